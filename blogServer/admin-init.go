@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"methompson.com/blog-microservice/blogServer/claims"
 	"methompson.com/blog-microservice/blogServer/user"
 )
 
@@ -23,10 +24,10 @@ func DoAdminInit(initUidPtr, initNamePtr *string) {
 
 	// fmt.Println(*initEmailPtr)
 
-	mdbController, mdbControllerErr := makeAndInitDatabase()
+	dbc, dbcErr := makeAndInitDatabase()
 
-	if mdbControllerErr != nil {
-		log.Fatal("Error Initializing Database: ", mdbControllerErr.Error())
+	if dbcErr != nil {
+		log.Fatal("Error Initializing Database: ", dbcErr.Error())
 	}
 
 	ctx := context.Background()
@@ -66,19 +67,14 @@ func DoAdminInit(initUidPtr, initNamePtr *string) {
 		Role:   user.Admin,
 	}
 
-	editUserErr := mdbController.AddUserInformation(&info)
+	editUserErr := dbc.AddUserInformation(&info)
 
 	if editUserErr != nil {
 		log.Fatal("Error adding user information: ", editUserErr.Error())
 	}
 
 	// Set admin privilege on the user corresponding to uid.
-	claims := map[string]interface{}{
-		"role":   "admin",
-		"name":   *initNamePtr,
-		"active": true,
-	}
-	setClaimsErr := client.SetCustomUserClaims(ctx, *initUidPtr, claims)
+	setClaimsErr := claims.NewSyncer(claims.FirebaseClient{Client: client}).SyncUser(ctx, nil, &info)
 
 	if setClaimsErr != nil {
 		log.Fatalf(setClaimsErr.Error())
@@ -0,0 +1,131 @@
+package blogServer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// The commands that can be passed to the -apiKey flag
+const (
+	API_KEY_COMMAND_CREATE = "create"
+	API_KEY_COMMAND_LIST   = "list"
+	API_KEY_COMMAND_REVOKE = "revoke"
+)
+
+// An ApiKeyCommand manages API keys from the command line. Name, OwnerId,
+// Scopes and ExpiresDays are used to create keys and Id to revoke them.
+// Scopes is a comma separated list. An ExpiresDays of 0 means the key doesn't
+// expire.
+type ApiKeyCommand struct {
+	Command     string
+	Name        string
+	OwnerId     string
+	Scopes      string
+	ExpiresDays int
+	Id          string
+}
+
+func printApiKey(key *dbController.ApiKeyDocument) {
+	status := "active"
+	if key.RevokedAt != nil {
+		status = "revoked " + key.RevokedAt.UTC().Format(time.RFC3339)
+	} else if !key.IsUsable(time.Now()) {
+		status = "expired"
+	}
+
+	expires := "never"
+	if key.ExpiresAt != nil {
+		expires = key.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	lastUsed := "never"
+	if key.LastUsed != nil {
+		lastUsed = key.LastUsed.UTC().Format(time.RFC3339)
+	}
+
+	fmt.Printf("%s %s (%s...)\n", key.Id, key.Name, key.Prefix)
+	fmt.Printf("  owner: %s\n", key.OwnerId)
+	fmt.Printf("  scopes: %s\n", strings.Join(key.Scopes, ", "))
+	fmt.Printf("  added: %s\n", key.DateAdded.UTC().Format(time.RFC3339))
+	fmt.Printf("  expires: %s\n", expires)
+	fmt.Printf("  last used: %s\n", lastUsed)
+	fmt.Printf("  status: %s\n", status)
+}
+
+// DoApiKeyCommand creates, lists or revokes API keys, so that keys can be
+// made before there is an admin that can use the API key routes.
+func DoApiKeyCommand(cmd ApiKeyCommand) {
+	dbc, dbcErr := makeAndInitDatabase()
+
+	if dbcErr != nil {
+		log.Fatal("Error Initializing Database: ", dbcErr.Error())
+	}
+
+	bc := InitController(&dbc)
+
+	switch cmd.Command {
+	case API_KEY_COMMAND_CREATE:
+		if len(cmd.OwnerId) == 0 {
+			log.Fatal("apiKeyOwner is required to create an API key")
+		}
+
+		body := AddApiKeyBody{
+			Name:    cmd.Name,
+			OwnerId: cmd.OwnerId,
+			Scopes:  make([]string, 0),
+		}
+
+		for _, scope := range strings.Split(cmd.Scopes, ",") {
+			if scope = strings.TrimSpace(scope); len(scope) > 0 {
+				body.Scopes = append(body.Scopes, scope)
+			}
+		}
+
+		if cmd.ExpiresDays < 0 {
+			log.Fatal("apiKeyExpiresDays must be 0 or more")
+		}
+
+		if cmd.ExpiresDays > 0 {
+			expiresAt := time.Now().AddDate(0, 0, cmd.ExpiresDays).Unix()
+			body.ExpiresAt = &expiresAt
+		}
+
+		key, doc, addErr := bc.AddApiKey(body, "")
+
+		if addErr != nil {
+			log.Fatal("Error creating API key: ", addErr.Error())
+		}
+
+		printApiKey(doc)
+		fmt.Println("Key:", key)
+		fmt.Println("Store the key somewhere safe. It can't be shown again.")
+	case API_KEY_COMMAND_LIST:
+		keys, getErr := bc.GetApiKeys()
+
+		if getErr != nil {
+			log.Fatal("Error getting API keys: ", getErr.Error())
+		}
+
+		for _, key := range keys {
+			printApiKey(key)
+		}
+
+		fmt.Printf("Found %d API keys.\n", len(keys))
+	case API_KEY_COMMAND_REVOKE:
+		if len(cmd.Id) == 0 {
+			log.Fatal("apiKeyId is required to revoke an API key")
+		}
+
+		if revokeErr := bc.RevokeApiKey(ApiKeyBody{Id: cmd.Id}); revokeErr != nil {
+			log.Fatal("Error revoking API key: ", revokeErr.Error())
+		}
+
+		fmt.Println("Revoked API key", cmd.Id)
+	default:
+		log.Fatalf("Unknown API key command %q. Use %s, %s or %s.", cmd.Command, API_KEY_COMMAND_CREATE, API_KEY_COMMAND_LIST, API_KEY_COMMAND_REVOKE)
+	}
+}
@@ -0,0 +1,106 @@
+package blogServer
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	firebase "firebase.google.com/go/v4"
+
+	"methompson.com/blog-microservice/blogServer/authenticator"
+	"methompson.com/blog-microservice/blogServer/constants"
+)
+
+// getAuthProvider returns the AUTH_PROVIDER. Firebase is used when it isn't
+// set.
+func getAuthProvider() (string, error) {
+	provider := os.Getenv(constants.AUTH_PROVIDER)
+
+	switch provider {
+	case "":
+		return constants.AUTH_PROVIDER_FIREBASE, nil
+	case constants.AUTH_PROVIDER_FIREBASE, constants.AUTH_PROVIDER_LOCAL:
+		return provider, nil
+	}
+
+	return "", errors.New("AUTH_PROVIDER must be firebase or local")
+}
+
+// makeLocalAuthenticator makes a LocalAuthenticator with every key set in the
+// LOCAL_AUTH environment variables. At least one key is required.
+func makeLocalAuthenticator() (*authenticator.LocalAuthenticator, error) {
+	la := authenticator.NewLocalAuthenticator(
+		os.Getenv(constants.LOCAL_AUTH_ISSUER),
+		os.Getenv(constants.LOCAL_AUTH_AUDIENCE),
+	)
+
+	if secret := os.Getenv(constants.LOCAL_AUTH_HS256_SECRET); len(secret) > 0 {
+		if hmacErr := la.AddHmacKey("", []byte(secret)); hmacErr != nil {
+			return nil, hmacErr
+		}
+	}
+
+	if keyPath := os.Getenv(constants.LOCAL_AUTH_RS256_KEY_PATH); len(keyPath) > 0 {
+		pemBytes, readErr := os.ReadFile(keyPath)
+
+		if readErr != nil {
+			return nil, errors.New("error reading LOCAL_AUTH_RS256_KEY_PATH: " + readErr.Error())
+		}
+
+		if pemErr := la.AddPemKey("", pemBytes); pemErr != nil {
+			return nil, errors.New("error parsing LOCAL_AUTH_RS256_KEY_PATH: " + pemErr.Error())
+		}
+	}
+
+	if jwksPath := os.Getenv(constants.LOCAL_AUTH_JWKS_PATH); len(jwksPath) > 0 {
+		jwksBytes, readErr := os.ReadFile(jwksPath)
+
+		if readErr != nil {
+			return nil, errors.New("error reading LOCAL_AUTH_JWKS_PATH: " + readErr.Error())
+		}
+
+		if jwksErr := la.AddJwks(jwksBytes); jwksErr != nil {
+			return nil, errors.New("error parsing LOCAL_AUTH_JWKS_PATH: " + jwksErr.Error())
+		}
+	}
+
+	if la.KeyCount() == 0 {
+		return nil, errors.New("the local auth provider needs LOCAL_AUTH_HS256_SECRET, LOCAL_AUTH_RS256_KEY_PATH or LOCAL_AUTH_JWKS_PATH")
+	}
+
+	return la, nil
+}
+
+// makeAuthenticator makes the Authenticator selected by AUTH_PROVIDER. The
+// Firebase app is nil for the local provider, which doesn't need Firebase.
+func makeAuthenticator() (authenticator.Authenticator, *firebase.App, error) {
+	provider, providerErr := getAuthProvider()
+
+	if providerErr != nil {
+		return nil, nil, providerErr
+	}
+
+	if provider == constants.AUTH_PROVIDER_LOCAL {
+		la, localErr := makeLocalAuthenticator()
+
+		if localErr != nil {
+			return nil, nil, localErr
+		}
+
+		return la, nil, nil
+	}
+
+	app, appErr := makeFirebaseApp()
+
+	if appErr != nil {
+		return nil, nil, errors.New("error making firebase app")
+	}
+
+	client, clientErr := app.Auth(context.Background())
+
+	if clientErr != nil {
+		return nil, nil, errors.New("error making firebase auth client")
+	}
+
+	return authenticator.NewFirebaseAuthenticator(client), app, nil
+}
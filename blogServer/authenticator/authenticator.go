@@ -0,0 +1,32 @@
+package authenticator
+
+import "context"
+
+// The claim that holds the role of the user, the same for every provider
+const ROLE_CLAIM = "role"
+
+// An Identity is who a verified token belongs to. Subject is the uid of the
+// user. Role is empty if the token doesn't have a role claim.
+type Identity struct {
+	Subject string
+	Role    string
+	Claims  map[string]interface{}
+}
+
+// An Authenticator verifies the tokens sent in the Authorization header
+type Authenticator interface {
+	VerifyToken(ctx context.Context, token string) (*Identity, error)
+}
+
+// A TokenError means the token couldn't be verified
+type TokenError struct{ ErrMsg string }
+
+func (err TokenError) Error() string { return err.ErrMsg }
+func NewTokenError(msg string) error { return TokenError{msg} }
+
+// getRole returns the role claim, or an empty string if it isn't a string
+func getRole(claims map[string]interface{}) string {
+	role, _ := claims[ROLE_CLAIM].(string)
+
+	return role
+}
@@ -0,0 +1,33 @@
+package authenticator
+
+import (
+	"context"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// FirebaseAuthenticator verifies Firebase ID tokens. The role comes from the
+// custom claims of the user.
+type FirebaseAuthenticator struct {
+	Client *auth.Client
+}
+
+func NewFirebaseAuthenticator(client *auth.Client) *FirebaseAuthenticator {
+	return &FirebaseAuthenticator{Client: client}
+}
+
+func (fa *FirebaseAuthenticator) VerifyToken(ctx context.Context, token string) (*Identity, error) {
+	verified, verifyErr := fa.Client.VerifyIDToken(ctx, token)
+
+	if verifyErr != nil {
+		return nil, NewTokenError(verifyErr.Error())
+	}
+
+	identity := Identity{
+		Subject: verified.UID,
+		Role:    getRole(verified.Claims),
+		Claims:  verified.Claims,
+	}
+
+	return &identity, nil
+}
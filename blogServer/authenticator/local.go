@@ -0,0 +1,258 @@
+package authenticator
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// The signing algorithms the LocalAuthenticator accepts
+const (
+	ALG_HS256 = "HS256"
+	ALG_RS256 = "RS256"
+)
+
+// A verificationKey is a key that can verify tokens signed with one
+// algorithm. Key is a []byte for HS256 and an *rsa.PublicKey for RS256. Keys
+// from a JWKS have an Id, which tokens pick with their kid header.
+type verificationKey struct {
+	Id  string
+	Alg string
+	Key interface{}
+}
+
+// LocalAuthenticator verifies JWTs signed with HS256 or RS256 by keys it's
+// given, without contacting anyone. Tokens must have a sub and an exp claim.
+// The iss and aud claims are checked when Issuer and Audience are set.
+type LocalAuthenticator struct {
+	Issuer   string
+	Audience string
+
+	keys []verificationKey
+}
+
+func NewLocalAuthenticator(issuer string, audience string) *LocalAuthenticator {
+	return &LocalAuthenticator{
+		Issuer:   issuer,
+		Audience: audience,
+		keys:     make([]verificationKey, 0),
+	}
+}
+
+// KeyCount returns the number of keys that can verify tokens
+func (la *LocalAuthenticator) KeyCount() int {
+	return len(la.keys)
+}
+
+// AddHmacKey adds a shared secret for HS256 tokens
+func (la *LocalAuthenticator) AddHmacKey(id string, secret []byte) error {
+	if len(secret) == 0 {
+		return errors.New("HS256 secret is empty")
+	}
+
+	la.keys = append(la.keys, verificationKey{Id: id, Alg: ALG_HS256, Key: secret})
+
+	return nil
+}
+
+// AddRsaKey adds a public key for RS256 tokens
+func (la *LocalAuthenticator) AddRsaKey(id string, key *rsa.PublicKey) {
+	la.keys = append(la.keys, verificationKey{Id: id, Alg: ALG_RS256, Key: key})
+}
+
+// AddPemKey adds an RS256 public key in PEM format
+func (la *LocalAuthenticator) AddPemKey(id string, pemBytes []byte) error {
+	key, keyErr := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+
+	if keyErr != nil {
+		return keyErr
+	}
+
+	la.AddRsaKey(id, key)
+
+	return nil
+}
+
+// A jsonWebKey is a key of a JWKS. Only RSA and oct keys are used.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+func decodeBase64Url(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(value)
+}
+
+func (jwk *jsonWebKey) getRsaKey() (*rsa.PublicKey, error) {
+	n, nErr := decodeBase64Url(jwk.N)
+	e, eErr := decodeBase64Url(jwk.E)
+
+	if nErr != nil || eErr != nil || len(n) == 0 || len(e) == 0 {
+		return nil, errors.New("invalid RSA key " + jwk.Kid)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent in key " + jwk.Kid)
+	}
+
+	key := rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}
+
+	return &key, nil
+}
+
+// AddJwks adds the signing keys of a JSON Web Key Set. RSA keys are used for
+// RS256 tokens and oct keys for HS256 tokens. Keys for other algorithms or
+// for encryption are skipped.
+func (la *LocalAuthenticator) AddJwks(jwksBytes []byte) error {
+	var set jsonWebKeySet
+
+	if jsonErr := json.Unmarshal(jwksBytes, &set); jsonErr != nil {
+		return errors.New("invalid JWKS: " + jsonErr.Error())
+	}
+
+	added := 0
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch {
+		case jwk.Kty == "RSA" && (jwk.Alg == "" || jwk.Alg == ALG_RS256):
+			key, keyErr := jwk.getRsaKey()
+
+			if keyErr != nil {
+				return keyErr
+			}
+
+			la.AddRsaKey(jwk.Kid, key)
+		case jwk.Kty == "oct" && (jwk.Alg == "" || jwk.Alg == ALG_HS256):
+			secret, secretErr := decodeBase64Url(jwk.K)
+
+			if secretErr != nil {
+				return errors.New("invalid oct key " + jwk.Kid)
+			}
+
+			if hmacErr := la.AddHmacKey(jwk.Kid, secret); hmacErr != nil {
+				return hmacErr
+			}
+		default:
+			continue
+		}
+
+		added++
+	}
+
+	if added == 0 {
+		return errors.New("JWKS has no RS256 or HS256 signing keys")
+	}
+
+	return nil
+}
+
+// candidateKeys returns the keys that may have signed a token with the
+// algorithm and kid. The algorithm has to match the key, so that an RSA
+// public key can't be used as an HMAC secret.
+func (la *LocalAuthenticator) candidateKeys(alg string, kid string) []verificationKey {
+	candidates := make([]verificationKey, 0)
+
+	for _, key := range la.keys {
+		if key.Alg != alg {
+			continue
+		}
+
+		if len(kid) > 0 && key.Id != kid {
+			continue
+		}
+
+		candidates = append(candidates, key)
+	}
+
+	return candidates
+}
+
+func (la *LocalAuthenticator) parse(token string, key verificationKey) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{key.Alg}))
+
+	_, parseErr := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key.Key, nil
+	})
+
+	return claims, parseErr
+}
+
+func (la *LocalAuthenticator) VerifyToken(ctx context.Context, token string) (*Identity, error) {
+	unverified, _, headerErr := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+
+	if headerErr != nil {
+		return nil, NewTokenError("malformed token")
+	}
+
+	kid, _ := unverified.Header["kid"].(string)
+
+	var claims jwt.MapClaims
+	var parseErr error = NewTokenError("no key for the token")
+
+	for _, key := range la.candidateKeys(unverified.Method.Alg(), kid) {
+		claims, parseErr = la.parse(token, key)
+
+		// Other keys may have signed the token, but if the signature matched,
+		// the token is invalid for a reason another key won't change.
+		validationErr, ok := parseErr.(*jwt.ValidationError)
+
+		if parseErr == nil || !ok || validationErr.Errors&jwt.ValidationErrorSignatureInvalid == 0 {
+			break
+		}
+	}
+
+	if parseErr != nil {
+		return nil, NewTokenError(parseErr.Error())
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, NewTokenError("token has no expiry")
+	}
+
+	if len(la.Issuer) > 0 && !claims.VerifyIssuer(la.Issuer, true) {
+		return nil, NewTokenError("invalid issuer")
+	}
+
+	if len(la.Audience) > 0 && !claims.VerifyAudience(la.Audience, true) {
+		return nil, NewTokenError("invalid audience")
+	}
+
+	subject, _ := claims["sub"].(string)
+
+	if len(subject) == 0 {
+		return nil, NewTokenError("token has no subject")
+	}
+
+	identity := Identity{
+		Subject: subject,
+		Role:    getRole(claims),
+		Claims:  claims,
+	}
+
+	return &identity, nil
+}
@@ -0,0 +1,239 @@
+package authenticator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const TEST_ISSUER = "https://issuer.example.com"
+const TEST_AUDIENCE = "blog"
+
+var testHmacSecret = []byte("test-secret")
+
+func generateTestRsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, keyErr := rsa.GenerateKey(rand.Reader, 2048)
+
+	if keyErr != nil {
+		t.Fatalf("GenerateKey: %v", keyErr)
+	}
+
+	return key
+}
+
+// validTestClaims returns claims that the test authenticators accept
+func validTestClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":  "uid",
+		"role": "editor",
+		"iss":  TEST_ISSUER,
+		"aud":  TEST_AUDIENCE,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// signTestToken signs the claims. kid is left out of the header when empty.
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+
+	if len(kid) > 0 {
+		token.Header["kid"] = kid
+	}
+
+	signed, signErr := token.SignedString(key)
+
+	if signErr != nil {
+		t.Fatalf("SignedString: %v", signErr)
+	}
+
+	return signed
+}
+
+func TestLocalAuthenticatorAcceptsValidTokens(t *testing.T) {
+	rsaKey := generateTestRsaKey(t)
+
+	la := NewLocalAuthenticator(TEST_ISSUER, TEST_AUDIENCE)
+	la.AddRsaKey("rsa", &rsaKey.PublicKey)
+
+	if hmacErr := la.AddHmacKey("hmac", testHmacSecret); hmacErr != nil {
+		t.Fatalf("AddHmacKey: %v", hmacErr)
+	}
+
+	tokens := map[string]string{
+		"RS256":        signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", validTestClaims()),
+		"HS256":        signTestToken(t, jwt.SigningMethodHS256, testHmacSecret, "hmac", validTestClaims()),
+		"RS256 no kid": signTestToken(t, jwt.SigningMethodRS256, rsaKey, "", validTestClaims()),
+	}
+
+	for name, token := range tokens {
+		identity, verifyErr := la.VerifyToken(context.Background(), token)
+
+		if verifyErr != nil {
+			t.Errorf("%s: %v", name, verifyErr)
+			continue
+		}
+
+		if identity.Subject != "uid" || identity.Role != "editor" {
+			t.Errorf("%s: identity %+v", name, identity)
+		}
+	}
+}
+
+func TestLocalAuthenticatorRejectsInvalidTokens(t *testing.T) {
+	rsaKey := generateTestRsaKey(t)
+	otherRsaKey := generateTestRsaKey(t)
+
+	la := NewLocalAuthenticator(TEST_ISSUER, TEST_AUDIENCE)
+	la.AddRsaKey("rsa", &rsaKey.PublicKey)
+
+	// The public key is public, so a token signed with it as an HMAC secret
+	// must not verify against the RSA key.
+	publicPem := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey),
+	})
+
+	withClaim := func(key string, value interface{}) jwt.MapClaims {
+		claims := validTestClaims()
+		claims[key] = value
+		return claims
+	}
+
+	withoutClaim := func(key string) jwt.MapClaims {
+		claims := validTestClaims()
+		delete(claims, key)
+		return claims
+	}
+
+	tokens := map[string]string{
+		"alg confusion":  signTestToken(t, jwt.SigningMethodHS256, publicPem, "rsa", validTestClaims()),
+		"wrong key":      signTestToken(t, jwt.SigningMethodRS256, otherRsaKey, "rsa", validTestClaims()),
+		"unknown kid":    signTestToken(t, jwt.SigningMethodRS256, rsaKey, "other", validTestClaims()),
+		"expired":        signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("exp", time.Now().Add(-time.Hour).Unix())),
+		"missing exp":    signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withoutClaim("exp")),
+		"wrong issuer":   signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("iss", "https://other.example.com")),
+		"missing issuer": signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withoutClaim("iss")),
+		"wrong audience": signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("aud", "other")),
+		"missing sub":    signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withoutClaim("sub")),
+		"none alg":       signTestToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "rsa", validTestClaims()),
+		"malformed":      "not.a.token",
+	}
+
+	for name, token := range tokens {
+		identity, verifyErr := la.VerifyToken(context.Background(), token)
+
+		if verifyErr == nil {
+			t.Errorf("%s: accepted %+v", name, identity)
+			continue
+		}
+
+		if _, ok := verifyErr.(TokenError); !ok {
+			t.Errorf("%s: error %T isn't a TokenError", name, verifyErr)
+		}
+	}
+}
+
+func TestLocalAuthenticatorAddJwks(t *testing.T) {
+	rsaKey := generateTestRsaKey(t)
+
+	encode := func(value []byte) string {
+		return base64.RawURLEncoding.EncodeToString(value)
+	}
+
+	jwks, jsonErr := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   encode(rsaKey.PublicKey.N.Bytes()),
+				"e":   encode(big.NewInt(int64(rsaKey.PublicKey.E)).Bytes()),
+			},
+			{
+				"kty": "oct",
+				"kid": "hmac",
+				"k":   encode(testHmacSecret),
+			},
+			// Encryption keys and other algorithms are skipped
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			{"kty": "EC", "kid": "ec", "crv": "P-256"},
+		},
+	})
+
+	if jsonErr != nil {
+		t.Fatalf("Marshal: %v", jsonErr)
+	}
+
+	la := NewLocalAuthenticator(TEST_ISSUER, TEST_AUDIENCE)
+
+	if jwksErr := la.AddJwks(jwks); jwksErr != nil {
+		t.Fatalf("AddJwks: %v", jwksErr)
+	}
+
+	if la.KeyCount() != 2 {
+		t.Errorf("KeyCount = %d, want 2", la.KeyCount())
+	}
+
+	tokens := map[string]string{
+		"RS256": signTestToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", validTestClaims()),
+		"HS256": signTestToken(t, jwt.SigningMethodHS256, testHmacSecret, "hmac", validTestClaims()),
+	}
+
+	for name, token := range tokens {
+		if _, verifyErr := la.VerifyToken(context.Background(), token); verifyErr != nil {
+			t.Errorf("%s: %v", name, verifyErr)
+		}
+	}
+
+	invalidSets := map[string]string{
+		"not json":       "keys",
+		"no usable keys": `{"keys":[{"kty":"EC","kid":"ec"}]}`,
+		"bad modulus":    `{"keys":[{"kty":"RSA","kid":"rsa","n":"!","e":"AQAB"}]}`,
+	}
+
+	for name, set := range invalidSets {
+		if jwksErr := NewLocalAuthenticator("", "").AddJwks([]byte(set)); jwksErr == nil {
+			t.Errorf("%s: JWKS was accepted", name)
+		}
+	}
+}
+
+func TestLocalAuthenticatorAddPemKey(t *testing.T) {
+	rsaKey := generateTestRsaKey(t)
+
+	publicDer, derErr := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+
+	if derErr != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", derErr)
+	}
+
+	la := NewLocalAuthenticator("", "")
+
+	if pemErr := la.AddPemKey("", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})); pemErr != nil {
+		t.Fatalf("AddPemKey: %v", pemErr)
+	}
+
+	token := signTestToken(t, jwt.SigningMethodRS256, rsaKey, "", validTestClaims())
+
+	if _, verifyErr := la.VerifyToken(context.Background(), token); verifyErr != nil {
+		t.Errorf("VerifyToken: %v", verifyErr)
+	}
+
+	if pemErr := la.AddPemKey("", []byte("not a key")); pemErr == nil {
+		t.Errorf("invalid PEM was accepted")
+	}
+}
@@ -1,15 +1,80 @@
 package blogServer
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/mail"
+	"strings"
+	"time"
+
 	"github.com/gosimple/slug"
+	"methompson.com/blog-microservice/blogServer/claims"
 	"methompson.com/blog-microservice/blogServer/dbController"
 	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/markdown"
 	"methompson.com/blog-microservice/blogServer/user"
 )
 
+// DEFAULT_PAGINATION is the number of blog posts on a page when a request
+// doesn't ask for a number
+const DEFAULT_PAGINATION = 10
+
+// GetPagination returns the number of blog posts on a page for a request that
+// asked for pagination posts. Requests that don't ask pass 0 or less.
+func GetPagination(pagination int) int {
+	if pagination <= 0 {
+		return DEFAULT_PAGINATION
+	}
+
+	return pagination
+}
+
+// API keys let machine clients, like a CI pipeline, change blog posts without
+// a Firebase token. A key acts as its owner, who has to stay an active editor
+// or admin, and only for the routes its scopes allow. Keys are managed by
+// admins and never get access to the admin routes.
+
+// The scopes an API key can have
+const (
+	SCOPE_POSTS_READ   = "posts:read"
+	SCOPE_POSTS_WRITE  = "posts:write"
+	SCOPE_POSTS_DELETE = "posts:delete"
+)
+
+var API_KEY_SCOPES = []string{SCOPE_POSTS_READ, SCOPE_POSTS_WRITE, SCOPE_POSTS_DELETE}
+
+// Every API key starts with API_KEY_PREFIX, which is how they are told apart
+// from tokens in the Authorization header.
+const API_KEY_PREFIX = "blog_"
+
+// API_KEY_BYTES is the number of random bytes in an API key
+const API_KEY_BYTES = 32
+
+// API_KEY_DISPLAY_LENGTH is the length of the start of a key that is kept, so
+// that admins can tell keys apart.
+const API_KEY_DISPLAY_LENGTH = 12
+
+// API_KEY_LAST_USED_INTERVAL is how often the lastUsed time of a key is saved,
+// so that busy clients don't write to the database on every request.
+const API_KEY_LAST_USED_INTERVAL = time.Minute
+
+// The claim that holds the id of the API key in the Identity of a request
+const API_KEY_ID_CLAIM = "apiKeyId"
+
 type BlogController struct {
 	DBController *dbController.DatabaseController
 	Loggers      []*logging.BlogLogger
+
+	// ClaimsSyncer pushes user changes to the Firebase claims. Without one,
+	// users are only changed in the database.
+	ClaimsSyncer *claims.Syncer
+
+	// UserCache keeps the users checked by CheckAuthorizedUser. Without one,
+	// the user is read from the database for every check.
+	UserCache *UserCache
 }
 
 // The DatabaseController should already be initialized before getting
@@ -18,29 +83,308 @@ func InitController(dbc *dbController.DatabaseController) BlogController {
 	bc := BlogController{
 		DBController: dbc,
 		Loggers:      make([]*logging.BlogLogger, 0),
+		UserCache:    NewUserCache(USER_CACHE_TTL),
 	}
 
 	return bc
 }
 
-func (bc *BlogController) AddUserData(userInfo *user.UserInformation) {}
+// checkUserName and checkUserEmail return an InputError for values that can't
+// be saved for a user.
+func checkUserName(name string) error {
+	if len(strings.TrimSpace(name)) == 0 {
+		return NewInputError("invalid name")
+	}
+
+	return nil
+}
+
+func checkUserEmail(email string) error {
+	address, addressErr := mail.ParseAddress(email)
+
+	if addressErr != nil || address.Address != email {
+		return NewInputError("invalid email")
+	}
+
+	return nil
+}
+
+func parseUserRole(role string) (user.UserType, error) {
+	userType, roleErr := user.ParseUserType(role)
+
+	if roleErr != nil {
+		return userType, NewInputError("invalid role")
+	}
+
+	return userType, nil
+}
+
+// syncUserClaims pushes a change of a user to the UserCache and their
+// Firebase claims. The change is already saved, so a failure leaves the two
+// out of sync until the claims are reconciled.
+func (bc *BlogController) syncUserClaims(previous *user.UserInformation, current *user.UserInformation) error {
+	uid := ""
+	if current != nil {
+		uid = current.Uid
+	} else if previous != nil {
+		uid = previous.Uid
+	}
+
+	if bc.UserCache != nil {
+		bc.UserCache.Invalidate(uid)
+	}
+
+	if bc.ClaimsSyncer == nil {
+		return nil
+	}
+
+	syncErr := bc.ClaimsSyncer.SyncUser(context.Background(), previous, current)
+
+	if syncErr != nil {
+		print("Error syncing user claims: " + syncErr.Error() + "\n")
+	}
+
+	return syncErr
+}
+
+// getAuthUser returns the user with the uid, or nil if there isn't one. The
+// user comes from the UserCache if it's there.
+func (bc *BlogController) getAuthUser(uid string) (*user.UserInformation, error) {
+	if bc.UserCache != nil {
+		if info, ok := bc.UserCache.Get(uid); ok {
+			return info, nil
+		}
+	}
+
+	info, getErr := (*bc.DBController).GetUserInformation(uid)
+
+	if _, ok := getErr.(dbController.NoResultsError); ok {
+		info, getErr = nil, nil
+	}
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if bc.UserCache != nil {
+		bc.UserCache.Set(uid, info)
+	}
+
+	return info, nil
+}
+
+// CheckAuthorizedUser returns the user with the uid if the user is active and
+// has the role. Tokens keep their role until they expire, so the role of a
+// token is only trusted if the database agrees.
+func (bc *BlogController) CheckAuthorizedUser(uid string, role string) (*user.UserInformation, error) {
+	info, getErr := bc.getAuthUser(uid)
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	if info == nil {
+		return nil, NewUnauthorizedError("user does not exist")
+	}
+
+	if !info.Active {
+		return nil, NewDeactivatedUserError("account deactivated")
+	}
+
+	if info.Role.String() != role {
+		return nil, NewUnauthorizedError("role does not match")
+	}
+
+	return info, nil
+}
+
+// getExistingUser returns the user with the uid, or an InvalidInputError if
+// there isn't one, the same as the DatabaseController does for edits.
+func (bc *BlogController) getExistingUser(uid string) (*user.UserInformation, error) {
+	info, getErr := (*bc.DBController).GetUserInformation(uid)
+
+	if _, ok := getErr.(dbController.NoResultsError); ok {
+		return nil, dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	return info, getErr
+}
+
+// AddUserData adds a user. Unlike the DatabaseController, it doesn't replace a
+// user that already exists.
+func (bc *BlogController) AddUserData(body AddUserBody) (*user.UserInformation, error) {
+	if nameErr := checkUserName(body.Name); nameErr != nil {
+		return nil, nameErr
+	}
+
+	if emailErr := checkUserEmail(body.Email); emailErr != nil {
+		return nil, emailErr
+	}
+
+	role, roleErr := parseUserRole(body.Role)
+
+	if roleErr != nil {
+		return nil, roleErr
+	}
+
+	_, existingErr := (*bc.DBController).GetUserInformation(body.Uid)
+
+	if existingErr == nil {
+		return nil, dbController.NewDuplicateEntryError("Duplicate user. User with uid '" + body.Uid + "' already exists.")
+	}
+
+	if _, ok := existingErr.(dbController.NoResultsError); !ok {
+		return nil, existingErr
+	}
+
+	info := user.UserInformation{
+		Uid:    body.Uid,
+		Name:   body.Name,
+		Email:  body.Email,
+		Active: body.Active == nil || *body.Active,
+		Role:   role,
+	}
+
+	if addErr := (*bc.DBController).AddUserInformation(&info); addErr != nil {
+		return nil, addErr
+	}
+
+	return &info, bc.syncUserClaims(nil, &info)
+}
+
+func (bc *BlogController) GetUserData(uid string) (*user.UserInformation, error) {
+	return (*bc.DBController).GetUserInformation(uid)
+}
+
+func (bc *BlogController) GetUsers(page int, pagination int) ([]*user.UserInformation, error) {
+	_pagination := GetPagination(pagination)
+
+	return (*bc.DBController).GetUsers(page, _pagination)
+}
+
+func (bc *BlogController) CountUsers() (int, error) {
+	return (*bc.DBController).CountUsers()
+}
+
+// EditUserData changes the user and returns the user as it is after the edit.
+// The user is returned along with a claims.SyncError if only the database was
+// changed. editedBy is the uid of the admin making the change. Admins can't take away
+// their own access, so that there's always an admin left.
+func (bc *BlogController) EditUserData(body EditUserBody, editedBy string) (*user.UserInformation, error) {
+	doc := dbController.EditUserDocument{
+		Uid:    body.Uid,
+		Name:   body.Name,
+		Email:  body.Email,
+		Active: body.Active,
+	}
+
+	if doc.Name != nil {
+		if nameErr := checkUserName(*doc.Name); nameErr != nil {
+			return nil, nameErr
+		}
+	}
+
+	if doc.Email != nil {
+		if emailErr := checkUserEmail(*doc.Email); emailErr != nil {
+			return nil, emailErr
+		}
+	}
+
+	if body.Role != nil {
+		role, roleErr := parseUserRole(*body.Role)
+
+		if roleErr != nil {
+			return nil, roleErr
+		}
+
+		doc.Role = &role
+	}
+
+	if body.Uid == editedBy {
+		removesAccess := (doc.Active != nil && !*doc.Active) || (doc.Role != nil && *doc.Role != user.Admin)
+
+		if removesAccess {
+			return nil, NewInputError("admins can't remove their own access")
+		}
+	}
+
+	previous, previousErr := bc.getExistingUser(body.Uid)
+
+	if previousErr != nil {
+		return nil, previousErr
+	}
+
+	if editErr := (*bc.DBController).EditUserInformation(&doc); editErr != nil {
+		return nil, editErr
+	}
+
+	current, currentErr := (*bc.DBController).GetUserInformation(body.Uid)
+
+	if currentErr != nil {
+		return nil, currentErr
+	}
+
+	return current, bc.syncUserClaims(previous, current)
+}
+
+// DeactivateUserData keeps the user, but takes away their access
+func (bc *BlogController) DeactivateUserData(body UserBody, deactivatedBy string) (*user.UserInformation, error) {
+	active := false
+
+	return bc.EditUserData(EditUserBody{Uid: body.Uid, Active: &active}, deactivatedBy)
+}
+
+func (bc *BlogController) DeleteUserData(body UserBody, deletedBy string) error {
+	if body.Uid == deletedBy {
+		return NewInputError("admins can't remove their own access")
+	}
+
+	previous, previousErr := bc.getExistingUser(body.Uid)
 
-func (bc *BlogController) GetUserData() *user.UserInformation {
-	ui := user.UserInformation{}
+	if previousErr != nil {
+		return previousErr
+	}
 
-	return &ui
+	if deleteErr := (*bc.DBController).DeleteUserInformation(body.Uid); deleteErr != nil {
+		return deleteErr
+	}
+
+	return bc.syncUserClaims(previous, nil)
 }
 
-func (bc *BlogController) EditUserData()    {}
-func (bc *BlogController) DeletedUserData() {}
+// AddBlogPost adds the blog post. addedBy is the uid of the user adding it.
+func (bc *BlogController) AddBlogPost(blogBody AddBlogBody, addedBy string) (id string, slug string, err error) {
+	blogDocument := blogBody.GetBlogDocument(addedBy)
+
+	newSlug, slugErr := bc.resolveSlug(blogDocument.Slug, &blogDocument.Title, "", blogDocument.DateAdded)
 
-func (bc *BlogController) AddBlogPost(blogBody AddBlogBody) (id string, slug string, err error) {
-	blogDocument := blogBody.GetBlogDocument()
+	if slugErr != nil {
+		return "", "", slugErr
+	}
+
+	blogDocument.Slug = newSlug
 
-	if !bc.isValidSlug(blogDocument.Slug) {
-		blogDocument.Slug = bc.slugify(blogDocument.Title)
+	statusErr := bc.checkStatus(blogDocument.Status, blogDocument.PublishAt)
+
+	if statusErr != nil {
+		return "", "", statusErr
 	}
 
+	bodyHtml, renderErr := markdown.Render(blogDocument.Body)
+
+	if renderErr != nil {
+		return "", "", renderErr
+	}
+
+	blogDocument.BodyHtml = bodyHtml
+
+	excerpt := ""
+	if blogBody.Excerpt != nil {
+		excerpt = *blogBody.Excerpt
+	}
+
+	blogDocument.Summary = makeBlogSummary(bodyHtml, excerpt)
+
 	addBlogId, addBlogErr := (*bc.DBController).AddBlogPost(blogDocument)
 
 	if addBlogErr != nil {
@@ -50,50 +394,767 @@ func (bc *BlogController) AddBlogPost(blogBody AddBlogBody) (id string, slug str
 	return addBlogId, blogDocument.Slug, nil
 }
 
-func (bc *BlogController) GetBlogPostById(id string) (*dbController.BlogDocument, error) {
-	return (*bc.DBController).GetBlogPostById(id)
+// The get functions only return drafts, archived posts and posts scheduled for
+// the future when includeUnpublished is true, i.e. for editors.
+func (bc *BlogController) GetBlogPostById(id string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return bc.withRenderedPost((*bc.DBController).GetBlogPostById(id, includeUnpublished))
+}
+
+func (bc *BlogController) GetBlogPostBySlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return bc.withRenderedPost((*bc.DBController).GetBlogPostBySlug(slug, includeUnpublished))
+}
+
+// GetBlogPostByOldSlug returns the blog post that used to have the slug, so
+// that old links can be sent to where the post is now.
+func (bc *BlogController) GetBlogPostByOldSlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return bc.withRenderedPost((*bc.DBController).GetBlogPostByOldSlug(slug, includeUnpublished))
+}
+
+func (bc *BlogController) GetBlogPosts(page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	_pagination := GetPagination(pagination)
+
+	return bc.withRenderedPosts((*bc.DBController).GetBlogPosts(page, _pagination, includeUnpublished))
+}
+
+// GetBlogPostsByCursor returns the page of blog posts next to the cursor in the
+// token, or the first page if the token is empty.
+func (bc *BlogController) GetBlogPostsByCursor(token string, pagination int, includeUnpublished bool) (*BlogCursorPage, error) {
+	_pagination := GetPagination(pagination)
+
+	var cursor *dbController.BlogCursor
+
+	if len(token) > 0 {
+		var cursorErr error
+		cursor, cursorErr = DecodeBlogCursor(token)
+
+		if cursorErr != nil {
+			return nil, cursorErr
+		}
+	}
+
+	dbc := *bc.DBController
+
+	// The extra post tells us whether there are more posts past this page
+	posts, postsErr := dbc.GetBlogPostsByCursor(cursor, _pagination+1, includeUnpublished)
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	if renderErr := bc.renderMissingFields(posts...); renderErr != nil {
+		return nil, renderErr
+	}
+
+	previous := cursor != nil && cursor.Previous
+	hasMore := len(posts) > _pagination
+
+	if hasMore && previous {
+		posts = posts[1:]
+	} else if hasMore {
+		posts = posts[:_pagination]
+	}
+
+	total, totalErr := dbc.CountBlogPosts(nil, includeUnpublished)
+
+	if totalErr != nil {
+		return nil, totalErr
+	}
+
+	page := BlogCursorPage{
+		Posts: posts,
+		Total: total,
+	}
+
+	// The posts on either side of the page. An empty page sits at the cursor.
+	var first, last *dbController.BlogCursor
+	if len(posts) > 0 {
+		first = &dbController.BlogCursor{DateAdded: posts[0].DateAdded, Id: posts[0].Id, Previous: true}
+		last = &dbController.BlogCursor{DateAdded: posts[len(posts)-1].DateAdded, Id: posts[len(posts)-1].Id}
+	} else if cursor != nil {
+		first = &dbController.BlogCursor{DateAdded: cursor.DateAdded, Id: cursor.Id, Previous: true}
+		last = &dbController.BlogCursor{DateAdded: cursor.DateAdded, Id: cursor.Id}
+	}
+
+	hasNext := hasMore && !previous
+	hasPrev := hasMore && previous
+
+	// The first page never has previous posts. Otherwise, the direction we
+	// didn't page in is checked for a post.
+	if previous && last != nil {
+		hasNext, postsErr = bc.hasBlogPostsAt(last, includeUnpublished)
+	} else if cursor != nil && first != nil {
+		hasPrev, postsErr = bc.hasBlogPostsAt(first, includeUnpublished)
+	}
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	if hasNext {
+		page.Next = last
+	}
+
+	if hasPrev {
+		page.Prev = first
+	}
+
+	return &page, nil
+}
+
+// hasBlogPostsAt returns whether there are any posts past the cursor
+func (bc *BlogController) hasBlogPostsAt(cursor *dbController.BlogCursor, includeUnpublished bool) (bool, error) {
+	posts, postsErr := (*bc.DBController).GetBlogPostsByCursor(cursor, 1, includeUnpublished)
+
+	if postsErr != nil {
+		return false, postsErr
+	}
+
+	return len(posts) > 0, nil
+}
+
+// CountBlogPosts returns the number of blog posts that match the filter, or
+// of every blog post if the filter is nil.
+func (bc *BlogController) CountBlogPosts(filter *dbController.BlogPostFilter, includeUnpublished bool) (int, error) {
+	return (*bc.DBController).CountBlogPosts(filter, includeUnpublished)
+}
+
+func (bc *BlogController) GetBlogTags(includeUnpublished bool) ([]*dbController.TagCount, error) {
+	return (*bc.DBController).GetBlogTags(includeUnpublished)
+}
+
+func (bc *BlogController) GetBlogPostsByTag(tag string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	_pagination := GetPagination(pagination)
+
+	return bc.withRenderedPosts((*bc.DBController).GetBlogPostsByTag(tag, page, _pagination, includeUnpublished))
 }
 
-func (bc *BlogController) GetBlogPostBySlug(slug string) (*dbController.BlogDocument, error) {
-	return (*bc.DBController).GetBlogPostBySlug(slug)
+func (bc *BlogController) GetBlogAuthor(uid string) (*dbController.BlogAuthor, error) {
+	return (*bc.DBController).GetBlogAuthor(uid)
 }
 
-func (bc *BlogController) GetBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
-	_pagination := pagination
+func (bc *BlogController) GetBlogPostsByAuthor(uid string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	_pagination := GetPagination(pagination)
+
+	return bc.withRenderedPosts((*bc.DBController).GetBlogPostsByAuthor(uid, page, _pagination, includeUnpublished))
+}
+
+func (bc *BlogController) GetBlogAuthors(includeUnpublished bool) ([]*dbController.BlogAuthor, error) {
+	return (*bc.DBController).GetBlogAuthors(includeUnpublished)
+}
+
+// GetSitemapEntries returns a page of the public blog posts with only the
+// fields a sitemap needs, so that large sitemaps don't read every post body
+func (bc *BlogController) GetSitemapEntries(page int, pagination int) ([]*dbController.SitemapEntry, error) {
+	return (*bc.DBController).GetSitemapEntries(page, pagination)
+}
+
+func (bc *BlogController) GetBlogArchive(includeUnpublished bool) ([]*dbController.ArchiveCount, error) {
+	return (*bc.DBController).GetBlogArchive(includeUnpublished)
+}
+
+// ArchiveDateFilter returns a filter for the blog posts added in the year, or
+// in the month of the year if month isn't 0. Dates are in UTC.
+func ArchiveDateFilter(year int, month int) (*dbController.BlogPostFilter, error) {
+	if year < 1 || year > 9999 {
+		return nil, NewInputError("invalid year")
+	}
+
+	if month < 0 || month > 12 {
+		return nil, NewInputError("invalid month")
+	}
+
+	var start, end time.Time
+
+	if month == 0 {
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(1, 0, 0)
+	} else {
+		start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, 0)
+	}
+
+	filter := dbController.BlogPostFilter{
+		DateAddedStart: &start,
+		DateAddedEnd:   &end,
+	}
+
+	return &filter, nil
+}
+
+// GetBlogPostsByArchiveDate returns the blog posts added in the year, or in the
+// month of the year if month isn't 0. Dates are in UTC.
+func (bc *BlogController) GetBlogPostsByArchiveDate(year int, month int, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	filter, filterErr := ArchiveDateFilter(year, month)
+
+	if filterErr != nil {
+		return nil, filterErr
+	}
+
+	_pagination := GetPagination(pagination)
+
+	return bc.withRenderedPosts((*bc.DBController).GetBlogPostsByDateRange(*filter.DateAddedStart, *filter.DateAddedEnd, page, _pagination, includeUnpublished))
+}
+
+func (bc *BlogController) SearchBlogPosts(query string, page int, pagination int, includeUnpublished bool) ([]*dbController.SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, NewInputError("search query is required")
+	}
+
+	_pagination := GetPagination(pagination)
+
+	results, searchErr := (*bc.DBController).SearchBlogPosts(query, page, _pagination, includeUnpublished)
+
+	if searchErr != nil {
+		return nil, searchErr
+	}
+
+	for _, result := range results {
+		if renderErr := bc.renderMissingFields(result.Post); renderErr != nil {
+			return nil, renderErr
+		}
+	}
+
+	return results, nil
+}
 
-	if _pagination <= 0 {
-		_pagination = 10
+func (bc *BlogController) CountSearchResults(query string, includeUnpublished bool) (int, error) {
+	if strings.TrimSpace(query) == "" {
+		return 0, NewInputError("search query is required")
 	}
 
-	return (*bc.DBController).GetBlogPosts(page, _pagination)
+	return (*bc.DBController).CountSearchResults(query, includeUnpublished)
 }
 
-// TODO Check that the data is valid (e.g. the slug)
-func (bc *BlogController) EditBlogPost(body EditBlogBody) error {
-	blogDocument := body.GetBlogDocument()
+// EditBlogPost edits the blog post. updatedBy is the uid of the user making
+// the edit.
+// TODO Check that the data is valid
+func (bc *BlogController) EditBlogPost(body EditBlogBody, updatedBy string) error {
+	blogDocument := body.GetBlogDocument(updatedBy)
+
+	// The revision saved for the edit uses dateUpdated as the time of the edit
+	if blogDocument.DateUpdated == nil {
+		now := time.Now()
+		blogDocument.DateUpdated = &now
+	}
+
+	if blogDocument.Slug != nil {
+		// Date suffixes use the date the post was added, or the edit if not given
+		slugDate := *blogDocument.DateUpdated
+		if blogDocument.DateAdded != nil {
+			slugDate = *blogDocument.DateAdded
+		}
+
+		newSlug, slugErr := bc.resolveSlug(*blogDocument.Slug, blogDocument.Title, blogDocument.Id, slugDate)
 
-	if blogDocument.Slug != nil && bc.isValidSlug(*blogDocument.Slug) {
-		if blogDocument.Title == nil {
-			return NewInputError("invalid slug and no title")
+		if slugErr != nil {
+			return slugErr
 		}
 
-		s := bc.slugify(*blogDocument.Title)
-		blogDocument.Slug = &s
+		blogDocument.Slug = &newSlug
+	}
+
+	statusErr := bc.checkEditStatus(blogDocument)
+
+	if statusErr != nil {
+		return statusErr
+	}
+
+	renderErr := bc.renderEdit(blogDocument, body.Excerpt)
+
+	if renderErr != nil {
+		return renderErr
 	}
 
 	return (*bc.DBController).EditBlogPost(blogDocument)
 }
 
-func (bc *BlogController) DeleteBlogPost(body DeleteBlogBody) error {
-	blogDocument := body.GetBlogDocument()
+// DeleteBlogPost moves the blog post to the trash. deletedBy is the uid of the
+// user deleting the post.
+func (bc *BlogController) DeleteBlogPost(body DeleteBlogBody, deletedBy string) error {
+	blogDocument := body.GetBlogDocument(deletedBy)
 
 	return (*bc.DBController).DeleteBlogPost(blogDocument)
 }
 
+func (bc *BlogController) GetTrashedBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
+	_pagination := GetPagination(pagination)
+
+	return bc.withRenderedPosts((*bc.DBController).GetTrashedBlogPosts(page, _pagination))
+}
+
+func (bc *BlogController) CountTrashedBlogPosts() (int, error) {
+	return (*bc.DBController).CountTrashedBlogPosts()
+}
+
+func (bc *BlogController) RestoreBlogPost(body TrashedBlogBody) error {
+	return (*bc.DBController).RestoreBlogPost(body.Id)
+}
+
+func (bc *BlogController) PurgeBlogPost(body TrashedBlogBody) error {
+	return (*bc.DBController).PurgeBlogPost(body.Id)
+}
+
+// PurgeExpiredBlogPosts purges the blog posts that have been in the trash for
+// longer than the retention period.
+func (bc *BlogController) PurgeExpiredBlogPosts(retention time.Duration) (int, error) {
+	return (*bc.DBController).PurgeTrashedBlogPosts(time.Now().Add(-retention))
+}
+
+func (bc *BlogController) GetBlogPostRevisions(postId string) ([]*dbController.BlogRevision, error) {
+	return (*bc.DBController).GetBlogPostRevisions(postId)
+}
+
+func (bc *BlogController) GetBlogPostRevision(postId string, revision int) (*dbController.BlogRevision, error) {
+	return (*bc.DBController).GetBlogPostRevision(postId, revision)
+}
+
+// DiffBlogPostRevisions returns the fields that changed between two revisions
+// of a blog post.
+func (bc *BlogController) DiffBlogPostRevisions(postId string, from int, to int) ([]*dbController.RevisionFieldDiff, error) {
+	fromRevision, fromErr := (*bc.DBController).GetBlogPostRevision(postId, from)
+
+	if fromErr != nil {
+		return nil, fromErr
+	}
+
+	toRevision, toErr := (*bc.DBController).GetBlogPostRevision(postId, to)
+
+	if toErr != nil {
+		return nil, toErr
+	}
+
+	return dbController.DiffBlogRevisions(fromRevision, toRevision), nil
+}
+
+// RestoreBlogPostRevision sets the blog post back to the state saved in an
+// earlier revision. The restore is an edit like any other, so it's saved as a
+// new revision and the revisions in between are kept. restoredBy is the uid
+// of the user restoring the revision.
+func (bc *BlogController) RestoreBlogPostRevision(body RestoreBlogBody, restoredBy string) error {
+	revision, revisionErr := (*bc.DBController).GetBlogPostRevision(body.Id, body.Revision)
+
+	if revisionErr != nil {
+		return revisionErr
+	}
+
+	blogDocument := revision.GetEditDocument()
+
+	now := time.Now()
+	blogDocument.DateUpdated = &now
+	blogDocument.UpdateAuthorId = &restoredBy
+
+	renderErr := bc.renderEdit(blogDocument, nil)
+
+	if renderErr != nil {
+		return renderErr
+	}
+
+	return (*bc.DBController).EditBlogPost(blogDocument)
+}
+
+// IsApiKey returns whether the token from an Authorization header is an API key
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, API_KEY_PREFIX)
+}
+
+// hashApiKey returns the hash the key is stored by. Keys are long and random,
+// so a fast hash is enough.
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateApiKey() (string, error) {
+	randomBytes := make([]byte, API_KEY_BYTES)
+
+	if _, randErr := rand.Read(randomBytes); randErr != nil {
+		return "", randErr
+	}
+
+	return API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+// checkApiKeyScopes returns the scopes without duplicates, or an InputError if
+// there aren't any or one of them is unknown.
+func checkApiKeyScopes(scopes []string) ([]string, error) {
+	output := make([]string, 0)
+
+	for _, scope := range scopes {
+		if !hasString(API_KEY_SCOPES, scope) {
+			return nil, NewInputError("invalid scope " + scope + ". scopes must be one of " + strings.Join(API_KEY_SCOPES, ", "))
+		}
+
+		if !hasString(output, scope) {
+			output = append(output, scope)
+		}
+	}
+
+	if len(output) == 0 {
+		return nil, NewInputError("at least one scope is required")
+	}
+
+	return output, nil
+}
+
+func hasString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canOwnApiKey returns whether the user can own an API key. Only active
+// editors and admins can change blog posts.
+func canOwnApiKey(info *user.UserInformation) bool {
+	return info.Active && (info.Role == user.Editor || info.Role == user.Admin)
+}
+
+// AddApiKey creates an API key and returns the key along with the stored
+// document. The key itself isn't stored, so this is the only time it's
+// available. addedBy is the uid of the admin creating the key.
+func (bc *BlogController) AddApiKey(body AddApiKeyBody, addedBy string) (string, *dbController.ApiKeyDocument, error) {
+	name := strings.TrimSpace(body.Name)
+
+	if len(name) == 0 {
+		return "", nil, NewInputError("invalid name")
+	}
+
+	scopes, scopesErr := checkApiKeyScopes(body.Scopes)
+
+	if scopesErr != nil {
+		return "", nil, scopesErr
+	}
+
+	ownerId := body.OwnerId
+	if len(ownerId) == 0 {
+		ownerId = addedBy
+	}
+
+	owner, ownerErr := (*bc.DBController).GetUserInformation(ownerId)
+
+	if _, ok := ownerErr.(dbController.NoResultsError); ok {
+		return "", nil, NewInputError("owner does not exist")
+	}
+
+	if ownerErr != nil {
+		return "", nil, ownerErr
+	}
+
+	if !canOwnApiKey(owner) {
+		return "", nil, NewInputError("owner must be an active editor or admin")
+	}
+
+	now := time.Now()
+
+	var expiresAt *time.Time
+	if body.ExpiresAt != nil {
+		expires := time.Unix(*body.ExpiresAt, 0)
+
+		if !expires.After(now) {
+			return "", nil, NewInputError("expiresAt must be in the future")
+		}
+
+		expiresAt = &expires
+	}
+
+	key, keyErr := generateApiKey()
+
+	if keyErr != nil {
+		return "", nil, keyErr
+	}
+
+	doc := dbController.AddApiKeyDocument{
+		Name:      name,
+		OwnerId:   ownerId,
+		Prefix:    key[:API_KEY_DISPLAY_LENGTH],
+		Hash:      hashApiKey(key),
+		Scopes:    scopes,
+		DateAdded: now,
+		ExpiresAt: expiresAt,
+	}
+
+	if _, addErr := (*bc.DBController).AddApiKey(&doc); addErr != nil {
+		return "", nil, addErr
+	}
+
+	stored, getErr := (*bc.DBController).GetApiKeyByHash(doc.Hash)
+
+	if getErr != nil {
+		return "", nil, getErr
+	}
+
+	return key, stored, nil
+}
+
+func (bc *BlogController) GetApiKeys() ([]*dbController.ApiKeyDocument, error) {
+	return (*bc.DBController).GetApiKeys()
+}
+
+func (bc *BlogController) RevokeApiKey(body ApiKeyBody) error {
+	return (*bc.DBController).RevokeApiKey(body.Id, time.Now())
+}
+
+// VerifyApiKey returns the owner of the API key if the key can be used for
+// the scope. Revoked, expired and unknown keys are an InvalidApiKeyError, and
+// keys without the scope are a MissingScopeError. The owner is checked the
+// same way CheckAuthorizedUser checks token users. Nothing is saved, so it can
+// be used by public routes.
+func (bc *BlogController) VerifyApiKey(key string, scope string) (*user.UserInformation, *dbController.ApiKeyDocument, error) {
+	apiKey, getErr := (*bc.DBController).GetApiKeyByHash(hashApiKey(key))
+
+	if _, ok := getErr.(dbController.NoResultsError); ok {
+		return nil, nil, NewInvalidApiKeyError("API key does not exist")
+	}
+
+	if getErr != nil {
+		return nil, nil, getErr
+	}
+
+	if !apiKey.IsUsable(time.Now()) {
+		return nil, nil, NewInvalidApiKeyError("API key was revoked or has expired")
+	}
+
+	if !apiKey.HasScope(scope) {
+		return nil, nil, NewMissingScopeError("API key does not have the " + scope + " scope")
+	}
+
+	owner, ownerErr := bc.getAuthUser(apiKey.OwnerId)
+
+	if ownerErr != nil {
+		return nil, nil, ownerErr
+	}
+
+	if owner == nil {
+		return nil, nil, NewUnauthorizedError("owner does not exist")
+	}
+
+	if !owner.Active {
+		return nil, nil, NewDeactivatedUserError("account deactivated")
+	}
+
+	if !canOwnApiKey(owner) {
+		return nil, nil, NewUnauthorizedError("owner can't edit the blog")
+	}
+
+	return owner, apiKey, nil
+}
+
+// CheckApiKey verifies the API key the same way as VerifyApiKey, then saves
+// the time the key was last used.
+func (bc *BlogController) CheckApiKey(key string, scope string) (*user.UserInformation, *dbController.ApiKeyDocument, error) {
+	owner, apiKey, verifyErr := bc.VerifyApiKey(key, scope)
+
+	if verifyErr != nil {
+		return nil, nil, verifyErr
+	}
+
+	now := time.Now()
+
+	// Failing to save the lastUsed time shouldn't fail the request
+	if apiKey.LastUsed == nil || now.Sub(*apiKey.LastUsed) >= API_KEY_LAST_USED_INTERVAL {
+		if usedErr := (*bc.DBController).SetApiKeyLastUsed(apiKey.Id, now); usedErr != nil {
+			print("Error saving API key last used: " + usedErr.Error() + "\n")
+		}
+	}
+
+	return owner, apiKey, nil
+}
+
 func (bc *BlogController) AddLogger(logger *logging.BlogLogger) {
 	bc.Loggers = append(bc.Loggers, logger)
 }
 
+// checkStatus makes sure that a blog post has a valid status, and that
+// scheduled posts have a time to be published at.
+func (bc *BlogController) checkStatus(status *string, publishAt *time.Time) error {
+	if status == nil {
+		return nil
+	}
+
+	if !dbController.IsValidBlogStatus(*status) {
+		return NewInputError("invalid status")
+	}
+
+	if *status == dbController.BLOG_STATUS_SCHEDULED && publishAt == nil {
+		return NewInputError("scheduled posts require publishAt")
+	}
+
+	return nil
+}
+
+// checkEditStatus runs checkStatus on the status and publishAt that the post
+// will have after the edit. Posts that aren't scheduled get their dateAdded as
+// publishAt, so the stored publishAt only counts if the post is scheduled.
+func (bc *BlogController) checkEditStatus(doc *dbController.EditBlogDocument) error {
+	if doc.Status == nil && doc.PublishAt == nil {
+		return nil
+	}
+
+	post, postErr := (*bc.DBController).GetBlogPostById(doc.Id, true)
+
+	// Missing posts are left for EditBlogPost to report, the same way as any
+	// other edit.
+	if _, ok := postErr.(dbController.NoResultsError); ok {
+		return bc.checkStatus(doc.Status, doc.PublishAt)
+	}
+
+	if postErr != nil {
+		return postErr
+	}
+
+	status := doc.Status
+	if status == nil {
+		status = &post.Status
+	}
+
+	publishAt := doc.PublishAt
+	if publishAt == nil && post.Status == dbController.BLOG_STATUS_SCHEDULED {
+		publishAt = &post.PublishAt
+	}
+
+	return bc.checkStatus(status, publishAt)
+}
+
+// makeBlogSummary returns the summary of a blog post from its rendered HTML.
+// A non empty excerpt replaces the one taken from the start of the body.
+func makeBlogSummary(bodyHtml string, excerpt string) dbController.BlogSummary {
+	summary := markdown.Summarize(bodyHtml)
+
+	blogSummary := dbController.BlogSummary{
+		Excerpt:            summary.Excerpt,
+		WordCount:          summary.WordCount,
+		ReadingTimeMinutes: summary.ReadingTimeMinutes,
+	}
+
+	if excerpt = strings.TrimSpace(excerpt); len(excerpt) > 0 {
+		blogSummary.Excerpt = excerpt
+		blogSummary.CustomExcerpt = true
+	}
+
+	return blogSummary
+}
+
+// renderEdit renders the body of an edit to HTML and updates the summary, so
+// that the stored HTML and summary always match the stored Markdown. Edits
+// that change neither the body nor the excerpt keep what they have. An empty
+// excerpt goes back to the one taken from the body, and a custom excerpt is
+// kept when only the body changes.
+func (bc *BlogController) renderEdit(doc *dbController.EditBlogDocument, excerpt *string) error {
+	if doc.Body == nil && excerpt == nil {
+		return nil
+	}
+
+	var current *dbController.BlogDocument
+
+	if doc.Body == nil || excerpt == nil {
+		post, postErr := bc.GetBlogPostById(doc.Id, true)
+
+		// Missing posts are left for EditBlogPost to report, the same way as
+		// any other edit.
+		if _, ok := postErr.(dbController.NoResultsError); ok {
+			return nil
+		}
+
+		if postErr != nil {
+			return postErr
+		}
+
+		current = post
+	}
+
+	var bodyHtml string
+
+	if doc.Body != nil {
+		var renderErr error
+		bodyHtml, renderErr = markdown.Render(*doc.Body)
+
+		if renderErr != nil {
+			return renderErr
+		}
+
+		doc.BodyHtml = &bodyHtml
+	} else {
+		bodyHtml = current.BodyHtml
+	}
+
+	var summary dbController.BlogSummary
+
+	if excerpt != nil {
+		summary = makeBlogSummary(bodyHtml, *excerpt)
+	} else if current.Summary.CustomExcerpt {
+		summary = makeBlogSummary(bodyHtml, current.Summary.Excerpt)
+	} else {
+		summary = makeBlogSummary(bodyHtml, "")
+	}
+
+	doc.Summary = &summary
+
+	return nil
+}
+
+// renderMissingFields renders the HTML and summary of blog posts saved before
+// they were made on write. They aren't saved, so those posts are rendered on
+// every read until they're edited.
+func (bc *BlogController) renderMissingFields(posts ...*dbController.BlogDocument) error {
+	for _, post := range posts {
+		if len(post.Body) == 0 {
+			continue
+		}
+
+		if len(post.BodyHtml) == 0 {
+			bodyHtml, renderErr := markdown.Render(post.Body)
+
+			if renderErr != nil {
+				return renderErr
+			}
+
+			post.BodyHtml = bodyHtml
+		}
+
+		if post.Summary.WordCount == 0 {
+			excerpt := ""
+			if post.Summary.CustomExcerpt {
+				excerpt = post.Summary.Excerpt
+			}
+
+			post.Summary = makeBlogSummary(post.BodyHtml, excerpt)
+		}
+	}
+
+	return nil
+}
+
+// withRenderedPost passes a blog post from the database through
+// renderMissingFields
+func (bc *BlogController) withRenderedPost(post *dbController.BlogDocument, err error) (*dbController.BlogDocument, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if renderErr := bc.renderMissingFields(post); renderErr != nil {
+		return nil, renderErr
+	}
+
+	return post, nil
+}
+
+// withRenderedPosts passes a page of blog posts from the database through
+// renderMissingFields
+func (bc *BlogController) withRenderedPosts(posts []*dbController.BlogDocument, err error) ([]*dbController.BlogDocument, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if renderErr := bc.renderMissingFields(posts...); renderErr != nil {
+		return nil, renderErr
+	}
+
+	return posts, nil
+}
+
 func (bc *BlogController) isValidSlug(_slug string) bool {
 	return slug.IsSlug(_slug)
 }
@@ -0,0 +1,206 @@
+package blogServer
+
+import (
+	"testing"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/claims"
+	"methompson.com/blog-microservice/blogServer/claims/claimsTest"
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/memoryDbController"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// makeTestController returns a controller backed by an empty memory database
+// whose claims are pushed to the returned fake client
+func makeTestController(t *testing.T) (*BlogController, *claimsTest.FakeAuthClient) {
+	t.Helper()
+
+	var dbc dbController.DatabaseController = memoryDbController.MakeMemoryDbController()
+
+	if initErr := dbc.InitDatabase(); initErr != nil {
+		t.Fatalf("InitDatabase: %v", initErr)
+	}
+
+	client := claimsTest.NewFakeAuthClient()
+
+	bc := InitController(&dbc)
+	bc.ClaimsSyncer = claims.NewSyncer(client)
+
+	return &bc, client
+}
+
+func TestUserChangesPushClaims(t *testing.T) {
+	bc, client := makeTestController(t)
+	client.AddUser("uid", map[string]interface{}{"other": 1})
+
+	if _, addErr := bc.AddUserData(AddUserBody{Uid: "uid", Name: "Name", Email: "uid@example.com", Role: "admin"}); addErr != nil {
+		t.Fatalf("AddUserData: %v", addErr)
+	}
+
+	checkClaims := func(step string, role string, active bool, revokeCount int) {
+		t.Helper()
+
+		actual := client.Claims("uid")
+
+		if actual[claims.ROLE_CLAIM] != role || actual[claims.ACTIVE_CLAIM] != active || actual["other"] != 1 {
+			t.Errorf("%s: claims %v", step, actual)
+		}
+
+		if client.RevokeCount("uid") != revokeCount {
+			t.Errorf("%s: revoked %d times, want %d", step, client.RevokeCount("uid"), revokeCount)
+		}
+	}
+
+	checkClaims("add", "admin", true, 0)
+
+	editor := "editor"
+
+	if _, editErr := bc.EditUserData(EditUserBody{Uid: "uid", Role: &editor}, "admin"); editErr != nil {
+		t.Fatalf("EditUserData: %v", editErr)
+	}
+
+	checkClaims("demote", "editor", true, 1)
+
+	if _, deactivateErr := bc.DeactivateUserData(UserBody{Uid: "uid"}, "admin"); deactivateErr != nil {
+		t.Fatalf("DeactivateUserData: %v", deactivateErr)
+	}
+
+	checkClaims("deactivate", "editor", false, 2)
+
+	if deleteErr := bc.DeleteUserData(UserBody{Uid: "uid"}, "admin"); deleteErr != nil {
+		t.Fatalf("DeleteUserData: %v", deleteErr)
+	}
+
+	if actual := client.Claims("uid"); len(actual) != 1 || actual["other"] != 1 {
+		t.Errorf("delete: claims %v", actual)
+	}
+}
+
+func TestUserChangesWithoutFirebaseUser(t *testing.T) {
+	bc, _ := makeTestController(t)
+
+	info, addErr := bc.AddUserData(AddUserBody{Uid: "uid", Name: "Name", Email: "uid@example.com", Role: "editor"})
+
+	if _, ok := addErr.(claims.SyncError); !ok {
+		t.Errorf("error %v isn't a SyncError", addErr)
+	}
+
+	// The user is still saved, and the claims are fixed by reconciling
+	if info == nil {
+		t.Fatalf("AddUserData didn't return the user")
+	}
+
+	if _, getErr := bc.GetUserData("uid"); getErr != nil {
+		t.Errorf("GetUserData: %v", getErr)
+	}
+}
+
+// countingDbController counts the users read from the database
+type countingDbController struct {
+	dbController.DatabaseController
+	userReads int
+}
+
+func (cdc *countingDbController) GetUserInformation(uid string) (*user.UserInformation, error) {
+	cdc.userReads++
+
+	return cdc.DatabaseController.GetUserInformation(uid)
+}
+
+// makeCachingTestController returns a controller whose users are cached for
+// the ttl, along with the database under it
+func makeCachingTestController(t *testing.T, ttl time.Duration) (*BlogController, *countingDbController) {
+	t.Helper()
+
+	bc, client := makeTestController(t)
+	client.AddUser("uid", nil)
+
+	cdc := &countingDbController{DatabaseController: *bc.DBController}
+	var dbc dbController.DatabaseController = cdc
+
+	bc.DBController = &dbc
+	bc.UserCache = NewUserCache(ttl)
+
+	if _, addErr := bc.AddUserData(AddUserBody{Uid: "uid", Name: "Name", Email: "uid@example.com", Role: "editor"}); addErr != nil {
+		t.Fatalf("AddUserData: %v", addErr)
+	}
+
+	cdc.userReads = 0
+
+	return bc, cdc
+}
+
+func TestCheckAuthorizedUserCachesUsers(t *testing.T) {
+	bc, cdc := makeCachingTestController(t, 50*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr != nil {
+			t.Fatalf("CheckAuthorizedUser: %v", checkErr)
+		}
+	}
+
+	// Missing users are cached too
+	for i := 0; i < 3; i++ {
+		if _, checkErr := bc.CheckAuthorizedUser("missing", "editor"); checkErr == nil {
+			t.Fatalf("CheckAuthorizedUser accepted a missing user")
+		}
+	}
+
+	if cdc.userReads != 2 {
+		t.Errorf("user reads %d, want 2", cdc.userReads)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr != nil {
+		t.Fatalf("CheckAuthorizedUser: %v", checkErr)
+	}
+
+	if cdc.userReads != 3 {
+		t.Errorf("user reads after expiry %d, want 3", cdc.userReads)
+	}
+}
+
+func TestDeactivationAppliesAfterTtl(t *testing.T) {
+	bc, cdc := makeCachingTestController(t, 50*time.Millisecond)
+
+	if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr != nil {
+		t.Fatalf("CheckAuthorizedUser: %v", checkErr)
+	}
+
+	// Changed by hand, so the cache doesn't know about it
+	active := false
+
+	if editErr := cdc.EditUserInformation(&dbController.EditUserDocument{Uid: "uid", Active: &active}); editErr != nil {
+		t.Fatalf("EditUserInformation: %v", editErr)
+	}
+
+	if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr != nil {
+		t.Errorf("deactivation applied before the ttl: %v", checkErr)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr == nil {
+		t.Errorf("deactivation didn't apply after the ttl")
+	} else if _, ok := checkErr.(DeactivatedUserError); !ok {
+		t.Errorf("error %T isn't a DeactivatedUserError", checkErr)
+	}
+}
+
+func TestDeactivationThroughControllerAppliesRightAway(t *testing.T) {
+	bc, _ := makeCachingTestController(t, time.Hour)
+
+	if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr != nil {
+		t.Fatalf("CheckAuthorizedUser: %v", checkErr)
+	}
+
+	if _, deactivateErr := bc.DeactivateUserData(UserBody{Uid: "uid"}, "admin"); deactivateErr != nil {
+		t.Fatalf("DeactivateUserData: %v", deactivateErr)
+	}
+
+	if _, checkErr := bc.CheckAuthorizedUser("uid", "editor"); checkErr == nil {
+		t.Errorf("deactivation didn't apply")
+	}
+}
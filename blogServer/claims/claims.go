@@ -0,0 +1,283 @@
+package claims
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/api/iterator"
+
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// The custom claims the blog sets on Firebase users. Other custom claims are
+// left alone.
+const (
+	ROLE_CLAIM   = "role"
+	NAME_CLAIM   = "name"
+	ACTIVE_CLAIM = "active"
+)
+
+var BLOG_CLAIMS = []string{ROLE_CLAIM, NAME_CLAIM, ACTIVE_CLAIM}
+
+// AuthClient is the part of the Firebase auth client that the Syncer uses.
+// FirebaseClient adapts an *auth.Client to it.
+type AuthClient interface {
+	GetUser(ctx context.Context, uid string) (*auth.UserRecord, error)
+	SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error
+	RevokeRefreshTokens(ctx context.Context, uid string) error
+	ListUsers(ctx context.Context) UserIterator
+}
+
+// UserIterator is the part of *auth.UserIterator that Reconcile uses. Next
+// returns iterator.Done after the last user.
+type UserIterator interface {
+	Next() (*auth.ExportedUserRecord, error)
+}
+
+// FirebaseClient is the AuthClient of a Firebase project
+type FirebaseClient struct {
+	*auth.Client
+}
+
+// ListUsers iterates over every user of the Firebase project
+func (fc FirebaseClient) ListUsers(ctx context.Context) UserIterator {
+	return fc.Client.Users(ctx, "")
+}
+
+// A SyncError means the users in the database and the Firebase claims may no
+// longer agree. Reconcile fixes the drift.
+type SyncError struct{ ErrMsg string }
+
+func (err SyncError) Error() string { return err.ErrMsg }
+func NewSyncError(msg string) error { return SyncError{msg} }
+
+// The Syncer keeps the Firebase custom claims of users the same as their
+// information in the database, which is where roles are managed.
+type Syncer struct {
+	Client AuthClient
+}
+
+func NewSyncer(client AuthClient) *Syncer {
+	return &Syncer{Client: client}
+}
+
+// GetUserClaims returns the blog claims a user should have
+func GetUserClaims(info *user.UserInformation) map[string]interface{} {
+	return map[string]interface{}{
+		ROLE_CLAIM:   info.Role.String(),
+		NAME_CLAIM:   info.Name,
+		ACTIVE_CLAIM: info.Active,
+	}
+}
+
+// getBlogClaims returns the blog claims from the custom claims of a Firebase
+// user.
+func getBlogClaims(customClaims map[string]interface{}) map[string]interface{} {
+	output := make(map[string]interface{})
+
+	for _, key := range BLOG_CLAIMS {
+		if value, ok := customClaims[key]; ok {
+			output[key] = value
+		}
+	}
+
+	return output
+}
+
+// mergeClaims returns the custom claims with the blog claims of the user. A
+// nil user removes the blog claims.
+func mergeClaims(customClaims map[string]interface{}, info *user.UserInformation) map[string]interface{} {
+	output := make(map[string]interface{})
+
+	for key, value := range customClaims {
+		output[key] = value
+	}
+
+	for _, key := range BLOG_CLAIMS {
+		delete(output, key)
+	}
+
+	if info != nil {
+		for key, value := range GetUserClaims(info) {
+			output[key] = value
+		}
+	}
+
+	return output
+}
+
+// grantsMore returns whether the blog claims give more access than the user
+// should have. Users that no longer exist should have no access at all.
+func grantsMore(blogClaims map[string]interface{}, info *user.UserInformation) bool {
+	role, roleOk := blogClaims[ROLE_CLAIM].(string)
+
+	if !roleOk {
+		return false
+	}
+
+	if info == nil {
+		return true
+	}
+
+	// Tokens without an active claim are treated as active, since the role
+	// claim alone gives access.
+	active, activeOk := blogClaims[ACTIVE_CLAIM].(bool)
+	if !activeOk {
+		active = true
+	}
+
+	userType, roleErr := user.ParseUserType(role)
+
+	return (active && !info.Active) || (roleErr == nil && userType > info.Role)
+}
+
+// setClaims replaces the blog claims of the Firebase user with those of the
+// user, or removes them for a nil user. Refresh tokens are revoked when the
+// old claims gave more access, so that the user has to sign in again to get
+// a token with the new claims.
+func (s *Syncer) setClaims(ctx context.Context, uid string, customClaims map[string]interface{}, info *user.UserInformation) error {
+	claimsErr := s.Client.SetCustomUserClaims(ctx, uid, mergeClaims(customClaims, info))
+
+	if claimsErr != nil {
+		return NewSyncError("error setting claims of " + uid + ": " + claimsErr.Error())
+	}
+
+	if grantsMore(getBlogClaims(customClaims), info) {
+		if revokeErr := s.Client.RevokeRefreshTokens(ctx, uid); revokeErr != nil {
+			return NewSyncError("error revoking tokens of " + uid + ": " + revokeErr.Error())
+		}
+	}
+
+	return nil
+}
+
+// SyncUser pushes a change of a user to their Firebase claims. previous is the
+// user before the change and current the user after it. current is nil for
+// deleted users and previous is nil for new users.
+func (s *Syncer) SyncUser(ctx context.Context, previous *user.UserInformation, current *user.UserInformation) error {
+	uid := ""
+	if current != nil {
+		uid = current.Uid
+	} else if previous != nil {
+		uid = previous.Uid
+	}
+
+	if len(uid) == 0 {
+		return nil
+	}
+
+	record, getErr := s.Client.GetUser(ctx, uid)
+
+	if getErr != nil {
+		return NewSyncError("error getting Firebase user " + uid + ": " + getErr.Error())
+	}
+
+	return s.setClaims(ctx, uid, record.CustomClaims, current)
+}
+
+// The problems Reconcile finds
+const (
+	DRIFT_CLAIMS_DIFFER     = "claims differ from the database"
+	DRIFT_NOT_IN_DATABASE   = "claims for a user that isn't in the database"
+	DRIFT_NOT_IN_FIREBASE   = "user isn't in Firebase"
+	DRIFT_UNFIXABLE_MESSAGE = "can't be fixed automatically"
+)
+
+// A Drift is a user whose Firebase claims don't match the database. Expected
+// is nil for users that aren't in the database and Actual is nil for users
+// that aren't in Firebase.
+type Drift struct {
+	Uid      string
+	Problem  string
+	Expected map[string]interface{}
+	Actual   map[string]interface{}
+	Fixed    bool
+	FixError error
+}
+
+// Reconcile compares the blog claims of every Firebase user with the users in
+// the database and returns the differences, sorted by uid. With fix set, the
+// claims are changed to match the database. Users that aren't in Firebase
+// are reported, but can't be fixed.
+func (s *Syncer) Reconcile(ctx context.Context, users []*user.UserInformation, fix bool) ([]*Drift, error) {
+	usersByUid := make(map[string]*user.UserInformation)
+	for _, info := range users {
+		usersByUid[info.Uid] = info
+	}
+
+	seen := make(map[string]bool)
+	drifts := make([]*Drift, 0)
+
+	it := s.Client.ListUsers(ctx)
+
+	for {
+		record, nextErr := it.Next()
+
+		if nextErr == iterator.Done {
+			break
+		}
+
+		if nextErr != nil {
+			return nil, NewSyncError("error listing Firebase users: " + nextErr.Error())
+		}
+
+		seen[record.UID] = true
+
+		info := usersByUid[record.UID]
+		actual := getBlogClaims(record.CustomClaims)
+
+		drift := Drift{
+			Uid:    record.UID,
+			Actual: actual,
+		}
+
+		if info == nil {
+			if len(actual) == 0 {
+				continue
+			}
+
+			drift.Problem = DRIFT_NOT_IN_DATABASE
+		} else {
+			drift.Expected = GetUserClaims(info)
+
+			if reflect.DeepEqual(drift.Expected, actual) {
+				continue
+			}
+
+			drift.Problem = DRIFT_CLAIMS_DIFFER
+		}
+
+		if fix {
+			drift.FixError = s.setClaims(ctx, record.UID, record.CustomClaims, info)
+			drift.Fixed = drift.FixError == nil
+		}
+
+		drifts = append(drifts, &drift)
+	}
+
+	for _, info := range users {
+		if seen[info.Uid] {
+			continue
+		}
+
+		drift := Drift{
+			Uid:      info.Uid,
+			Problem:  DRIFT_NOT_IN_FIREBASE,
+			Expected: GetUserClaims(info),
+		}
+
+		if fix {
+			drift.FixError = NewSyncError(DRIFT_UNFIXABLE_MESSAGE)
+		}
+
+		drifts = append(drifts, &drift)
+	}
+
+	sort.SliceStable(drifts, func(i, j int) bool {
+		return drifts[i].Uid < drifts[j].Uid
+	})
+
+	return drifts, nil
+}
@@ -0,0 +1,147 @@
+// Package claimsTest has a claims.AuthClient that keeps Firebase users in
+// memory, so that claims can be tested without a Firebase project.
+package claimsTest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/api/iterator"
+
+	"methompson.com/blog-microservice/blogServer/claims"
+)
+
+// FakeAuthClient is an AuthClient whose users only live in memory. Getting
+// or changing a user that wasn't added is an error, the same as in Firebase.
+type FakeAuthClient struct {
+	mutex   sync.Mutex
+	claims  map[string]map[string]interface{}
+	revoked map[string]int
+}
+
+func NewFakeAuthClient() *FakeAuthClient {
+	return &FakeAuthClient{
+		claims:  make(map[string]map[string]interface{}),
+		revoked: make(map[string]int),
+	}
+}
+
+func copyClaims(customClaims map[string]interface{}) map[string]interface{} {
+	if customClaims == nil {
+		return nil
+	}
+
+	output := make(map[string]interface{})
+	for key, value := range customClaims {
+		output[key] = value
+	}
+
+	return output
+}
+
+func makeUserRecord(uid string, customClaims map[string]interface{}) *auth.UserRecord {
+	return &auth.UserRecord{
+		UserInfo:     &auth.UserInfo{UID: uid},
+		CustomClaims: copyClaims(customClaims),
+	}
+}
+
+// AddUser adds a Firebase user with the custom claims
+func (f *FakeAuthClient) AddUser(uid string, customClaims map[string]interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.claims[uid] = copyClaims(customClaims)
+}
+
+// Claims returns the custom claims of the user
+func (f *FakeAuthClient) Claims(uid string) map[string]interface{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return copyClaims(f.claims[uid])
+}
+
+// RevokeCount returns the number of times the refresh tokens of the user were
+// revoked
+func (f *FakeAuthClient) RevokeCount(uid string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.revoked[uid]
+}
+
+func (f *FakeAuthClient) GetUser(ctx context.Context, uid string) (*auth.UserRecord, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	customClaims, ok := f.claims[uid]
+
+	if !ok {
+		return nil, errors.New("no user record found for the given identifier")
+	}
+
+	return makeUserRecord(uid, customClaims), nil
+}
+
+func (f *FakeAuthClient) SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.claims[uid]; !ok {
+		return errors.New("no user record found for the given identifier")
+	}
+
+	f.claims[uid] = copyClaims(customClaims)
+
+	return nil
+}
+
+func (f *FakeAuthClient) RevokeRefreshTokens(ctx context.Context, uid string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.claims[uid]; !ok {
+		return errors.New("no user record found for the given identifier")
+	}
+
+	f.revoked[uid]++
+
+	return nil
+}
+
+// ListUsers iterates over the users as they are now, sorted by uid
+func (f *FakeAuthClient) ListUsers(ctx context.Context) claims.UserIterator {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	records := make([]*auth.ExportedUserRecord, 0)
+
+	for uid, customClaims := range f.claims {
+		records = append(records, &auth.ExportedUserRecord{UserRecord: makeUserRecord(uid, customClaims)})
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].UID < records[j].UID
+	})
+
+	return &fakeUserIterator{records}
+}
+
+type fakeUserIterator struct {
+	records []*auth.ExportedUserRecord
+}
+
+func (it *fakeUserIterator) Next() (*auth.ExportedUserRecord, error) {
+	if len(it.records) == 0 {
+		return nil, iterator.Done
+	}
+
+	record := it.records[0]
+	it.records = it.records[1:]
+
+	return record, nil
+}
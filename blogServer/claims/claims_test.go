@@ -0,0 +1,199 @@
+package claims_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"methompson.com/blog-microservice/blogServer/claims"
+	"methompson.com/blog-microservice/blogServer/claims/claimsTest"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+func makeUser(uid string, role user.UserType, active bool) *user.UserInformation {
+	return &user.UserInformation{
+		Uid:    uid,
+		Name:   uid,
+		Email:  uid + "@example.com",
+		Active: active,
+		Role:   role,
+	}
+}
+
+func TestSyncUser(t *testing.T) {
+	editor := makeUser("uid", user.Editor, true)
+	admin := makeUser("uid", user.Admin, true)
+	reader := makeUser("uid", user.Reader, true)
+	inactive := makeUser("uid", user.Editor, false)
+
+	tests := []struct {
+		name       string
+		claims     map[string]interface{}
+		previous   *user.UserInformation
+		current    *user.UserInformation
+		wantClaims map[string]interface{}
+		wantRevoke bool
+	}{
+		{
+			name:       "new user",
+			claims:     map[string]interface{}{"other": 1},
+			current:    editor,
+			wantClaims: map[string]interface{}{"other": 1, "role": "editor", "name": "uid", "active": true},
+		},
+		{
+			name:       "promotion",
+			claims:     claims.GetUserClaims(editor),
+			previous:   editor,
+			current:    admin,
+			wantClaims: claims.GetUserClaims(admin),
+		},
+		{
+			name:       "demotion",
+			claims:     claims.GetUserClaims(admin),
+			previous:   admin,
+			current:    reader,
+			wantClaims: claims.GetUserClaims(reader),
+			wantRevoke: true,
+		},
+		{
+			name:       "deactivation",
+			claims:     claims.GetUserClaims(editor),
+			previous:   editor,
+			current:    inactive,
+			wantClaims: claims.GetUserClaims(inactive),
+			wantRevoke: true,
+		},
+		{
+			name:       "deactivation of claims without active",
+			claims:     map[string]interface{}{"role": "editor"},
+			previous:   editor,
+			current:    inactive,
+			wantClaims: claims.GetUserClaims(inactive),
+			wantRevoke: true,
+		},
+		{
+			name:       "deleted user",
+			claims:     map[string]interface{}{"other": 1, "role": "editor", "name": "uid", "active": true},
+			previous:   editor,
+			wantClaims: map[string]interface{}{"other": 1},
+			wantRevoke: true,
+		},
+	}
+
+	for _, test := range tests {
+		client := claimsTest.NewFakeAuthClient()
+		client.AddUser("uid", test.claims)
+
+		syncErr := claims.NewSyncer(client).SyncUser(context.Background(), test.previous, test.current)
+
+		if syncErr != nil {
+			t.Errorf("%s: %v", test.name, syncErr)
+			continue
+		}
+
+		if actual := client.Claims("uid"); !reflect.DeepEqual(actual, test.wantClaims) {
+			t.Errorf("%s: claims %v, want %v", test.name, actual, test.wantClaims)
+		}
+
+		if revoked := client.RevokeCount("uid") > 0; revoked != test.wantRevoke {
+			t.Errorf("%s: revoked %v, want %v", test.name, revoked, test.wantRevoke)
+		}
+	}
+}
+
+func TestSyncUserWithoutFirebaseUser(t *testing.T) {
+	client := claimsTest.NewFakeAuthClient()
+
+	syncErr := claims.NewSyncer(client).SyncUser(context.Background(), nil, makeUser("uid", user.Editor, true))
+
+	if _, ok := syncErr.(claims.SyncError); !ok {
+		t.Errorf("error %v isn't a SyncError", syncErr)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	inSync := makeUser("in-sync", user.Editor, true)
+	demoted := makeUser("demoted", user.Reader, true)
+	deactivated := makeUser("deactivated", user.Editor, false)
+	notInFirebase := makeUser("not-in-firebase", user.Editor, true)
+	users := []*user.UserInformation{inSync, demoted, deactivated, notInFirebase}
+
+	client := claimsTest.NewFakeAuthClient()
+	client.AddUser("in-sync", claims.GetUserClaims(inSync))
+	client.AddUser("demoted", claims.GetUserClaims(makeUser("demoted", user.Admin, true)))
+	client.AddUser("deactivated", map[string]interface{}{"role": "editor"})
+	client.AddUser("not-in-database", map[string]interface{}{"role": "admin", "other": 1})
+	client.AddUser("no-claims", nil)
+
+	syncer := claims.NewSyncer(client)
+
+	drifts, reconcileErr := syncer.Reconcile(context.Background(), users, false)
+
+	if reconcileErr != nil {
+		t.Fatalf("Reconcile: %v", reconcileErr)
+	}
+
+	wantProblems := map[string]string{
+		"deactivated":     claims.DRIFT_CLAIMS_DIFFER,
+		"demoted":         claims.DRIFT_CLAIMS_DIFFER,
+		"not-in-database": claims.DRIFT_NOT_IN_DATABASE,
+		"not-in-firebase": claims.DRIFT_NOT_IN_FIREBASE,
+	}
+
+	if len(drifts) != len(wantProblems) {
+		t.Fatalf("drifts %d, want %d", len(drifts), len(wantProblems))
+	}
+
+	for i, drift := range drifts {
+		if i > 0 && drifts[i-1].Uid > drift.Uid {
+			t.Errorf("drifts aren't sorted by uid")
+		}
+
+		if drift.Problem != wantProblems[drift.Uid] {
+			t.Errorf("%s: problem %q, want %q", drift.Uid, drift.Problem, wantProblems[drift.Uid])
+		}
+
+		if drift.Fixed {
+			t.Errorf("%s: fixed without fix", drift.Uid)
+		}
+	}
+
+	if client.RevokeCount("demoted") > 0 || !reflect.DeepEqual(client.Claims("in-sync"), claims.GetUserClaims(inSync)) {
+		t.Errorf("Reconcile changed claims without fix")
+	}
+
+	drifts, reconcileErr = syncer.Reconcile(context.Background(), users, true)
+
+	if reconcileErr != nil {
+		t.Fatalf("Reconcile: %v", reconcileErr)
+	}
+
+	for _, drift := range drifts {
+		wantFixed := drift.Problem != claims.DRIFT_NOT_IN_FIREBASE
+
+		if drift.Fixed != wantFixed {
+			t.Errorf("%s: fixed %v, want %v (%v)", drift.Uid, drift.Fixed, wantFixed, drift.FixError)
+		}
+	}
+
+	if actual := client.Claims("demoted"); !reflect.DeepEqual(actual, claims.GetUserClaims(demoted)) {
+		t.Errorf("demoted claims %v", actual)
+	}
+
+	if actual := client.Claims("not-in-database"); !reflect.DeepEqual(actual, map[string]interface{}{"other": 1}) {
+		t.Errorf("not-in-database claims %v", actual)
+	}
+
+	for _, uid := range []string{"demoted", "deactivated", "not-in-database"} {
+		if client.RevokeCount(uid) != 1 {
+			t.Errorf("%s: revoked %d times, want 1", uid, client.RevokeCount(uid))
+		}
+	}
+
+	// Once fixed, only the user that can't be fixed is left
+	drifts, reconcileErr = syncer.Reconcile(context.Background(), users, false)
+
+	if reconcileErr != nil || len(drifts) != 1 || drifts[0].Uid != "not-in-firebase" {
+		t.Errorf("drifts after the fix %v %v", drifts, reconcileErr)
+	}
+}
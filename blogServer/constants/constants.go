@@ -3,13 +3,47 @@ package constants
 const GOOGLE_APPLICATION_CREDENTIALS = "GOOGLE_APPLICATION_CREDENTIALS"
 const FIREBASE_AUTH_EMULATOR_HOST = "FIREBASE_AUTH_EMULATOR_HOST"
 
+const AUTH_PROVIDER = "AUTH_PROVIDER"
+const AUTH_PROVIDER_FIREBASE = "firebase"
+const AUTH_PROVIDER_LOCAL = "local"
+
+const LOCAL_AUTH_HS256_SECRET = "LOCAL_AUTH_HS256_SECRET"
+const LOCAL_AUTH_RS256_KEY_PATH = "LOCAL_AUTH_RS256_KEY_PATH"
+const LOCAL_AUTH_JWKS_PATH = "LOCAL_AUTH_JWKS_PATH"
+const LOCAL_AUTH_ISSUER = "LOCAL_AUTH_ISSUER"
+const LOCAL_AUTH_AUDIENCE = "LOCAL_AUTH_AUDIENCE"
+
 const BLOG_DB_NAME = "blog"
 const GIN_MODE = "GIN_MODE"
 
+const DB_TYPE = "DB_TYPE"
+const DB_TYPE_MONGODB = "mongodb"
+const DB_TYPE_MEMORY = "memory"
+const DB_TYPE_SQLITE = "sqlite"
+const DB_TYPE_POSTGRES = "postgres"
+
 const MONGO_DB_URL = "MONGO_DB_URL"
 const MONGO_DB_USERNAME = "MONGO_DB_USERNAME"
 const MONGO_DB_PASSWORD = "MONGO_DB_PASSWORD"
 
+const SQLITE_DB_PATH = "SQLITE_DB_PATH"
+
+const POSTGRES_DB_URL = "POSTGRES_DB_URL"
+
+const TRASH_RETENTION_DAYS = "TRASH_RETENTION_DAYS"
+
+const SLUG_MODE = "SLUG_MODE"
+
+const API_VERSION = "API_VERSION"
+
+const PUBLIC_BASE_URL = "PUBLIC_BASE_URL"
+const POST_PATH_TEMPLATE = "POST_PATH_TEMPLATE"
+const TAG_PATH_TEMPLATE = "TAG_PATH_TEMPLATE"
+const AUTHOR_PATH_TEMPLATE = "AUTHOR_PATH_TEMPLATE"
+
+const FEED_TITLE = "FEED_TITLE"
+const FEED_DESCRIPTION = "FEED_DESCRIPTION"
+
 const USER_ADMIN = "admin"
 const USER_EDITOR = "editor"
 const USER_VIEWER = "viewer"
@@ -0,0 +1,82 @@
+package blogServer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// cursorToken is the content of the opaque cursor tokens given to clients
+type cursorToken struct {
+	DateAdded int64  `json:"d"`
+	Id        string `json:"i"`
+	Previous  bool   `json:"p,omitempty"`
+}
+
+func EncodeBlogCursor(cursor *dbController.BlogCursor) string {
+	data, _ := json.Marshal(cursorToken{
+		DateAdded: cursor.DateAdded.Unix(),
+		Id:        cursor.Id,
+		Previous:  cursor.Previous,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeBlogCursor(token string) (*dbController.BlogCursor, error) {
+	data, decodeErr := base64.RawURLEncoding.DecodeString(token)
+
+	if decodeErr != nil {
+		return nil, NewInputError("invalid cursor")
+	}
+
+	var ct cursorToken
+	if unmarshalErr := json.Unmarshal(data, &ct); unmarshalErr != nil || len(ct.Id) == 0 {
+		return nil, NewInputError("invalid cursor")
+	}
+
+	cursor := dbController.BlogCursor{
+		DateAdded: time.Unix(ct.DateAdded, 0),
+		Id:        ct.Id,
+		Previous:  ct.Previous,
+	}
+
+	return &cursor, nil
+}
+
+// A BlogCursorPage is a page of blog posts found with a cursor. Next and Prev
+// are nil when there are no more posts in that direction.
+type BlogCursorPage struct {
+	Posts []*dbController.BlogDocument
+	Total int
+	Next  *dbController.BlogCursor
+	Prev  *dbController.BlogCursor
+}
+
+func (bcp *BlogCursorPage) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	posts := make([]map[string]interface{}, 0)
+	for _, post := range bcp.Posts {
+		posts = append(posts, *post.GetMap())
+	}
+
+	m["posts"] = posts
+	m["total"] = bcp.Total
+	m["hasNext"] = bcp.Next != nil
+	m["hasPrev"] = bcp.Prev != nil
+	m["next"] = nil
+	m["prev"] = nil
+
+	if bcp.Next != nil {
+		m["next"] = EncodeBlogCursor(bcp.Next)
+	}
+
+	if bcp.Prev != nil {
+		m["prev"] = EncodeBlogCursor(bcp.Prev)
+	}
+
+	return &m
+}
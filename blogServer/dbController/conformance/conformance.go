@@ -0,0 +1,2218 @@
+// Package conformance contains a test suite that every implementation of
+// dbController.DatabaseController is expected to pass. Backends run the suite
+// from their own tests by passing a ControllerFactory to RunConformanceSuite:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.RunConformanceSuite(t, func(t *testing.T) dbController.DatabaseController {
+//			return memoryDbController.MakeMemoryDbController()
+//		})
+//	}
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// UNKNOWN_ID is a well formed blog post id that should never be returned by
+// a backend.
+const UNKNOWN_ID = "000000000000000000000000"
+
+// INVALID_ID is an id that no backend should accept as well formed.
+const INVALID_ID = "not-a-valid-id"
+
+// ControllerFactory returns an empty DatabaseController. The suite calls
+// InitDatabase itself. Each test in the suite gets its own controller, so the
+// factory should use t.Cleanup to remove anything it creates.
+type ControllerFactory func(t *testing.T) dbController.DatabaseController
+
+type conformanceTest struct {
+	name string
+	run  func(t *testing.T, dbc dbController.DatabaseController)
+}
+
+// RunConformanceSuite runs every conformance test as a subtest of t against a
+// fresh controller made by the factory.
+func RunConformanceSuite(t *testing.T, factory ControllerFactory) {
+	tests := []conformanceTest{
+		{"InitDatabaseIsRepeatable", testInitDatabaseIsRepeatable},
+		{"AddBlogPost", testAddBlogPost},
+		{"AddBlogPostDefaults", testAddBlogPostDefaults},
+		{"AddBlogPostDuplicateSlug", testAddBlogPostDuplicateSlug},
+		{"GetBlogPostById", testGetBlogPostById},
+		{"GetBlogPostBySlug", testGetBlogPostBySlug},
+		{"GetBlogPostsPaging", testGetBlogPostsPaging},
+		{"CountBlogPosts", testCountBlogPosts},
+		{"GetBlogPostsByCursor", testGetBlogPostsByCursor},
+		{"PublicVisibility", testPublicVisibility},
+		{"EditBlogPostStatus", testEditBlogPostStatus},
+		{"EditBlogPostPartialUpdate", testEditBlogPostPartialUpdate},
+		{"EditBlogPostDates", testEditBlogPostDates},
+		{"EditBlogPostSlug", testEditBlogPostSlug},
+		{"OldSlugs", testOldSlugs},
+		{"IsSlugAvailable", testIsSlugAvailable},
+		{"EditBlogPostMissing", testEditBlogPostMissing},
+		{"BodyHtml", testBodyHtml},
+		{"BlogSummary", testBlogSummary},
+		{"DeleteBlogPost", testDeleteBlogPost},
+		{"Trash", testTrash},
+		{"PurgeTrashedBlogPosts", testPurgeTrashedBlogPosts},
+		{"GetBlogTags", testGetBlogTags},
+		{"GetBlogPostsByTag", testGetBlogPostsByTag},
+		{"GetBlogAuthor", testGetBlogAuthor},
+		{"GetBlogPostsByAuthor", testGetBlogPostsByAuthor},
+		{"GetBlogAuthors", testGetBlogAuthors},
+		{"GetBlogArchive", testGetBlogArchive},
+		{"GetBlogPostsByDateRange", testGetBlogPostsByDateRange},
+		{"GetSitemapEntries", testGetSitemapEntries},
+		{"SearchBlogPosts", testSearchBlogPosts},
+		{"SearchBlogPostsInvalid", testSearchBlogPostsInvalid},
+		{"RevisionOnAdd", testRevisionOnAdd},
+		{"RevisionOnEdit", testRevisionOnEdit},
+		{"RevisionRestore", testRevisionRestore},
+		{"RevisionsPurgedWithPost", testRevisionsPurgedWithPost},
+		{"AddUserInformation", testAddUserInformation},
+		{"AddUserInformationDuplicateEmail", testAddUserInformationDuplicateEmail},
+		{"GetUsers", testGetUsers},
+		{"EditUserInformation", testEditUserInformation},
+		{"DeleteUserInformation", testDeleteUserInformation},
+		{"AddApiKey", testAddApiKey},
+		{"GetApiKeys", testGetApiKeys},
+		{"RevokeApiKey", testRevokeApiKey},
+		{"SetApiKeyLastUsed", testSetApiKeyLastUsed},
+		{"Logs", testLogs},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			dbc := factory(t)
+
+			if initErr := dbc.InitDatabase(); initErr != nil {
+				t.Fatalf("InitDatabase: %v", initErr)
+			}
+
+			test.run(t, dbc)
+		})
+	}
+}
+
+/****************************************************************************************
+* Helpers
+****************************************************************************************/
+
+func stringPtr(s string) *string     { return &s }
+func timePtr(t time.Time) *time.Time { return &t }
+func boolPtr(b bool) *bool           { return &b }
+
+func makeAddDocument(slug string, dateAdded int64) *dbController.AddBlogDocument {
+	tags := []string{"tag1", "tag2"}
+
+	return &dbController.AddBlogDocument{
+		Title:     "Title " + slug,
+		Slug:      slug,
+		Body:      "Body " + slug,
+		Tags:      &tags,
+		AuthorId:  "author-uid",
+		DateAdded: time.Unix(dateAdded, 0),
+	}
+}
+
+func mustAddBlogPost(t *testing.T, dbc dbController.DatabaseController, doc *dbController.AddBlogDocument) string {
+	t.Helper()
+
+	id, err := dbc.AddBlogPost(doc)
+
+	if err != nil {
+		t.Fatalf("AddBlogPost(%q): %v", doc.Slug, err)
+	}
+
+	if len(id) == 0 {
+		t.Fatalf("AddBlogPost(%q) returned an empty id", doc.Slug)
+	}
+
+	return id
+}
+
+func mustGetBlogPostById(t *testing.T, dbc dbController.DatabaseController, id string) *dbController.BlogDocument {
+	t.Helper()
+
+	post, err := dbc.GetBlogPostById(id, true)
+
+	if err != nil {
+		t.Fatalf("GetBlogPostById(%q): %v", id, err)
+	}
+
+	return post
+}
+
+func mustAddUser(t *testing.T, dbc dbController.DatabaseController, uid string, name string, email string) {
+	t.Helper()
+
+	info := user.UserInformation{
+		Uid:    uid,
+		Name:   name,
+		Email:  email,
+		Active: true,
+		Role:   user.Editor,
+	}
+
+	if err := dbc.AddUserInformation(&info); err != nil {
+		t.Fatalf("AddUserInformation(%q): %v", uid, err)
+	}
+}
+
+func expectNoResultsError(t *testing.T, err error) {
+	t.Helper()
+
+	if _, ok := err.(dbController.NoResultsError); !ok {
+		t.Errorf("expected NoResultsError, got %T (%v)", err, err)
+	}
+}
+
+func expectInvalidInputError(t *testing.T, err error) {
+	t.Helper()
+
+	if _, ok := err.(dbController.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError, got %T (%v)", err, err)
+	}
+}
+
+func expectDuplicateEntryError(t *testing.T, err error) {
+	t.Helper()
+
+	if _, ok := err.(dbController.DuplicateEntryError); !ok {
+		t.Errorf("expected DuplicateEntryError, got %T (%v)", err, err)
+	}
+}
+
+func expectString(t *testing.T, field string, got string, want string) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("%s: got %q, want %q", field, got, want)
+	}
+}
+
+func expectTime(t *testing.T, field string, got time.Time, want int64) {
+	t.Helper()
+
+	if got.Unix() != want {
+		t.Errorf("%s: got %d, want %d", field, got.Unix(), want)
+	}
+}
+
+func expectTags(t *testing.T, got []string, want []string) {
+	t.Helper()
+
+	expectStrings(t, "tags", got, want)
+}
+
+func expectStrings(t *testing.T, field string, got []string, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("%s: got %v, want %v", field, got, want)
+		return
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s: got %v, want %v", field, got, want)
+			return
+		}
+	}
+}
+
+func expectCount(t *testing.T, call string, got int, err error, want int) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s: %v", call, err)
+	}
+
+	if got != want {
+		t.Errorf("%s: got %d, want %d", call, got, want)
+	}
+}
+
+func expectSlugs(t *testing.T, posts []*dbController.BlogDocument, want []string) {
+	t.Helper()
+
+	got := make([]string, 0)
+	for _, post := range posts {
+		got = append(got, post.Slug)
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("slugs: got %v, want %v", got, want)
+		return
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("slugs: got %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+/****************************************************************************************
+* Tests
+****************************************************************************************/
+
+func testInitDatabaseIsRepeatable(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddBlogPost(t, dbc, makeAddDocument("init-post", 1000))
+
+	if err := dbc.InitDatabase(); err != nil {
+		t.Fatalf("second InitDatabase: %v", err)
+	}
+
+	if _, err := dbc.GetBlogPostBySlug("init-post", true); err != nil {
+		t.Errorf("post lost after second InitDatabase: %v", err)
+	}
+}
+
+func testAddBlogPost(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+	mustAddUser(t, dbc, "update-uid", "Update Name", "update@example.com")
+
+	doc := makeAddDocument("add-post", 1000)
+	doc.UpdateAuthorId = stringPtr("update-uid")
+	doc.DateUpdated = timePtr(time.Unix(2000, 0))
+
+	id := mustAddBlogPost(t, dbc, doc)
+	post := mustGetBlogPostById(t, dbc, id)
+
+	expectString(t, "id", post.Id, id)
+	expectString(t, "title", post.Title, doc.Title)
+	expectString(t, "slug", post.Slug, doc.Slug)
+	expectString(t, "body", post.Body, doc.Body)
+	expectTags(t, post.Tags, *doc.Tags)
+	expectString(t, "authorId", post.AuthorId, "author-uid")
+	expectString(t, "author", post.Author, "Author Name")
+	expectString(t, "updateAuthorId", post.UpdateAuthorId, "update-uid")
+	expectString(t, "updateAuthor", post.UpdateAuthor, "Update Name")
+	expectTime(t, "dateAdded", post.DateAdded, 1000)
+	expectTime(t, "dateUpdated", post.DateUpdated, 2000)
+}
+
+func testAddBlogPostDefaults(t *testing.T, dbc dbController.DatabaseController) {
+	doc := makeAddDocument("defaults-post", 1000)
+	doc.Tags = nil
+	// Sub-second precision is dropped by every backend
+	doc.DateAdded = time.Unix(1000, 500)
+
+	id := mustAddBlogPost(t, dbc, doc)
+	post := mustGetBlogPostById(t, dbc, id)
+
+	expectString(t, "updateAuthorId", post.UpdateAuthorId, doc.AuthorId)
+	expectString(t, "author", post.Author, "")
+	expectTime(t, "dateAdded", post.DateAdded, 1000)
+	expectTime(t, "dateUpdated", post.DateUpdated, 1000)
+	expectString(t, "status", post.Status, dbController.BLOG_STATUS_PUBLISHED)
+	expectTime(t, "publishAt", post.PublishAt, 1000)
+
+	if len(post.Tags) != 0 {
+		t.Errorf("tags: got %v, want none", post.Tags)
+	}
+}
+
+func testAddBlogPostDuplicateSlug(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddBlogPost(t, dbc, makeAddDocument("duplicate-post", 1000))
+
+	_, err := dbc.AddBlogPost(makeAddDocument("duplicate-post", 2000))
+	expectDuplicateEntryError(t, err)
+
+	posts, postsErr := dbc.GetBlogPosts(1, 10, true)
+
+	if postsErr != nil {
+		t.Fatalf("GetBlogPosts: %v", postsErr)
+	}
+
+	expectSlugs(t, posts, []string{"duplicate-post"})
+}
+
+func testGetBlogPostById(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("id-post", 1000))
+
+	post := mustGetBlogPostById(t, dbc, id)
+	expectString(t, "slug", post.Slug, "id-post")
+
+	_, unknownErr := dbc.GetBlogPostById(UNKNOWN_ID, true)
+	expectNoResultsError(t, unknownErr)
+
+	_, invalidErr := dbc.GetBlogPostById(INVALID_ID, true)
+	expectInvalidInputError(t, invalidErr)
+}
+
+func testGetBlogPostBySlug(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("slug-post", 1000))
+
+	post, err := dbc.GetBlogPostBySlug("slug-post", true)
+
+	if err != nil {
+		t.Fatalf("GetBlogPostBySlug: %v", err)
+	}
+
+	expectString(t, "id", post.Id, id)
+
+	_, missingErr := dbc.GetBlogPostBySlug("missing-post", true)
+	expectNoResultsError(t, missingErr)
+}
+
+func testGetBlogPostsPaging(t *testing.T, dbc dbController.DatabaseController) {
+	// Added out of order so that the sort is exercised
+	mustAddBlogPost(t, dbc, makeAddDocument("post-3", 3000))
+	mustAddBlogPost(t, dbc, makeAddDocument("post-1", 1000))
+	mustAddBlogPost(t, dbc, makeAddDocument("post-5", 5000))
+	mustAddBlogPost(t, dbc, makeAddDocument("post-2", 2000))
+	mustAddBlogPost(t, dbc, makeAddDocument("post-4", 4000))
+
+	pages := [][]string{
+		{"post-5", "post-4"},
+		{"post-3", "post-2"},
+		{"post-1"},
+		{},
+	}
+
+	for i, want := range pages {
+		posts, err := dbc.GetBlogPosts(i+1, 2, false)
+
+		if err != nil {
+			t.Fatalf("GetBlogPosts(%d, 2): %v", i+1, err)
+		}
+
+		if posts == nil {
+			t.Errorf("GetBlogPosts(%d, 2) returned nil instead of an empty slice", i+1)
+		}
+
+		expectSlugs(t, posts, want)
+	}
+
+	all, allErr := dbc.GetBlogPosts(1, 10, false)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogPosts(1, 10): %v", allErr)
+	}
+
+	expectSlugs(t, all, []string{"post-5", "post-4", "post-3", "post-2", "post-1"})
+}
+
+// addCursorPosts adds the posts used by the cursor tests and returns them by
+// slug. cursor-c1 and cursor-c2 are added at the same time.
+func addCursorPosts(t *testing.T, dbc dbController.DatabaseController) map[string]*dbController.BlogDocument {
+	mustAddBlogPost(t, dbc, makeAddDocument("cursor-a", 1000))
+	mustAddBlogPost(t, dbc, makeAddDocument("cursor-b", 2000))
+	mustAddBlogPost(t, dbc, makeAddDocument("cursor-c1", 3000))
+	mustAddBlogPost(t, dbc, makeAddDocument("cursor-c2", 3000))
+	mustAddBlogPost(t, dbc, makeAddDocument("cursor-d", 4000))
+
+	draft := makeAddDocument("cursor-draft", 5000)
+	draft.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	mustAddBlogPost(t, dbc, draft)
+
+	trashedId := mustAddBlogPost(t, dbc, makeAddDocument("cursor-trashed", 6000))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	all, allErr := dbc.GetBlogPosts(1, 10, true)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogPosts(1, 10, true): %v", allErr)
+	}
+
+	posts := make(map[string]*dbController.BlogDocument)
+	for _, post := range all {
+		posts[post.Slug] = post
+	}
+
+	return posts
+}
+
+func testCountBlogPosts(t *testing.T, dbc dbController.DatabaseController) {
+	empty, emptyErr := dbc.CountBlogPosts(nil, true)
+
+	if emptyErr != nil {
+		t.Fatalf("CountBlogPosts(nil, true): %v", emptyErr)
+	}
+
+	if empty != 0 {
+		t.Errorf("CountBlogPosts(nil, true) with no posts: got %d, want 0", empty)
+	}
+
+	addCursorPosts(t, dbc)
+
+	public, publicErr := dbc.CountBlogPosts(nil, false)
+
+	if publicErr != nil {
+		t.Fatalf("CountBlogPosts(nil, false): %v", publicErr)
+	}
+
+	if public != 5 {
+		t.Errorf("CountBlogPosts(nil, false): got %d, want 5", public)
+	}
+
+	all, allErr := dbc.CountBlogPosts(nil, true)
+
+	if allErr != nil {
+		t.Fatalf("CountBlogPosts(nil, true): %v", allErr)
+	}
+
+	if all != 6 {
+		t.Errorf("CountBlogPosts(nil, true): got %d, want 6", all)
+	}
+}
+
+func testGetBlogPostsByCursor(t *testing.T, dbc dbController.DatabaseController) {
+	posts := addCursorPosts(t, dbc)
+
+	at := func(slug string, previous bool) *dbController.BlogCursor {
+		post, ok := posts[slug]
+
+		if !ok {
+			t.Fatalf("missing post %q", slug)
+		}
+
+		return &dbController.BlogCursor{DateAdded: post.DateAdded, Id: post.Id, Previous: previous}
+	}
+
+	cases := []struct {
+		name               string
+		cursor             *dbController.BlogCursor
+		limit              int
+		includeUnpublished bool
+		want               []string
+	}{
+		{"first page", nil, 2, false, []string{"cursor-d", "cursor-c1"}},
+		{"first page unpublished", nil, 2, true, []string{"cursor-draft", "cursor-d"}},
+		{"after c1", at("cursor-c1", false), 2, false, []string{"cursor-c2", "cursor-b"}},
+		{"after b", at("cursor-b", false), 2, false, []string{"cursor-a"}},
+		{"after a", at("cursor-a", false), 2, false, []string{}},
+		{"before c2", at("cursor-c2", true), 2, false, []string{"cursor-d", "cursor-c1"}},
+		{"before b", at("cursor-b", true), 2, false, []string{"cursor-c1", "cursor-c2"}},
+		{"before c1", at("cursor-c1", true), 5, false, []string{"cursor-d"}},
+		{"before c1 unpublished", at("cursor-c1", true), 5, true, []string{"cursor-draft", "cursor-d"}},
+		{"before d", at("cursor-d", true), 2, false, []string{}},
+	}
+
+	for _, c := range cases {
+		got, err := dbc.GetBlogPostsByCursor(c.cursor, c.limit, c.includeUnpublished)
+
+		if err != nil {
+			t.Fatalf("GetBlogPostsByCursor(%s): %v", c.name, err)
+		}
+
+		expectSlugs(t, got, c.want)
+	}
+
+	_, invalidErr := dbc.GetBlogPostsByCursor(&dbController.BlogCursor{DateAdded: time.Unix(1000, 0), Id: INVALID_ID}, 2, true)
+	expectInvalidInputError(t, invalidErr)
+
+	if _, err := dbc.GetBlogPostsByCursor(nil, 0, true); err == nil {
+		t.Errorf("GetBlogPostsByCursor with limit 0: expected an error")
+	}
+}
+
+func testPublicVisibility(t *testing.T, dbc dbController.DatabaseController) {
+	now := time.Now().Unix()
+
+	addWithStatus := func(slug string, status string, publishAt int64) string {
+		doc := makeAddDocument(slug, 1000)
+		doc.Status = stringPtr(status)
+		doc.PublishAt = timePtr(time.Unix(publishAt, 0))
+
+		return mustAddBlogPost(t, dbc, doc)
+	}
+
+	publicIds := []string{
+		addWithStatus("published-post", dbController.BLOG_STATUS_PUBLISHED, now-60),
+		addWithStatus("scheduled-past-post", dbController.BLOG_STATUS_SCHEDULED, now-60),
+	}
+
+	hiddenIds := []string{
+		addWithStatus("draft-post", dbController.BLOG_STATUS_DRAFT, now-60),
+		addWithStatus("archived-post", dbController.BLOG_STATUS_ARCHIVED, now-60),
+		addWithStatus("scheduled-future-post", dbController.BLOG_STATUS_SCHEDULED, now+3600),
+		addWithStatus("published-future-post", dbController.BLOG_STATUS_PUBLISHED, now+3600),
+	}
+
+	for _, id := range publicIds {
+		if _, err := dbc.GetBlogPostById(id, false); err != nil {
+			t.Errorf("GetBlogPostById(%q, false): %v", id, err)
+		}
+	}
+
+	for _, id := range hiddenIds {
+		_, hiddenErr := dbc.GetBlogPostById(id, false)
+		expectNoResultsError(t, hiddenErr)
+
+		post := mustGetBlogPostById(t, dbc, id)
+
+		_, slugErr := dbc.GetBlogPostBySlug(post.Slug, false)
+		expectNoResultsError(t, slugErr)
+
+		if _, err := dbc.GetBlogPostBySlug(post.Slug, true); err != nil {
+			t.Errorf("GetBlogPostBySlug(%q, true): %v", post.Slug, err)
+		}
+	}
+
+	public, publicErr := dbc.GetBlogPosts(1, 10, false)
+
+	if publicErr != nil {
+		t.Fatalf("GetBlogPosts(1, 10, false): %v", publicErr)
+	}
+
+	expectSlugs(t, public, []string{"published-post", "scheduled-past-post"})
+
+	all, allErr := dbc.GetBlogPosts(1, 10, true)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogPosts(1, 10, true): %v", allErr)
+	}
+
+	if len(all) != len(publicIds)+len(hiddenIds) {
+		t.Errorf("GetBlogPosts(1, 10, true): got %d posts, want %d", len(all), len(publicIds)+len(hiddenIds))
+	}
+}
+
+func testEditBlogPostStatus(t *testing.T, dbc dbController.DatabaseController) {
+	doc := makeAddDocument("status-post", 1000)
+	doc.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	id := mustAddBlogPost(t, dbc, doc)
+
+	post := mustGetBlogPostById(t, dbc, id)
+	expectString(t, "status", post.Status, dbController.BLOG_STATUS_DRAFT)
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:        id,
+		Status:    stringPtr(dbController.BLOG_STATUS_PUBLISHED),
+		PublishAt: timePtr(time.Unix(1500, 0)),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post, getErr := dbc.GetBlogPostById(id, false)
+
+	if getErr != nil {
+		t.Fatalf("GetBlogPostById(%q, false): %v", id, getErr)
+	}
+
+	expectString(t, "status", post.Status, dbController.BLOG_STATUS_PUBLISHED)
+	expectTime(t, "publishAt", post.PublishAt, 1500)
+	expectTime(t, "dateAdded", post.DateAdded, 1000)
+}
+
+func testEditBlogPostPartialUpdate(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+	mustAddUser(t, dbc, "editor-uid", "Editor Name", "editor@example.com")
+
+	doc := makeAddDocument("partial-post", 1000)
+	id := mustAddBlogPost(t, dbc, doc)
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:    id,
+		Title: stringPtr("New Title"),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post := mustGetBlogPostById(t, dbc, id)
+
+	expectString(t, "title", post.Title, "New Title")
+	expectString(t, "slug", post.Slug, doc.Slug)
+	expectString(t, "body", post.Body, doc.Body)
+	expectTags(t, post.Tags, *doc.Tags)
+	expectString(t, "authorId", post.AuthorId, doc.AuthorId)
+	expectString(t, "updateAuthorId", post.UpdateAuthorId, doc.AuthorId)
+	expectTime(t, "dateAdded", post.DateAdded, 1000)
+	expectTime(t, "dateUpdated", post.DateUpdated, 1000)
+
+	newTags := []string{"tag3"}
+	editErr = dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:             id,
+		Body:           stringPtr("New Body"),
+		Tags:           &newTags,
+		UpdateAuthorId: stringPtr("editor-uid"),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post = mustGetBlogPostById(t, dbc, id)
+
+	expectString(t, "title", post.Title, "New Title")
+	expectString(t, "body", post.Body, "New Body")
+	expectTags(t, post.Tags, newTags)
+	expectString(t, "author", post.Author, "Author Name")
+	expectString(t, "updateAuthorId", post.UpdateAuthorId, "editor-uid")
+	expectString(t, "updateAuthor", post.UpdateAuthor, "Editor Name")
+}
+
+// testBodyHtml checks that the rendered HTML is stored as given, and that an
+// edit that doesn't set it keeps the HTML the post had.
+func testBodyHtml(t *testing.T, dbc dbController.DatabaseController) {
+	doc := makeAddDocument("html-post", 1000)
+	doc.BodyHtml = "<p>Body html-post</p>\n"
+	id := mustAddBlogPost(t, dbc, doc)
+
+	post := mustGetBlogPostById(t, dbc, id)
+	expectString(t, "bodyHtml", post.BodyHtml, doc.BodyHtml)
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:    id,
+		Title: stringPtr("New Title"),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post = mustGetBlogPostById(t, dbc, id)
+	expectString(t, "bodyHtml", post.BodyHtml, doc.BodyHtml)
+
+	editErr = dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:       id,
+		Body:     stringPtr("New *Body*"),
+		BodyHtml: stringPtr("<p>New <em>Body</em></p>\n"),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post = mustGetBlogPostById(t, dbc, id)
+	expectString(t, "body", post.Body, "New *Body*")
+	expectString(t, "bodyHtml", post.BodyHtml, "<p>New <em>Body</em></p>\n")
+
+	posts, getErr := dbc.GetBlogPosts(1, 10, true)
+
+	if getErr != nil {
+		t.Fatalf("GetBlogPosts: %v", getErr)
+	}
+
+	if len(posts) != 1 {
+		t.Fatalf("GetBlogPosts: got %d posts, want 1", len(posts))
+	}
+
+	expectString(t, "bodyHtml", posts[0].BodyHtml, "<p>New <em>Body</em></p>\n")
+}
+
+func expectSummary(t *testing.T, got dbController.BlogSummary, want dbController.BlogSummary) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("summary: got %+v, want %+v", got, want)
+	}
+}
+
+// testBlogSummary checks that the summary is stored as given, and that an
+// edit that doesn't set it keeps the summary the post had.
+func testBlogSummary(t *testing.T, dbc dbController.DatabaseController) {
+	summary := dbController.BlogSummary{
+		Excerpt:            "Body summary-post",
+		WordCount:          2,
+		ReadingTimeMinutes: 1,
+	}
+
+	doc := makeAddDocument("summary-post", 1000)
+	doc.Summary = summary
+	id := mustAddBlogPost(t, dbc, doc)
+
+	post := mustGetBlogPostById(t, dbc, id)
+	expectSummary(t, post.Summary, summary)
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:    id,
+		Title: stringPtr("New Title"),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post = mustGetBlogPostById(t, dbc, id)
+	expectSummary(t, post.Summary, summary)
+
+	newSummary := dbController.BlogSummary{
+		Excerpt:            "A custom excerpt",
+		CustomExcerpt:      true,
+		WordCount:          450,
+		ReadingTimeMinutes: 3,
+	}
+
+	editErr = dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:      id,
+		Summary: &newSummary,
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	post = mustGetBlogPostById(t, dbc, id)
+	expectSummary(t, post.Summary, newSummary)
+
+	posts, getErr := dbc.GetBlogPosts(1, 10, true)
+
+	if getErr != nil {
+		t.Fatalf("GetBlogPosts: %v", getErr)
+	}
+
+	if len(posts) != 1 {
+		t.Fatalf("GetBlogPosts: got %d posts, want 1", len(posts))
+	}
+
+	expectSummary(t, posts[0].Summary, newSummary)
+}
+
+func testEditBlogPostDates(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("dates-post", 1000))
+
+	// Only dateUpdated is set, so dateAdded must stay the same
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:          id,
+		DateUpdated: timePtr(time.Unix(3000, 0)),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost(dateUpdated): %v", editErr)
+	}
+
+	post := mustGetBlogPostById(t, dbc, id)
+	expectTime(t, "dateAdded", post.DateAdded, 1000)
+	expectTime(t, "dateUpdated", post.DateUpdated, 3000)
+
+	// Only dateAdded is set, so dateUpdated must stay the same
+	editErr = dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:        id,
+		DateAdded: timePtr(time.Unix(2000, 0)),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost(dateAdded): %v", editErr)
+	}
+
+	post = mustGetBlogPostById(t, dbc, id)
+	expectTime(t, "dateAdded", post.DateAdded, 2000)
+	expectTime(t, "dateUpdated", post.DateUpdated, 3000)
+}
+
+func testEditBlogPostSlug(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddBlogPost(t, dbc, makeAddDocument("first-post", 1000))
+	id := mustAddBlogPost(t, dbc, makeAddDocument("second-post", 2000))
+
+	duplicateErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:   id,
+		Slug: stringPtr("first-post"),
+	})
+	expectDuplicateEntryError(t, duplicateErr)
+
+	// Setting a post's slug to its current value isn't a duplicate
+	sameErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:   id,
+		Slug: stringPtr("second-post"),
+	})
+
+	if sameErr != nil {
+		t.Errorf("EditBlogPost with unchanged slug: %v", sameErr)
+	}
+
+	renameErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:   id,
+		Slug: stringPtr("renamed-post"),
+	})
+
+	if renameErr != nil {
+		t.Fatalf("EditBlogPost with new slug: %v", renameErr)
+	}
+
+	post, getErr := dbc.GetBlogPostBySlug("renamed-post", true)
+
+	if getErr != nil {
+		t.Fatalf("GetBlogPostBySlug: %v", getErr)
+	}
+
+	expectString(t, "id", post.Id, id)
+
+	_, oldErr := dbc.GetBlogPostBySlug("second-post", true)
+	expectNoResultsError(t, oldErr)
+}
+
+func mustEditSlug(t *testing.T, dbc dbController.DatabaseController, id string, slug string) {
+	t.Helper()
+
+	if err := dbc.EditBlogPost(&dbController.EditBlogDocument{Id: id, Slug: stringPtr(slug)}); err != nil {
+		t.Fatalf("EditBlogPost(slug %q): %v", slug, err)
+	}
+}
+
+func expectOldSlug(t *testing.T, dbc dbController.DatabaseController, oldSlug string, wantId string) {
+	t.Helper()
+
+	post, err := dbc.GetBlogPostByOldSlug(oldSlug, true)
+
+	if err != nil {
+		t.Errorf("GetBlogPostByOldSlug(%q): %v", oldSlug, err)
+		return
+	}
+
+	expectString(t, "id", post.Id, wantId)
+}
+
+func testOldSlugs(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("first-slug", 1000))
+	otherId := mustAddBlogPost(t, dbc, makeAddDocument("other-post", 2000))
+
+	_, unknownErr := dbc.GetBlogPostByOldSlug("first-slug", true)
+	expectNoResultsError(t, unknownErr)
+
+	mustEditSlug(t, dbc, id, "second-slug")
+	mustEditSlug(t, dbc, id, "third-slug")
+
+	expectOldSlug(t, dbc, "first-slug", id)
+	expectOldSlug(t, dbc, "second-slug", id)
+
+	post, _ := dbc.GetBlogPostByOldSlug("first-slug", true)
+	if post != nil {
+		expectString(t, "slug", post.Slug, "third-slug")
+	}
+
+	// Old slugs can't be given to another post
+	_, addErr := dbc.AddBlogPost(makeAddDocument("first-slug", 3000))
+	expectDuplicateEntryError(t, addErr)
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{Id: otherId, Slug: stringPtr("second-slug")})
+	expectDuplicateEntryError(t, editErr)
+
+	// A post can go back to one of its own old slugs
+	mustEditSlug(t, dbc, id, "first-slug")
+
+	_, currentErr := dbc.GetBlogPostByOldSlug("first-slug", true)
+	expectNoResultsError(t, currentErr)
+
+	expectOldSlug(t, dbc, "second-slug", id)
+	expectOldSlug(t, dbc, "third-slug", id)
+
+	// Posts in the trash keep their old slugs, but aren't returned
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	_, trashedErr := dbc.GetBlogPostByOldSlug("second-slug", true)
+	expectNoResultsError(t, trashedErr)
+
+	_, addErr = dbc.AddBlogPost(makeAddDocument("second-slug", 3000))
+	expectDuplicateEntryError(t, addErr)
+
+	if err := dbc.PurgeBlogPost(id); err != nil {
+		t.Fatalf("PurgeBlogPost: %v", err)
+	}
+
+	mustAddBlogPost(t, dbc, makeAddDocument("second-slug", 3000))
+}
+
+func expectSlugAvailable(t *testing.T, dbc dbController.DatabaseController, slug string, id string, want bool) {
+	t.Helper()
+
+	available, err := dbc.IsSlugAvailable(slug, id)
+	if err != nil {
+		t.Fatalf("IsSlugAvailable(%q, %q): %v", slug, id, err)
+	}
+	if available != want {
+		t.Errorf("IsSlugAvailable(%q, %q) = %v, want %v", slug, id, available, want)
+	}
+}
+
+func testIsSlugAvailable(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("first-slug", 1000))
+	otherId := mustAddBlogPost(t, dbc, makeAddDocument("other-post", 2000))
+	trashedId := mustAddBlogPost(t, dbc, makeAddDocument("trashed-post", 3000))
+
+	mustEditSlug(t, dbc, id, "second-slug")
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	expectSlugAvailable(t, dbc, "unused-slug", "", true)
+	expectSlugAvailable(t, dbc, "unused-slug", id, true)
+
+	// Current and old slugs belong to their post
+	expectSlugAvailable(t, dbc, "second-slug", "", false)
+	expectSlugAvailable(t, dbc, "second-slug", id, true)
+	expectSlugAvailable(t, dbc, "second-slug", otherId, false)
+	expectSlugAvailable(t, dbc, "first-slug", "", false)
+	expectSlugAvailable(t, dbc, "first-slug", id, true)
+	expectSlugAvailable(t, dbc, "first-slug", otherId, false)
+
+	expectSlugAvailable(t, dbc, "trashed-post", "", false)
+
+	_, invalidErr := dbc.IsSlugAvailable("unused-slug", INVALID_ID)
+	expectInvalidInputError(t, invalidErr)
+}
+
+func testEditBlogPostMissing(t *testing.T, dbc dbController.DatabaseController) {
+	unknownErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:    UNKNOWN_ID,
+		Title: stringPtr("Title"),
+	})
+	expectInvalidInputError(t, unknownErr)
+
+	invalidErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:    INVALID_ID,
+		Title: stringPtr("Title"),
+	})
+	expectInvalidInputError(t, invalidErr)
+}
+
+func testDeleteBlogPost(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("delete-post", 1000))
+	keepId := mustAddBlogPost(t, dbc, makeAddDocument("keep-post", 2000))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id, DeletedBy: "editor-uid"}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	_, getErr := dbc.GetBlogPostById(id, true)
+	expectNoResultsError(t, getErr)
+
+	_, slugErr := dbc.GetBlogPostBySlug("delete-post", true)
+	expectNoResultsError(t, slugErr)
+
+	all, allErr := dbc.GetBlogPosts(1, 10, true)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogPosts(1, 10, true): %v", allErr)
+	}
+
+	expectSlugs(t, all, []string{"keep-post"})
+
+	againErr := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id})
+	expectInvalidInputError(t, againErr)
+
+	invalidErr := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: INVALID_ID})
+	expectInvalidInputError(t, invalidErr)
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{Id: id, Title: stringPtr("Trashed")})
+	expectInvalidInputError(t, editErr)
+
+	// Posts in the trash keep their slug
+	_, duplicateErr := dbc.AddBlogPost(makeAddDocument("delete-post", 3000))
+	expectDuplicateEntryError(t, duplicateErr)
+
+	mustGetBlogPostById(t, dbc, keepId)
+}
+
+func mustGetTrash(t *testing.T, dbc dbController.DatabaseController) []*dbController.BlogDocument {
+	t.Helper()
+
+	posts, err := dbc.GetTrashedBlogPosts(1, 10)
+
+	if err != nil {
+		t.Fatalf("GetTrashedBlogPosts(1, 10): %v", err)
+	}
+
+	return posts
+}
+
+func testTrash(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("trash-post", 1000))
+	mustAddBlogPost(t, dbc, makeAddDocument("keep-post", 2000))
+
+	before := time.Now().Unix()
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id, DeletedBy: "editor-uid"}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	trash := mustGetTrash(t, dbc)
+	expectSlugs(t, trash, []string{"trash-post"})
+
+	trashCount, trashCountErr := dbc.CountTrashedBlogPosts()
+	expectCount(t, "CountTrashedBlogPosts()", trashCount, trashCountErr, 1)
+
+	trashed := trash[0]
+	expectString(t, "deletedBy", trashed.DeletedBy, "editor-uid")
+
+	if trashed.DeletedAt == nil {
+		t.Fatalf("deletedAt: got nil, want a time")
+	}
+
+	if trashed.DeletedAt.Unix() < before || trashed.DeletedAt.Unix() > time.Now().Unix() {
+		t.Errorf("deletedAt: got %d, want the time of the delete", trashed.DeletedAt.Unix())
+	}
+
+	if trashed.IsPublic(time.Now()) {
+		t.Errorf("IsPublic: got true for a post in the trash")
+	}
+
+	if err := dbc.RestoreBlogPost(id); err != nil {
+		t.Fatalf("RestoreBlogPost: %v", err)
+	}
+
+	restored := mustGetBlogPostById(t, dbc, id)
+
+	if restored.DeletedAt != nil {
+		t.Errorf("deletedAt: got %v, want nil", restored.DeletedAt)
+	}
+
+	expectSlugs(t, mustGetTrash(t, dbc), []string{})
+
+	emptyCount, emptyCountErr := dbc.CountTrashedBlogPosts()
+	expectCount(t, "CountTrashedBlogPosts()", emptyCount, emptyCountErr, 0)
+
+	againErr := dbc.RestoreBlogPost(id)
+	expectInvalidInputError(t, againErr)
+
+	// Only posts in the trash can be purged
+	notTrashedErr := dbc.PurgeBlogPost(id)
+	expectInvalidInputError(t, notTrashedErr)
+
+	invalidErr := dbc.PurgeBlogPost(INVALID_ID)
+	expectInvalidInputError(t, invalidErr)
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	if err := dbc.PurgeBlogPost(id); err != nil {
+		t.Fatalf("PurgeBlogPost: %v", err)
+	}
+
+	expectSlugs(t, mustGetTrash(t, dbc), []string{})
+	expectRevisionNumbers(t, mustGetRevisions(t, dbc, id), []int{})
+
+	// The slug is free to use again once the post is purged
+	mustAddBlogPost(t, dbc, makeAddDocument("trash-post", 3000))
+}
+
+func testPurgeTrashedBlogPosts(t *testing.T, dbc dbController.DatabaseController) {
+	firstId := mustAddBlogPost(t, dbc, makeAddDocument("first-post", 1000))
+	secondId := mustAddBlogPost(t, dbc, makeAddDocument("second-post", 2000))
+	mustAddBlogPost(t, dbc, makeAddDocument("keep-post", 3000))
+
+	for _, id := range []string{firstId, secondId} {
+		if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id}); err != nil {
+			t.Fatalf("DeleteBlogPost: %v", err)
+		}
+	}
+
+	none, noneErr := dbc.PurgeTrashedBlogPosts(time.Now().Add(-time.Hour))
+
+	if noneErr != nil {
+		t.Fatalf("PurgeTrashedBlogPosts: %v", noneErr)
+	}
+
+	if none != 0 {
+		t.Errorf("PurgeTrashedBlogPosts: got %d purged, want 0", none)
+	}
+
+	purged, purgedErr := dbc.PurgeTrashedBlogPosts(time.Now().Add(time.Hour))
+
+	if purgedErr != nil {
+		t.Fatalf("PurgeTrashedBlogPosts: %v", purgedErr)
+	}
+
+	if purged != 2 {
+		t.Errorf("PurgeTrashedBlogPosts: got %d purged, want 2", purged)
+	}
+
+	expectSlugs(t, mustGetTrash(t, dbc), []string{})
+	expectRevisionNumbers(t, mustGetRevisions(t, dbc, firstId), []int{})
+
+	all, allErr := dbc.GetBlogPosts(1, 10, true)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogPosts(1, 10, true): %v", allErr)
+	}
+
+	expectSlugs(t, all, []string{"keep-post"})
+}
+
+// addTaggedPosts adds the posts used by the tag tests. Only tag-a and tag-b
+// are public.
+func addTaggedPosts(t *testing.T, dbc dbController.DatabaseController) {
+	addTagged := func(slug string, dateAdded int64, tags ...string) *dbController.AddBlogDocument {
+		doc := makeAddDocument(slug, dateAdded)
+		doc.Tags = &tags
+
+		return doc
+	}
+
+	mustAddBlogPost(t, dbc, addTagged("tag-a", 1000, "go", "web"))
+	mustAddBlogPost(t, dbc, addTagged("tag-b", 2000, "go"))
+	mustAddBlogPost(t, dbc, addTagged("tag-c", 3000, "rust", "rust"))
+
+	draft := addTagged("tag-draft", 4000, "go", "drafts")
+	draft.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	mustAddBlogPost(t, dbc, draft)
+
+	trashedId := mustAddBlogPost(t, dbc, addTagged("tag-trashed", 5000, "go", "trashed"))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+}
+
+func expectTagCounts(t *testing.T, got []*dbController.TagCount, want []dbController.TagCount) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("tag counts: got %d tags, want %v", len(got), want)
+		return
+	}
+
+	for i := range got {
+		if *got[i] != want[i] {
+			t.Errorf("tag count %d: got %v, want %v", i, *got[i], want[i])
+		}
+	}
+}
+
+func testGetBlogTags(t *testing.T, dbc dbController.DatabaseController) {
+	empty, emptyErr := dbc.GetBlogTags(true)
+
+	if emptyErr != nil {
+		t.Fatalf("GetBlogTags(true): %v", emptyErr)
+	}
+
+	expectTagCounts(t, empty, []dbController.TagCount{})
+
+	addTaggedPosts(t, dbc)
+
+	public, publicErr := dbc.GetBlogTags(false)
+
+	if publicErr != nil {
+		t.Fatalf("GetBlogTags(false): %v", publicErr)
+	}
+
+	// A tag used twice on the same post is only counted once
+	expectTagCounts(t, public, []dbController.TagCount{
+		{Tag: "go", Count: 2},
+		{Tag: "rust", Count: 1},
+		{Tag: "web", Count: 1},
+	})
+
+	all, allErr := dbc.GetBlogTags(true)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogTags(true): %v", allErr)
+	}
+
+	expectTagCounts(t, all, []dbController.TagCount{
+		{Tag: "go", Count: 3},
+		{Tag: "drafts", Count: 1},
+		{Tag: "rust", Count: 1},
+		{Tag: "web", Count: 1},
+	})
+}
+
+func testGetBlogPostsByTag(t *testing.T, dbc dbController.DatabaseController) {
+	addTaggedPosts(t, dbc)
+
+	cases := []struct {
+		tag                string
+		page               int
+		pagination         int
+		includeUnpublished bool
+		want               []string
+	}{
+		{"go", 1, 10, false, []string{"tag-b", "tag-a"}},
+		{"go", 1, 10, true, []string{"tag-draft", "tag-b", "tag-a"}},
+		{"go", 2, 1, false, []string{"tag-a"}},
+		{"go", 3, 1, false, []string{}},
+		{"rust", 1, 10, false, []string{"tag-c"}},
+		{"trashed", 1, 10, true, []string{}},
+		{"Go", 1, 10, true, []string{}},
+		{"missing", 1, 10, true, []string{}},
+	}
+
+	for _, c := range cases {
+		posts, err := dbc.GetBlogPostsByTag(c.tag, c.page, c.pagination, c.includeUnpublished)
+
+		if err != nil {
+			t.Fatalf("GetBlogPostsByTag(%q, %d, %d, %v): %v", c.tag, c.page, c.pagination, c.includeUnpublished, err)
+		}
+
+		expectSlugs(t, posts, c.want)
+	}
+
+	if _, err := dbc.GetBlogPostsByTag("go", 0, 10, true); err == nil {
+		t.Errorf("GetBlogPostsByTag with page 0: expected an error")
+	}
+
+	goFilter := &dbController.BlogPostFilter{Tag: "go"}
+
+	public, publicErr := dbc.CountBlogPosts(goFilter, false)
+	expectCount(t, "CountBlogPosts(go, false)", public, publicErr, 2)
+
+	all, allErr := dbc.CountBlogPosts(goFilter, true)
+	expectCount(t, "CountBlogPosts(go, true)", all, allErr, 3)
+
+	missing, missingErr := dbc.CountBlogPosts(&dbController.BlogPostFilter{Tag: "missing"}, true)
+	expectCount(t, "CountBlogPosts(missing, true)", missing, missingErr, 0)
+}
+
+func testGetBlogAuthor(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+
+	author, err := dbc.GetBlogAuthor("author-uid")
+
+	if err != nil {
+		t.Fatalf("GetBlogAuthor: %v", err)
+	}
+
+	expectString(t, "uid", author.UID, "author-uid")
+	expectString(t, "name", author.Name, "Author Name")
+
+	_, missingErr := dbc.GetBlogAuthor("missing-uid")
+	expectNoResultsError(t, missingErr)
+}
+
+func testGetBlogPostsByAuthor(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+	mustAddUser(t, dbc, "other-uid", "Other Name", "other@example.com")
+
+	addByAuthor := func(slug string, authorId string, dateAdded int64) *dbController.AddBlogDocument {
+		doc := makeAddDocument(slug, dateAdded)
+		doc.AuthorId = authorId
+
+		return doc
+	}
+
+	mustAddBlogPost(t, dbc, addByAuthor("author-1", "author-uid", 1000))
+	mustAddBlogPost(t, dbc, addByAuthor("other-1", "other-uid", 2000))
+	mustAddBlogPost(t, dbc, addByAuthor("author-2", "author-uid", 3000))
+
+	draft := addByAuthor("author-draft", "author-uid", 4000)
+	draft.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	mustAddBlogPost(t, dbc, draft)
+
+	trashedId := mustAddBlogPost(t, dbc, addByAuthor("author-trashed", "author-uid", 5000))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	cases := []struct {
+		uid                string
+		page               int
+		pagination         int
+		includeUnpublished bool
+		want               []string
+	}{
+		{"author-uid", 1, 10, false, []string{"author-2", "author-1"}},
+		{"author-uid", 1, 10, true, []string{"author-draft", "author-2", "author-1"}},
+		{"author-uid", 2, 1, false, []string{"author-1"}},
+		{"other-uid", 1, 10, false, []string{"other-1"}},
+		{"missing-uid", 1, 10, true, []string{}},
+	}
+
+	for _, c := range cases {
+		posts, err := dbc.GetBlogPostsByAuthor(c.uid, c.page, c.pagination, c.includeUnpublished)
+
+		if err != nil {
+			t.Fatalf("GetBlogPostsByAuthor(%q, %d, %d, %v): %v", c.uid, c.page, c.pagination, c.includeUnpublished, err)
+		}
+
+		expectSlugs(t, posts, c.want)
+
+		for _, post := range posts {
+			expectString(t, "authorId", post.AuthorId, c.uid)
+		}
+	}
+
+	if _, err := dbc.GetBlogPostsByAuthor("author-uid", 1, 0, true); err == nil {
+		t.Errorf("GetBlogPostsByAuthor with pagination 0: expected an error")
+	}
+
+	authorFilter := &dbController.BlogPostFilter{AuthorId: "author-uid"}
+
+	public, publicErr := dbc.CountBlogPosts(authorFilter, false)
+	expectCount(t, "CountBlogPosts(author-uid, false)", public, publicErr, 2)
+
+	all, allErr := dbc.CountBlogPosts(authorFilter, true)
+	expectCount(t, "CountBlogPosts(author-uid, true)", all, allErr, 3)
+}
+
+func testGetBlogAuthors(t *testing.T, dbc dbController.DatabaseController) {
+	empty, emptyErr := dbc.GetBlogAuthors(true)
+
+	if emptyErr != nil {
+		t.Fatalf("GetBlogAuthors(true): %v", emptyErr)
+	}
+
+	if len(empty) != 0 {
+		t.Errorf("GetBlogAuthors(true) with no posts: got %d authors, want 0", len(empty))
+	}
+
+	mustAddUser(t, dbc, "b-uid", "B Name", "b@example.com")
+	mustAddUser(t, dbc, "a-uid", "A Name", "a@example.com")
+	mustAddUser(t, dbc, "draft-uid", "Draft Name", "draft@example.com")
+	mustAddUser(t, dbc, "trashed-uid", "Trashed Name", "trashed@example.com")
+	mustAddUser(t, dbc, "idle-uid", "Idle Name", "idle@example.com")
+
+	addByAuthor := func(slug string, authorId string, dateAdded int64) *dbController.AddBlogDocument {
+		doc := makeAddDocument(slug, dateAdded)
+		doc.AuthorId = authorId
+
+		return doc
+	}
+
+	mustAddBlogPost(t, dbc, addByAuthor("b-1", "b-uid", 1000))
+	mustAddBlogPost(t, dbc, addByAuthor("b-2", "b-uid", 2000))
+	mustAddBlogPost(t, dbc, addByAuthor("a-1", "a-uid", 3000))
+
+	// Posts by users that don't exist don't have an author to list
+	mustAddBlogPost(t, dbc, addByAuthor("missing-1", "missing-uid", 4000))
+
+	draft := addByAuthor("draft-1", "draft-uid", 5000)
+	draft.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	mustAddBlogPost(t, dbc, draft)
+
+	trashedId := mustAddBlogPost(t, dbc, addByAuthor("trashed-1", "trashed-uid", 6000))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	cases := []struct {
+		includeUnpublished bool
+		want               []dbController.BlogAuthor
+	}{
+		{false, []dbController.BlogAuthor{{UID: "a-uid", Name: "A Name"}, {UID: "b-uid", Name: "B Name"}}},
+		{true, []dbController.BlogAuthor{{UID: "a-uid", Name: "A Name"}, {UID: "b-uid", Name: "B Name"}, {UID: "draft-uid", Name: "Draft Name"}}},
+	}
+
+	for _, c := range cases {
+		authors, err := dbc.GetBlogAuthors(c.includeUnpublished)
+
+		if err != nil {
+			t.Fatalf("GetBlogAuthors(%v): %v", c.includeUnpublished, err)
+		}
+
+		if len(authors) != len(c.want) {
+			t.Errorf("GetBlogAuthors(%v): got %d authors, want %v", c.includeUnpublished, len(authors), c.want)
+			continue
+		}
+
+		for i := range authors {
+			if *authors[i] != c.want[i] {
+				t.Errorf("GetBlogAuthors(%v) author %d: got %v, want %v", c.includeUnpublished, i, *authors[i], c.want[i])
+			}
+		}
+	}
+}
+
+func utcUnix(year int, month time.Month, day int, hour int, min int, sec int) int64 {
+	return time.Date(year, month, day, hour, min, sec, 0, time.UTC).Unix()
+}
+
+// addArchivePosts adds the posts used by the archive tests. The draft and the
+// trashed posts are the only ones that aren't public.
+func addArchivePosts(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddBlogPost(t, dbc, makeAddDocument("jan-a", utcUnix(2021, time.January, 15, 12, 0, 0)))
+	mustAddBlogPost(t, dbc, makeAddDocument("jan-b", utcUnix(2021, time.January, 20, 12, 0, 0)))
+	mustAddBlogPost(t, dbc, makeAddDocument("mar", utcUnix(2021, time.March, 1, 0, 0, 0)))
+	mustAddBlogPost(t, dbc, makeAddDocument("dec-end", utcUnix(2022, time.December, 31, 23, 59, 59)))
+	mustAddBlogPost(t, dbc, makeAddDocument("jan-start", utcUnix(2023, time.January, 1, 0, 0, 0)))
+
+	draft := makeAddDocument("jan-draft", utcUnix(2023, time.January, 5, 0, 0, 0))
+	draft.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	mustAddBlogPost(t, dbc, draft)
+
+	trashedId := mustAddBlogPost(t, dbc, makeAddDocument("mar-trashed", utcUnix(2021, time.March, 2, 0, 0, 0)))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+}
+
+func expectArchiveCounts(t *testing.T, got []*dbController.ArchiveCount, want []dbController.ArchiveCount) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Errorf("archive counts: got %d months, want %v", len(got), want)
+		return
+	}
+
+	for i := range got {
+		if *got[i] != want[i] {
+			t.Errorf("archive count %d: got %v, want %v", i, *got[i], want[i])
+		}
+	}
+}
+
+func testGetBlogArchive(t *testing.T, dbc dbController.DatabaseController) {
+	empty, emptyErr := dbc.GetBlogArchive(true)
+
+	if emptyErr != nil {
+		t.Fatalf("GetBlogArchive(true): %v", emptyErr)
+	}
+
+	expectArchiveCounts(t, empty, []dbController.ArchiveCount{})
+
+	addArchivePosts(t, dbc)
+
+	public, publicErr := dbc.GetBlogArchive(false)
+
+	if publicErr != nil {
+		t.Fatalf("GetBlogArchive(false): %v", publicErr)
+	}
+
+	expectArchiveCounts(t, public, []dbController.ArchiveCount{
+		{Year: 2023, Month: 1, Count: 1},
+		{Year: 2022, Month: 12, Count: 1},
+		{Year: 2021, Month: 3, Count: 1},
+		{Year: 2021, Month: 1, Count: 2},
+	})
+
+	all, allErr := dbc.GetBlogArchive(true)
+
+	if allErr != nil {
+		t.Fatalf("GetBlogArchive(true): %v", allErr)
+	}
+
+	expectArchiveCounts(t, all, []dbController.ArchiveCount{
+		{Year: 2023, Month: 1, Count: 2},
+		{Year: 2022, Month: 12, Count: 1},
+		{Year: 2021, Month: 3, Count: 1},
+		{Year: 2021, Month: 1, Count: 2},
+	})
+}
+
+func testGetBlogPostsByDateRange(t *testing.T, dbc dbController.DatabaseController) {
+	addArchivePosts(t, dbc)
+
+	month := func(year int, month time.Month) (time.Time, time.Time) {
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+
+	year := func(year int) (time.Time, time.Time) {
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0)
+	}
+
+	jan2021Start, jan2021End := month(2021, time.January)
+	dec2022Start, dec2022End := month(2022, time.December)
+	jan2023Start, jan2023End := month(2023, time.January)
+	y2021Start, y2021End := year(2021)
+	y2020Start, y2020End := year(2020)
+
+	cases := []struct {
+		name               string
+		start              time.Time
+		end                time.Time
+		page               int
+		pagination         int
+		includeUnpublished bool
+		want               []string
+	}{
+		{"January 2021", jan2021Start, jan2021End, 1, 10, false, []string{"jan-b", "jan-a"}},
+		{"December 2022", dec2022Start, dec2022End, 1, 10, false, []string{"dec-end"}},
+		{"January 2023", jan2023Start, jan2023End, 1, 10, false, []string{"jan-start"}},
+		{"January 2023 unpublished", jan2023Start, jan2023End, 1, 10, true, []string{"jan-draft", "jan-start"}},
+		{"2021", y2021Start, y2021End, 1, 10, true, []string{"mar", "jan-b", "jan-a"}},
+		{"2021 page 2", y2021Start, y2021End, 2, 1, false, []string{"jan-b"}},
+		{"2020", y2020Start, y2020End, 1, 10, true, []string{}},
+	}
+
+	for _, c := range cases {
+		posts, err := dbc.GetBlogPostsByDateRange(c.start, c.end, c.page, c.pagination, c.includeUnpublished)
+
+		if err != nil {
+			t.Fatalf("GetBlogPostsByDateRange(%s): %v", c.name, err)
+		}
+
+		expectSlugs(t, posts, c.want)
+	}
+
+	if _, err := dbc.GetBlogPostsByDateRange(y2021Start, y2021End, 0, 10, true); err == nil {
+		t.Errorf("GetBlogPostsByDateRange with page 0: expected an error")
+	}
+
+	y2021, y2021Err := dbc.CountBlogPosts(&dbController.BlogPostFilter{DateAddedStart: &y2021Start, DateAddedEnd: &y2021End}, true)
+	expectCount(t, "CountBlogPosts(2021, true)", y2021, y2021Err, 3)
+
+	// Either end of the range can be left open
+	since, sinceErr := dbc.CountBlogPosts(&dbController.BlogPostFilter{DateAddedStart: &dec2022Start}, false)
+	expectCount(t, "CountBlogPosts(since December 2022, false)", since, sinceErr, 2)
+
+	before, beforeErr := dbc.CountBlogPosts(&dbController.BlogPostFilter{DateAddedEnd: &dec2022End}, true)
+	expectCount(t, "CountBlogPosts(before January 2023, true)", before, beforeErr, 4)
+}
+
+func testGetSitemapEntries(t *testing.T, dbc dbController.DatabaseController) {
+	addArchivePosts(t, dbc)
+
+	mar, marErr := dbc.GetBlogPostBySlug("mar", false)
+
+	if marErr != nil {
+		t.Fatalf("GetBlogPostBySlug(mar): %v", marErr)
+	}
+
+	updated := utcUnix(2024, time.June, 1, 0, 0, 0)
+
+	if err := dbc.EditBlogPost(&dbController.EditBlogDocument{Id: mar.Id, DateUpdated: timePtr(time.Unix(updated, 0))}); err != nil {
+		t.Fatalf("EditBlogPost: %v", err)
+	}
+
+	cases := []struct {
+		page       int
+		pagination int
+		want       []string
+	}{
+		{1, 10, []string{"jan-start", "dec-end", "mar", "jan-b", "jan-a"}},
+		{2, 2, []string{"mar", "jan-b"}},
+		{4, 2, []string{}},
+	}
+
+	for _, c := range cases {
+		entries, err := dbc.GetSitemapEntries(c.page, c.pagination)
+
+		if err != nil {
+			t.Fatalf("GetSitemapEntries(%d, %d): %v", c.page, c.pagination, err)
+		}
+
+		slugs := make([]string, 0)
+		for _, entry := range entries {
+			slugs = append(slugs, entry.Slug)
+
+			if entry.Slug == "mar" {
+				expectTime(t, "dateAdded", entry.DateAdded, utcUnix(2021, time.March, 1, 0, 0, 0))
+				expectTime(t, "dateUpdated", entry.DateUpdated, updated)
+				expectTime(t, "lastModified", entry.LastModified(), updated)
+			}
+		}
+
+		expectStrings(t, "slugs", slugs, c.want)
+	}
+
+	if _, err := dbc.GetSitemapEntries(0, 10); err == nil {
+		t.Errorf("GetSitemapEntries with page 0: expected an error")
+	}
+}
+
+func mustSearchBlogPosts(t *testing.T, dbc dbController.DatabaseController, query string, page int, pagination int, includeUnpublished bool) []*dbController.SearchResult {
+	t.Helper()
+
+	results, err := dbc.SearchBlogPosts(query, page, pagination, includeUnpublished)
+
+	if err != nil {
+		t.Fatalf("SearchBlogPosts(%q, %d, %d, %v): %v", query, page, pagination, includeUnpublished, err)
+	}
+
+	return results
+}
+
+func searchResultPosts(results []*dbController.SearchResult) []*dbController.BlogDocument {
+	posts := make([]*dbController.BlogDocument, 0)
+	for _, result := range results {
+		posts = append(posts, result.Post)
+	}
+
+	return posts
+}
+
+func testSearchBlogPosts(t *testing.T, dbc dbController.DatabaseController) {
+	addSearchPost := func(slug string, title string, body string, tags []string, dateAdded int64) *dbController.AddBlogDocument {
+		doc := makeAddDocument(slug, dateAdded)
+		doc.Title = title
+		doc.Body = body
+		doc.Tags = &tags
+
+		return doc
+	}
+
+	mustAddBlogPost(t, dbc, addSearchPost("title-post", "The gopher guide", "Unrelated words", []string{"go"}, 1000))
+	mustAddBlogPost(t, dbc, addSearchPost("body-post", "Other", "Today a gopher <appeared> in the garden", []string{"go"}, 2000))
+	mustAddBlogPost(t, dbc, addSearchPost("tag-post", "Another", "Nothing to see", []string{"gopher"}, 3000))
+	mustAddBlogPost(t, dbc, addSearchPost("no-match-post", "Cooking", "Bread and butter", []string{"food"}, 4000))
+
+	draft := addSearchPost("draft-post", "Draft gopher", "Draft", []string{"go"}, 5000)
+	draft.Status = stringPtr(dbController.BLOG_STATUS_DRAFT)
+	mustAddBlogPost(t, dbc, draft)
+
+	trashedId := mustAddBlogPost(t, dbc, addSearchPost("trashed-post", "Trashed gopher", "Trashed", []string{"go"}, 6000))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: trashedId}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	public := mustSearchBlogPosts(t, dbc, "Gopher", 1, 10, false)
+	expectSlugs(t, searchResultPosts(public), []string{"title-post", "tag-post", "body-post"})
+
+	for _, result := range public {
+		if result.Score <= 0 {
+			t.Errorf("score of %q: got %v, want a positive score", result.Post.Slug, result.Score)
+		}
+	}
+
+	if len(public) == 3 {
+		expectString(t, "snippet", public[2].Snippet, "Today a <mark>gopher</mark> &lt;appeared&gt; in the garden")
+	}
+
+	all := mustSearchBlogPosts(t, dbc, "gopher", 1, 10, true)
+
+	if len(all) != 4 {
+		t.Errorf("SearchBlogPosts(\"gopher\", 1, 10, true): got %d results, want 4", len(all))
+	}
+
+	paged := mustSearchBlogPosts(t, dbc, "gopher", 2, 2, false)
+	expectSlugs(t, searchResultPosts(paged), []string{"body-post"})
+
+	// Any of the words can match
+	either := mustSearchBlogPosts(t, dbc, "butter gopher", 1, 10, false)
+
+	if len(either) != 4 {
+		t.Errorf("SearchBlogPosts(\"butter gopher\", 1, 10, false): got %d results, want 4", len(either))
+	}
+
+	none := mustSearchBlogPosts(t, dbc, "zebra", 1, 10, true)
+	expectSlugs(t, searchResultPosts(none), []string{})
+
+	publicCount, publicCountErr := dbc.CountSearchResults("gopher", false)
+	expectCount(t, "CountSearchResults(\"gopher\", false)", publicCount, publicCountErr, 3)
+
+	allCount, allCountErr := dbc.CountSearchResults("gopher", true)
+	expectCount(t, "CountSearchResults(\"gopher\", true)", allCount, allCountErr, 4)
+
+	noneCount, noneCountErr := dbc.CountSearchResults("zebra", true)
+	expectCount(t, "CountSearchResults(\"zebra\", true)", noneCount, noneCountErr, 0)
+}
+
+func testSearchBlogPostsInvalid(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddBlogPost(t, dbc, makeAddDocument("search-post", 1000))
+
+	_, emptyErr := dbc.SearchBlogPosts(" -- ", 1, 10, true)
+	expectInvalidInputError(t, emptyErr)
+
+	_, emptyCountErr := dbc.CountSearchResults(" -- ", true)
+	expectInvalidInputError(t, emptyCountErr)
+
+	if _, err := dbc.SearchBlogPosts("title", 0, 10, true); err == nil {
+		t.Errorf("SearchBlogPosts with page 0: expected an error")
+	}
+
+	if _, err := dbc.SearchBlogPosts("title", 1, 0, true); err == nil {
+		t.Errorf("SearchBlogPosts with pagination 0: expected an error")
+	}
+}
+
+func mustGetRevisions(t *testing.T, dbc dbController.DatabaseController, postId string) []*dbController.BlogRevision {
+	t.Helper()
+
+	revisions, err := dbc.GetBlogPostRevisions(postId)
+
+	if err != nil {
+		t.Fatalf("GetBlogPostRevisions(%q): %v", postId, err)
+	}
+
+	return revisions
+}
+
+func expectRevisionNumbers(t *testing.T, revisions []*dbController.BlogRevision, want []int) {
+	t.Helper()
+
+	got := make([]int, 0)
+	for _, rev := range revisions {
+		got = append(got, rev.Revision)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("revisions: got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("revisions: got %v, want %v", got, want)
+		}
+	}
+}
+
+func testRevisionOnAdd(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("revision-post", 1000))
+
+	revisions := mustGetRevisions(t, dbc, id)
+	expectRevisionNumbers(t, revisions, []int{1})
+
+	rev := revisions[0]
+	expectString(t, "postId", rev.PostId, id)
+	expectString(t, "title", rev.Title, "Title revision-post")
+	expectString(t, "slug", rev.Slug, "revision-post")
+	expectString(t, "updateAuthorId", rev.UpdateAuthorId, "author-uid")
+	expectString(t, "status", rev.Status, dbController.BLOG_STATUS_PUBLISHED)
+	expectTime(t, "dateUpdated", rev.DateUpdated, 1000)
+	expectTags(t, rev.Tags, []string{"tag1", "tag2"})
+
+	if len(rev.ChangedFields) != 0 {
+		t.Errorf("changedFields: got %v, want none", rev.ChangedFields)
+	}
+
+	unknown := mustGetRevisions(t, dbc, UNKNOWN_ID)
+	expectRevisionNumbers(t, unknown, []int{})
+
+	_, invalidErr := dbc.GetBlogPostRevisions(INVALID_ID)
+	expectInvalidInputError(t, invalidErr)
+}
+
+func testRevisionOnEdit(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("revision-post", 1000))
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:             id,
+		Title:          stringPtr("New Title"),
+		Body:           stringPtr("New Body"),
+		UpdateAuthorId: stringPtr("editor-uid"),
+		DateUpdated:    timePtr(time.Unix(2000, 0)),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	revisions := mustGetRevisions(t, dbc, id)
+	expectRevisionNumbers(t, revisions, []int{2, 1})
+
+	latest := revisions[0]
+	expectString(t, "title", latest.Title, "New Title")
+	expectString(t, "body", latest.Body, "New Body")
+	expectString(t, "updateAuthorId", latest.UpdateAuthorId, "editor-uid")
+	expectTime(t, "dateUpdated", latest.DateUpdated, 2000)
+	expectStrings(t, "changedFields", latest.ChangedFields, []string{"title", "body"})
+
+	first, firstErr := dbc.GetBlogPostRevision(id, 1)
+
+	if firstErr != nil {
+		t.Fatalf("GetBlogPostRevision(%q, 1): %v", id, firstErr)
+	}
+
+	expectString(t, "title", first.Title, "Title revision-post")
+	expectString(t, "body", first.Body, "Body revision-post")
+
+	diffs := dbController.DiffBlogRevisions(first, latest)
+
+	if len(diffs) != 2 || diffs[0].Field != "title" || diffs[0].From != "Title revision-post" || diffs[0].To != "New Title" {
+		t.Errorf("DiffBlogRevisions: got %d changes, want title and body", len(diffs))
+	}
+
+	_, missingErr := dbc.GetBlogPostRevision(id, 3)
+	expectNoResultsError(t, missingErr)
+
+	_, invalidErr := dbc.GetBlogPostRevision(INVALID_ID, 1)
+	expectInvalidInputError(t, invalidErr)
+}
+
+func testRevisionRestore(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("revision-post", 1000))
+
+	editErr := dbc.EditBlogPost(&dbController.EditBlogDocument{
+		Id:     id,
+		Title:  stringPtr("New Title"),
+		Slug:   stringPtr("new-slug"),
+		Tags:   &[]string{"tag3"},
+		Status: stringPtr(dbController.BLOG_STATUS_DRAFT),
+	})
+
+	if editErr != nil {
+		t.Fatalf("EditBlogPost: %v", editErr)
+	}
+
+	first, firstErr := dbc.GetBlogPostRevision(id, 1)
+
+	if firstErr != nil {
+		t.Fatalf("GetBlogPostRevision(%q, 1): %v", id, firstErr)
+	}
+
+	if restoreErr := dbc.EditBlogPost(first.GetEditDocument()); restoreErr != nil {
+		t.Fatalf("EditBlogPost(restore): %v", restoreErr)
+	}
+
+	post := mustGetBlogPostById(t, dbc, id)
+	expectString(t, "title", post.Title, "Title revision-post")
+	expectString(t, "slug", post.Slug, "revision-post")
+	expectString(t, "status", post.Status, dbController.BLOG_STATUS_PUBLISHED)
+	expectTags(t, post.Tags, []string{"tag1", "tag2"})
+
+	revisions := mustGetRevisions(t, dbc, id)
+	expectRevisionNumbers(t, revisions, []int{3, 2, 1})
+	expectStrings(t, "changedFields", revisions[0].ChangedFields, []string{"title", "slug", "tags", "status"})
+}
+
+func testRevisionsPurgedWithPost(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddBlogPost(t, dbc, makeAddDocument("revision-post", 1000))
+
+	if err := dbc.DeleteBlogPost(&dbController.DeleteBlogDocument{Id: id}); err != nil {
+		t.Fatalf("DeleteBlogPost: %v", err)
+	}
+
+	// Revisions are kept while the post is in the trash
+	expectRevisionNumbers(t, mustGetRevisions(t, dbc, id), []int{1})
+
+	if err := dbc.PurgeBlogPost(id); err != nil {
+		t.Fatalf("PurgeBlogPost: %v", err)
+	}
+
+	expectRevisionNumbers(t, mustGetRevisions(t, dbc, id), []int{})
+}
+
+func testAddUserInformation(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+	mustAddUser(t, dbc, "other-uid", "Other Name", "other@example.com")
+
+	id := mustAddBlogPost(t, dbc, makeAddDocument("user-post", 1000))
+
+	// Adding a second user must not change the first one
+	post := mustGetBlogPostById(t, dbc, id)
+	expectString(t, "author", post.Author, "Author Name")
+
+	// Adding a user with an existing uid updates that user
+	mustAddUser(t, dbc, "author-uid", "Renamed Author", "author@example.com")
+
+	post = mustGetBlogPostById(t, dbc, id)
+	expectString(t, "author", post.Author, "Renamed Author")
+}
+
+func testAddUserInformationDuplicateEmail(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "first-uid", "First Name", "same@example.com")
+
+	info := user.UserInformation{
+		Uid:    "second-uid",
+		Name:   "Second Name",
+		Email:  "same@example.com",
+		Active: true,
+		Role:   user.Reader,
+	}
+
+	expectDuplicateEntryError(t, dbc.AddUserInformation(&info))
+}
+
+func testGetUsers(t *testing.T, dbc dbController.DatabaseController) {
+	expectCount(t, "CountUsers", mustCountUsers(t, dbc), nil, 0)
+
+	mustAddUser(t, dbc, "c-uid", "C Name", "c@example.com")
+	mustAddUser(t, dbc, "a-uid", "A Name", "a@example.com")
+	mustAddUser(t, dbc, "b-uid", "B Name", "b@example.com")
+
+	expectCount(t, "CountUsers", mustCountUsers(t, dbc), nil, 3)
+
+	first, firstErr := dbc.GetUsers(1, 2)
+	if firstErr != nil {
+		t.Fatalf("GetUsers: %v", firstErr)
+	}
+
+	second, secondErr := dbc.GetUsers(2, 2)
+	if secondErr != nil {
+		t.Fatalf("GetUsers: %v", secondErr)
+	}
+
+	uids := make([]string, 0)
+	for _, info := range append(first, second...) {
+		uids = append(uids, info.Uid)
+	}
+	expectStrings(t, "uids", uids, []string{"a-uid", "b-uid", "c-uid"})
+
+	past, pastErr := dbc.GetUsers(3, 2)
+	if pastErr != nil {
+		t.Fatalf("GetUsers: %v", pastErr)
+	}
+	expectCount(t, "GetUsers past the end", len(past), nil, 0)
+
+	info, getErr := dbc.GetUserInformation("a-uid")
+	if getErr != nil {
+		t.Fatalf("GetUserInformation: %v", getErr)
+	}
+	expectString(t, "name", info.Name, "A Name")
+	expectString(t, "email", info.Email, "a@example.com")
+	expectString(t, "role", info.Role.String(), "editor")
+	if !info.Active {
+		t.Errorf("active = false, want true")
+	}
+
+	_, unknownErr := dbc.GetUserInformation("unknown-uid")
+	expectNoResultsError(t, unknownErr)
+}
+
+func mustCountUsers(t *testing.T, dbc dbController.DatabaseController) int {
+	t.Helper()
+
+	count, err := dbc.CountUsers()
+	if err != nil {
+		t.Fatalf("CountUsers: %v", err)
+	}
+
+	return count
+}
+
+func testEditUserInformation(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+	mustAddUser(t, dbc, "other-uid", "Other Name", "other@example.com")
+
+	admin := user.Admin
+	editErr := dbc.EditUserInformation(&dbController.EditUserDocument{
+		Uid:    "author-uid",
+		Active: boolPtr(false),
+		Role:   &admin,
+	})
+	if editErr != nil {
+		t.Fatalf("EditUserInformation: %v", editErr)
+	}
+
+	info, getErr := dbc.GetUserInformation("author-uid")
+	if getErr != nil {
+		t.Fatalf("GetUserInformation: %v", getErr)
+	}
+
+	// Only the fields in the document change
+	expectString(t, "name", info.Name, "Author Name")
+	expectString(t, "email", info.Email, "author@example.com")
+	expectString(t, "role", info.Role.String(), "admin")
+	if info.Active {
+		t.Errorf("active = true, want false")
+	}
+
+	duplicateErr := dbc.EditUserInformation(&dbController.EditUserDocument{
+		Uid:   "author-uid",
+		Email: stringPtr("other@example.com"),
+	})
+	expectDuplicateEntryError(t, duplicateErr)
+
+	unknownErr := dbc.EditUserInformation(&dbController.EditUserDocument{
+		Uid:  "unknown-uid",
+		Name: stringPtr("Name"),
+	})
+	expectInvalidInputError(t, unknownErr)
+
+	emptyErr := dbc.EditUserInformation(&dbController.EditUserDocument{Uid: "unknown-uid"})
+	expectInvalidInputError(t, emptyErr)
+
+	if err := dbc.EditUserInformation(&dbController.EditUserDocument{Uid: "author-uid"}); err != nil {
+		t.Errorf("EditUserInformation without changes: %v", err)
+	}
+}
+
+func testDeleteUserInformation(t *testing.T, dbc dbController.DatabaseController) {
+	mustAddUser(t, dbc, "author-uid", "Author Name", "author@example.com")
+
+	id := mustAddBlogPost(t, dbc, makeAddDocument("user-post", 1000))
+
+	if err := dbc.DeleteUserInformation("author-uid"); err != nil {
+		t.Fatalf("DeleteUserInformation: %v", err)
+	}
+
+	_, getErr := dbc.GetUserInformation("author-uid")
+	expectNoResultsError(t, getErr)
+
+	// The posts of the user are kept
+	mustGetBlogPostById(t, dbc, id)
+
+	expectInvalidInputError(t, dbc.DeleteUserInformation("author-uid"))
+
+	// The email can be used again
+	mustAddUser(t, dbc, "new-uid", "New Name", "author@example.com")
+}
+
+// MISSING_API_KEY_ID is a valid id that doesn't match any API key
+const MISSING_API_KEY_ID = "000000000000000000000000"
+
+func makeApiKeyDocument(name string, hash string, dateAdded int64) *dbController.AddApiKeyDocument {
+	return &dbController.AddApiKeyDocument{
+		Name:      name,
+		OwnerId:   "owner-uid",
+		Prefix:    "blog_" + name,
+		Hash:      hash,
+		Scopes:    []string{"posts:write", "posts:delete"},
+		DateAdded: time.Unix(dateAdded, 0),
+	}
+}
+
+func mustAddApiKey(t *testing.T, dbc dbController.DatabaseController, doc *dbController.AddApiKeyDocument) string {
+	t.Helper()
+
+	id, err := dbc.AddApiKey(doc)
+
+	if err != nil {
+		t.Fatalf("AddApiKey(%s): %v", doc.Name, err)
+	}
+
+	return id
+}
+
+func mustGetApiKeyByHash(t *testing.T, dbc dbController.DatabaseController, hash string) *dbController.ApiKeyDocument {
+	t.Helper()
+
+	key, err := dbc.GetApiKeyByHash(hash)
+
+	if err != nil {
+		t.Fatalf("GetApiKeyByHash(%s): %v", hash, err)
+	}
+
+	return key
+}
+
+func testAddApiKey(t *testing.T, dbc dbController.DatabaseController) {
+	doc := makeApiKeyDocument("ci", "hash-ci", 1000)
+	doc.ExpiresAt = timePtr(time.Unix(5000, 0))
+
+	id := mustAddApiKey(t, dbc, doc)
+
+	key := mustGetApiKeyByHash(t, dbc, "hash-ci")
+
+	expectString(t, "id", key.Id, id)
+	expectString(t, "name", key.Name, "ci")
+	expectString(t, "ownerId", key.OwnerId, "owner-uid")
+	expectString(t, "prefix", key.Prefix, "blog_ci")
+	expectString(t, "hash", key.Hash, "hash-ci")
+	expectStrings(t, "scopes", key.Scopes, []string{"posts:write", "posts:delete"})
+	expectTime(t, "dateAdded", key.DateAdded, 1000)
+
+	if key.ExpiresAt == nil {
+		t.Errorf("expiresAt: got nil, want 5000")
+	} else {
+		expectTime(t, "expiresAt", *key.ExpiresAt, 5000)
+	}
+
+	if key.LastUsed != nil || key.RevokedAt != nil {
+		t.Errorf("new key: got lastUsed %v and revokedAt %v, want nil", key.LastUsed, key.RevokedAt)
+	}
+
+	// Keys without an expiry never expire
+	mustAddApiKey(t, dbc, makeApiKeyDocument("importer", "hash-importer", 1000))
+
+	if importer := mustGetApiKeyByHash(t, dbc, "hash-importer"); importer.ExpiresAt != nil {
+		t.Errorf("expiresAt: got %v, want nil", importer.ExpiresAt)
+	}
+
+	_, dupErr := dbc.AddApiKey(makeApiKeyDocument("other", "hash-ci", 2000))
+	expectDuplicateEntryError(t, dupErr)
+
+	_, missingErr := dbc.GetApiKeyByHash("hash-missing")
+	expectNoResultsError(t, missingErr)
+}
+
+func testGetApiKeys(t *testing.T, dbc dbController.DatabaseController) {
+	keys, err := dbc.GetApiKeys()
+
+	if err != nil {
+		t.Fatalf("GetApiKeys: %v", err)
+	}
+
+	if len(keys) != 0 {
+		t.Errorf("GetApiKeys: got %d keys, want 0", len(keys))
+	}
+
+	mustAddApiKey(t, dbc, makeApiKeyDocument("first", "hash-first", 1000))
+	mustAddApiKey(t, dbc, makeApiKeyDocument("third", "hash-third", 3000))
+	mustAddApiKey(t, dbc, makeApiKeyDocument("second", "hash-second", 2000))
+
+	keys, err = dbc.GetApiKeys()
+
+	if err != nil {
+		t.Fatalf("GetApiKeys: %v", err)
+	}
+
+	names := make([]string, 0)
+	for _, key := range keys {
+		names = append(names, key.Name)
+	}
+
+	expectStrings(t, "names", names, []string{"third", "second", "first"})
+}
+
+func testRevokeApiKey(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddApiKey(t, dbc, makeApiKeyDocument("ci", "hash-ci", 1000))
+
+	if err := dbc.RevokeApiKey(id, time.Unix(2000, 0)); err != nil {
+		t.Fatalf("RevokeApiKey: %v", err)
+	}
+
+	// The first revocation is kept
+	if err := dbc.RevokeApiKey(id, time.Unix(3000, 0)); err != nil {
+		t.Fatalf("RevokeApiKey: %v", err)
+	}
+
+	key := mustGetApiKeyByHash(t, dbc, "hash-ci")
+
+	if key.RevokedAt == nil {
+		t.Fatalf("revokedAt: got nil, want 2000")
+	}
+
+	expectTime(t, "revokedAt", *key.RevokedAt, 2000)
+
+	// Revoked keys are still listed
+	keys, err := dbc.GetApiKeys()
+
+	if err != nil {
+		t.Fatalf("GetApiKeys: %v", err)
+	}
+
+	if len(keys) != 1 {
+		t.Errorf("GetApiKeys: got %d keys, want 1", len(keys))
+	}
+
+	expectInvalidInputError(t, dbc.RevokeApiKey(MISSING_API_KEY_ID, time.Unix(2000, 0)))
+}
+
+func testSetApiKeyLastUsed(t *testing.T, dbc dbController.DatabaseController) {
+	id := mustAddApiKey(t, dbc, makeApiKeyDocument("ci", "hash-ci", 1000))
+
+	if err := dbc.SetApiKeyLastUsed(id, time.Unix(2000, 0)); err != nil {
+		t.Fatalf("SetApiKeyLastUsed: %v", err)
+	}
+
+	if err := dbc.SetApiKeyLastUsed(id, time.Unix(3000, 0)); err != nil {
+		t.Fatalf("SetApiKeyLastUsed: %v", err)
+	}
+
+	key := mustGetApiKeyByHash(t, dbc, "hash-ci")
+
+	if key.LastUsed == nil {
+		t.Fatalf("lastUsed: got nil, want 3000")
+	}
+
+	expectTime(t, "lastUsed", *key.LastUsed, 3000)
+
+	expectInvalidInputError(t, dbc.SetApiKeyLastUsed(MISSING_API_KEY_ID, time.Unix(2000, 0)))
+}
+
+func testLogs(t *testing.T, dbc dbController.DatabaseController) {
+	requestLog := logging.RequestLogData{
+		Timestamp:  time.Now(),
+		Type:       "request",
+		ClientIP:   "127.0.0.1",
+		Method:     "GET",
+		Path:       "/blog",
+		Protocol:   "HTTP/1.1",
+		StatusCode: 200,
+		Latency:    time.Millisecond,
+		UserAgent:  "conformance",
+	}
+
+	if err := dbc.AddRequestLog(&requestLog); err != nil {
+		t.Errorf("AddRequestLog: %v", err)
+	}
+
+	infoLog := logging.InfoLogData{
+		Timestamp: time.Now(),
+		Type:      "info",
+		Message:   "conformance",
+	}
+
+	if err := dbc.AddInfoLog(&infoLog); err != nil {
+		t.Errorf("AddInfoLog: %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package dbController
 
 import (
+	"time"
+
 	"methompson.com/blog-microservice/blogServer/logging"
 	"methompson.com/blog-microservice/blogServer/user"
 )
@@ -8,15 +10,126 @@ import (
 type DatabaseController interface {
 	InitDatabase() error
 
+	// The get functions only return public blog posts, unless
+	// includeUnpublished is true. See BlogDocument.IsPublic. Posts in the
+	// trash are never returned by the get functions, and can't be edited.
 	AddBlogPost(doc *AddBlogDocument) (id string, err error)
-	GetBlogPostById(id string) (*BlogDocument, error)
-	GetBlogPostBySlug(slug string) (*BlogDocument, error)
-	GetBlogPosts(page int, pagination int) ([]*BlogDocument, error)
+	GetBlogPostById(id string, includeUnpublished bool) (*BlogDocument, error)
+	GetBlogPostBySlug(slug string, includeUnpublished bool) (*BlogDocument, error)
+	GetBlogPosts(page int, pagination int, includeUnpublished bool) ([]*BlogDocument, error)
 	EditBlogPost(doc *EditBlogDocument) error
 	DeleteBlogPost(doc *DeleteBlogDocument) error
 
+	// EditBlogPost keeps the slugs that a blog post had before, so that old
+	// links can be sent to the post. A slug can't be given to a post if another
+	// post used to have it. GetBlogPostByOldSlug returns the visible blog post
+	// that used to have the slug. The old slugs are purged along with the post.
+	GetBlogPostByOldSlug(slug string, includeUnpublished bool) (*BlogDocument, error)
+
+	// IsSlugAvailable returns whether the slug can be given to the blog post
+	// with the id, or to a new post if the id is empty. Slugs of posts in the
+	// trash and the old slugs of other posts are taken.
+	IsSlugAvailable(slug string, id string) (bool, error)
+
+	// CountBlogPosts returns the number of visible blog posts that match the
+	// filter, or of every visible blog post if the filter is nil.
+	// GetBlogPostsByCursor returns up to limit visible blog posts next to the
+	// cursor, in the same order as GetBlogPosts. A nil cursor returns the
+	// first posts. Posts added at the same time are sorted by id.
+	CountBlogPosts(filter *BlogPostFilter, includeUnpublished bool) (int, error)
+	GetBlogPostsByCursor(cursor *BlogCursor, limit int, includeUnpublished bool) ([]*BlogDocument, error)
+
+	// GetBlogTags returns every tag on the visible blog posts with the number
+	// of posts that have it, most used first. Tags used equally often are
+	// sorted by name.
+	GetBlogTags(includeUnpublished bool) ([]*TagCount, error)
+
+	// GetBlogPostsByTag returns the visible blog posts with the tag. Sorting
+	// and paging work the same way as GetBlogPosts.
+	GetBlogPostsByTag(tag string, page int, pagination int, includeUnpublished bool) ([]*BlogDocument, error)
+
+	// GetBlogAuthor returns the public profile of the user with the uid, or a
+	// NoResultsError if there isn't one. GetBlogPostsByAuthor returns the
+	// visible blog posts written by the user. Sorting and paging work the same
+	// way as GetBlogPosts.
+	GetBlogAuthor(uid string) (*BlogAuthor, error)
+	GetBlogPostsByAuthor(uid string, page int, pagination int, includeUnpublished bool) ([]*BlogDocument, error)
+
+	// GetBlogAuthors returns the public profiles of the users who wrote at least
+	// one visible blog post, sorted by uid.
+	GetBlogAuthors(includeUnpublished bool) ([]*BlogAuthor, error)
+
+	// GetBlogArchive returns the number of visible blog posts added in every
+	// month that has any, newest month first. GetBlogPostsByDateRange returns
+	// the visible blog posts added from start up to, but not including, end.
+	// Sorting and paging work the same way as GetBlogPosts.
+	GetBlogArchive(includeUnpublished bool) ([]*ArchiveCount, error)
+	GetBlogPostsByDateRange(start time.Time, end time.Time, page int, pagination int, includeUnpublished bool) ([]*BlogDocument, error)
+
+	// GetSitemapEntries returns a page of the public blog posts with only the
+	// fields a sitemap needs. Sorting and paging work the same way as
+	// GetBlogPosts.
+	GetSitemapEntries(page int, pagination int) ([]*SitemapEntry, error)
+
+	// SearchBlogPosts returns the posts that match any word of the query in
+	// their title, body or tags, best match first. Visibility and paging work
+	// the same way as GetBlogPosts. A query without any words is invalid input.
+	SearchBlogPosts(query string, page int, pagination int, includeUnpublished bool) ([]*SearchResult, error)
+	CountSearchResults(query string, includeUnpublished bool) (int, error)
+
+	// DeleteBlogPost moves a blog post to the trash. Posts in the trash keep
+	// their slug until they are purged. GetTrashedBlogPosts returns the most
+	// recently deleted posts first. PurgeTrashedBlogPosts purges every post
+	// deleted before deletedBefore and returns the number of posts purged.
+	GetTrashedBlogPosts(page int, pagination int) ([]*BlogDocument, error)
+	CountTrashedBlogPosts() (int, error)
+	RestoreBlogPost(id string) error
+	PurgeBlogPost(id string) error
+	PurgeTrashedBlogPosts(deletedBefore time.Time) (int, error)
+
+	// AddBlogPost and EditBlogPost save a BlogRevision of every change. The
+	// revisions are purged along with the blog post. GetBlogPostRevisions
+	// returns the newest revision first.
+	GetBlogPostRevisions(postId string) ([]*BlogRevision, error)
+	GetBlogPostRevision(postId string, revision int) (*BlogRevision, error)
+
+	// AddUserInformation inserts the user information or replaces the
+	// information of the user with the same uid. Emails are unique among users.
+	// GetUsers returns the users sorted by uid. EditUserInformation only
+	// changes the fields that aren't nil. Editing or deleting a user that
+	// doesn't exist is invalid input. Deleting a user doesn't change the blog
+	// posts they wrote.
 	AddUserInformation(info *user.UserInformation) error
+	GetUserInformation(uid string) (*user.UserInformation, error)
+	GetUsers(page int, pagination int) ([]*user.UserInformation, error)
+	CountUsers() (int, error)
+	EditUserInformation(doc *EditUserDocument) error
+	DeleteUserInformation(uid string) error
+
+	// API keys are found by the hash of the key, so hashes are unique.
+	// GetApiKeyByHash returns a NoResultsError for unknown hashes. GetApiKeys
+	// returns every key, newest first, including revoked keys. RevokeApiKey
+	// keeps the first time a key was revoked. Revoking or using a key that
+	// doesn't exist is invalid input.
+	AddApiKey(doc *AddApiKeyDocument) (id string, err error)
+	GetApiKeyByHash(hash string) (*ApiKeyDocument, error)
+	GetApiKeys() ([]*ApiKeyDocument, error)
+	RevokeApiKey(id string, revokedAt time.Time) error
+	SetApiKeyLastUsed(id string, lastUsed time.Time) error
 
 	AddRequestLog(log *logging.RequestLogData) error
 	AddInfoLog(log *logging.InfoLogData) error
 }
+
+// LATEST_MIGRATION can be passed to Migrator.MigrateTo to apply every migration
+const LATEST_MIGRATION = -1
+
+// Migrator is implemented by DatabaseControllers that version their schema
+// with migrations. MigrateTo applies or reverts migrations until the schema
+// is at the requested version. With dryRun set, the changes are printed but
+// nothing is changed. GetMigrationVersion never changes the database, so it is
+// safe to call before a dry run.
+type Migrator interface {
+	GetMigrationVersion() (int, error)
+	MigrateTo(version int, dryRun bool) error
+}
@@ -0,0 +1,175 @@
+package dbController
+
+import (
+	"reflect"
+	"time"
+)
+
+// A BlogRevision is an immutable snapshot of a blog post, saved every time the
+// post is added or edited. Revisions are numbered from 1 for each post. The
+// UpdateAuthorId and DateUpdated record who made the revision and when.
+// ChangedFields lists the fields that differ from the previous revision, and
+// is empty for the first revision.
+type BlogRevision struct {
+	PostId         string
+	Revision       int
+	Title          string
+	Slug           string
+	Body           string
+	Tags           []string
+	AuthorId       string
+	DateAdded      time.Time
+	UpdateAuthorId string
+	DateUpdated    time.Time
+	Status         string
+	PublishAt      time.Time
+	ChangedFields  []string
+}
+
+// A RevisionFieldDiff is a single field that differs between two revisions
+type RevisionFieldDiff struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+func (rfd *RevisionFieldDiff) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["field"] = rfd.Field
+	m["from"] = rfd.From
+	m["to"] = rfd.To
+
+	return &m
+}
+
+// NewBlogRevision makes a revision from the current state of a blog post.
+// When previous, the state of the post before the edit, isn't nil, the changed
+// fields are filled in.
+func NewBlogRevision(post *BlogDocument, revision int, previous *BlogDocument) *BlogRevision {
+	rev := BlogRevision{
+		PostId:         post.Id,
+		Revision:       revision,
+		Title:          post.Title,
+		Slug:           post.Slug,
+		Body:           post.Body,
+		Tags:           post.Tags,
+		AuthorId:       post.AuthorId,
+		DateAdded:      post.DateAdded,
+		UpdateAuthorId: post.UpdateAuthorId,
+		DateUpdated:    post.DateUpdated,
+		Status:         post.Status,
+		PublishAt:      post.PublishAt,
+		ChangedFields:  []string{},
+	}
+
+	if previous != nil {
+		previousRev := NewBlogRevision(previous, revision-1, nil)
+
+		for _, diff := range DiffBlogRevisions(previousRev, &rev) {
+			rev.ChangedFields = append(rev.ChangedFields, diff.Field)
+		}
+	}
+
+	return &rev
+}
+
+// revisionField is the name and value of a field that is tracked between
+// revisions. Dates are compared as Unix timestamps.
+type revisionField struct {
+	name  string
+	value interface{}
+}
+
+func (br *BlogRevision) getFields() []revisionField {
+	tags := br.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return []revisionField{
+		{"title", br.Title},
+		{"slug", br.Slug},
+		{"body", br.Body},
+		{"tags", tags},
+		{"authorId", br.AuthorId},
+		{"dateAdded", br.DateAdded.Unix()},
+		{"status", br.Status},
+		{"publishAt", br.PublishAt.Unix()},
+	}
+}
+
+// DiffBlogRevisions returns the fields that changed going from one revision to
+// the other. The UpdateAuthorId and DateUpdated aren't compared, since they
+// change with every revision.
+func DiffBlogRevisions(from *BlogRevision, to *BlogRevision) []*RevisionFieldDiff {
+	fromFields := from.getFields()
+	toFields := to.getFields()
+
+	diffs := make([]*RevisionFieldDiff, 0)
+
+	for i := range fromFields {
+		if reflect.DeepEqual(fromFields[i].value, toFields[i].value) {
+			continue
+		}
+
+		diffs = append(diffs, &RevisionFieldDiff{
+			Field: fromFields[i].name,
+			From:  fromFields[i].value,
+			To:    toFields[i].value,
+		})
+	}
+
+	return diffs
+}
+
+// GetEditDocument returns the EditBlogDocument that restores a blog post to
+// the state saved in the revision.
+func (br *BlogRevision) GetEditDocument() *EditBlogDocument {
+	tags := make([]string, len(br.Tags))
+	copy(tags, br.Tags)
+
+	title := br.Title
+	slug := br.Slug
+	body := br.Body
+	authorId := br.AuthorId
+	dateAdded := br.DateAdded
+	status := br.Status
+	publishAt := br.PublishAt
+
+	doc := EditBlogDocument{
+		Id:        br.PostId,
+		Title:     &title,
+		Slug:      &slug,
+		Body:      &body,
+		Tags:      &tags,
+		AuthorId:  &authorId,
+		DateAdded: &dateAdded,
+		Status:    &status,
+		PublishAt: &publishAt,
+	}
+
+	return &doc
+}
+
+func (br *BlogRevision) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["postId"] = br.PostId
+	m["revision"] = br.Revision
+
+	for _, field := range br.getFields() {
+		m[field.name] = field.value
+	}
+
+	m["updateAuthorId"] = br.UpdateAuthorId
+	m["dateUpdated"] = br.DateUpdated.Unix()
+
+	if br.ChangedFields != nil {
+		m["changedFields"] = br.ChangedFields
+	} else {
+		m["changedFields"] = make([]string, 0)
+	}
+
+	return &m
+}
@@ -0,0 +1,200 @@
+package dbController
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SNIPPET_LENGTH is the approximate number of characters of the body that are
+// shown around the first match in a search result.
+const SNIPPET_LENGTH = 160
+
+// The weights used by ScoreBlogPost. A match in the title counts for more than
+// a match in the tags, which counts for more than a match in the body.
+const SEARCH_TITLE_WEIGHT = 10.0
+const SEARCH_TAG_WEIGHT = 5.0
+const SEARCH_BODY_WEIGHT = 1.0
+
+// A SearchResult is a blog post matched by SearchBlogPosts. Scores are only
+// comparable between results of the same search on the same backend. The
+// snippet is HTML escaped text from the body, with the search terms wrapped in
+// <mark> tags.
+type SearchResult struct {
+	Post    *BlogDocument
+	Score   float64
+	Snippet string
+}
+
+func (sr *SearchResult) GetMap() *map[string]interface{} {
+	m := *sr.Post.GetMap()
+
+	m["score"] = sr.Score
+	m["snippet"] = sr.Snippet
+
+	return &m
+}
+
+// SearchTerms splits a search query into lower case words. Duplicate words are
+// removed. A post matches a search if it contains any of the terms.
+func SearchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+
+	return terms
+}
+
+// ScoreBlogPost counts the matches of the terms in a blog post, weighted by the
+// field they are found in. Backends without a text index of their own use it
+// to rank search results.
+func ScoreBlogPost(post *BlogDocument, terms []string) float64 {
+	title := strings.ToLower(post.Title)
+	body := strings.ToLower(post.Body)
+	tags := strings.ToLower(strings.Join(post.Tags, " "))
+
+	score := 0.0
+
+	for _, term := range terms {
+		score += SEARCH_TITLE_WEIGHT * float64(strings.Count(title, term))
+		score += SEARCH_TAG_WEIGHT * float64(strings.Count(tags, term))
+		score += SEARCH_BODY_WEIGHT * float64(strings.Count(body, term))
+	}
+
+	return score
+}
+
+// SortSearchResults orders results by score, then by the date the post was
+// added, newest first.
+func SortSearchResults(results []*SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+
+		return results[i].Post.DateAdded.After(results[j].Post.DateAdded)
+	})
+}
+
+// PageSearchResults returns the results on the page, the same way GetBlogPosts
+// pages through blog posts.
+func PageSearchResults(results []*SearchResult, page int, pagination int) []*SearchResult {
+	start := (page - 1) * pagination
+	if start >= len(results) {
+		return []*SearchResult{}
+	}
+
+	end := start + pagination
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return results[start:end]
+}
+
+// MakeSearchSnippet returns the part of the body around the first match of any
+// of the terms, with every match highlighted. The start of the body is used if
+// none of the terms are in the body.
+func MakeSearchSnippet(body string, terms []string) string {
+	runes := []rune(body)
+	lower := []rune(strings.ToLower(body))
+
+	// Lower casing can change the length of some strings, in which case the
+	// positions can't be mapped back to the body.
+	if len(lower) != len(runes) {
+		lower = runes
+	}
+
+	first := -1
+	for _, term := range terms {
+		idx := indexRunes(lower, []rune(term), 0)
+
+		if idx >= 0 && (first < 0 || idx < first) {
+			first = idx
+		}
+	}
+
+	start := 0
+	if first > SNIPPET_LENGTH/2 {
+		start = first - SNIPPET_LENGTH/2
+	}
+
+	end := start + SNIPPET_LENGTH
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var snippet strings.Builder
+
+	if start > 0 {
+		snippet.WriteString("…")
+	}
+
+	pos := start
+	for pos < end {
+		matchLength := 0
+
+		for _, term := range terms {
+			termRunes := []rune(term)
+
+			if len(termRunes) > matchLength && hasRunesAt(lower[:end], termRunes, pos) {
+				matchLength = len(termRunes)
+			}
+		}
+
+		if matchLength == 0 {
+			snippet.WriteString(html.EscapeString(string(runes[pos])))
+			pos++
+			continue
+		}
+
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(string(runes[pos : pos+matchLength])))
+		snippet.WriteString("</mark>")
+		pos += matchLength
+	}
+
+	if end < len(runes) {
+		snippet.WriteString("…")
+	}
+
+	return snippet.String()
+}
+
+// hasRunesAt returns whether term is found in s starting at pos
+func hasRunesAt(s []rune, term []rune, pos int) bool {
+	if len(term) == 0 || pos+len(term) > len(s) {
+		return false
+	}
+
+	for j := range term {
+		if s[pos+j] != term[j] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// indexRunes returns the index of the first match of term in s at or after
+// from, or -1 if there isn't one.
+func indexRunes(s []rune, term []rune, from int) int {
+	for i := from; i+len(term) <= len(s); i++ {
+		if hasRunesAt(s, term, i) {
+			return i
+		}
+	}
+
+	return -1
+}
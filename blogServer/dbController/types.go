@@ -2,8 +2,59 @@ package dbController
 
 import (
 	"time"
+
+	"methompson.com/blog-microservice/blogServer/user"
 )
 
+// The statuses a blog post can have. Only published and scheduled posts are
+// shown to the public, and only once their publishAt time has passed.
+const BLOG_STATUS_DRAFT = "draft"
+const BLOG_STATUS_SCHEDULED = "scheduled"
+const BLOG_STATUS_PUBLISHED = "published"
+const BLOG_STATUS_ARCHIVED = "archived"
+
+func IsValidBlogStatus(status string) bool {
+	switch status {
+	case BLOG_STATUS_DRAFT, BLOG_STATUS_SCHEDULED, BLOG_STATUS_PUBLISHED, BLOG_STATUS_ARCHIVED:
+		return true
+	}
+
+	return false
+}
+
+// IsPublicBlogStatus returns whether posts with the status can be shown to the
+// public once their publishAt time has passed.
+func IsPublicBlogStatus(status string) bool {
+	return status == BLOG_STATUS_PUBLISHED || status == BLOG_STATUS_SCHEDULED
+}
+
+// A BlogCursor is a position in the list of blog posts, which is sorted by
+// dateAdded, newest first, then by id. GetBlogPostsByCursor returns the posts
+// after the position, or the posts before it if Previous is true.
+type BlogCursor struct {
+	DateAdded time.Time
+	Id        string
+	Previous  bool
+}
+
+// A BlogPostFilter limits the blog posts counted by CountBlogPosts to the ones
+// that match every field that is set. Posts added at DateAddedEnd are not
+// included.
+type BlogPostFilter struct {
+	Tag            string
+	AuthorId       string
+	DateAddedStart *time.Time
+	DateAddedEnd   *time.Time
+}
+
+// ReverseBlogDocuments reverses the order of the posts in place. Backends that
+// page back from a cursor read the posts in reverse order.
+func ReverseBlogDocuments(posts []*BlogDocument) {
+	for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+		posts[i], posts[j] = posts[j], posts[i]
+	}
+}
+
 type UserDataDocument struct {
 	Id    string
 	UID   string
@@ -16,11 +67,26 @@ type AddBlogDocument struct {
 	Title          string
 	Slug           string
 	Body           string
+	BodyHtml       string
 	Tags           *[]string
 	AuthorId       string
 	DateAdded      time.Time
 	UpdateAuthorId *string
 	DateUpdated    *time.Time
+	Status         *string
+	PublishAt      *time.Time
+	Summary        BlogSummary
+}
+
+// A BlogSummary is computed from the body of a blog post when it's written,
+// so that lists of posts can show a teaser without the body. CustomExcerpt is
+// true when the excerpt was written by the author instead of taken from the
+// start of the body.
+type BlogSummary struct {
+	Excerpt            string
+	CustomExcerpt      bool
+	WordCount          int
+	ReadingTimeMinutes int
 }
 
 type BlogDocument struct {
@@ -28,6 +94,7 @@ type BlogDocument struct {
 	Title          string
 	Slug           string
 	Body           string
+	BodyHtml       string
 	Tags           []string
 	Author         string
 	AuthorId       string
@@ -35,6 +102,17 @@ type BlogDocument struct {
 	UpdateAuthor   string
 	UpdateAuthorId string
 	DateUpdated    time.Time
+	Status         string
+	PublishAt      time.Time
+	DeletedAt      *time.Time
+	DeletedBy      string
+	Summary        BlogSummary
+}
+
+// IsPublic returns whether the public is allowed to see the blog post at the
+// time now.
+func (bd *BlogDocument) IsPublic(now time.Time) bool {
+	return bd.DeletedAt == nil && IsPublicBlogStatus(bd.Status) && !bd.PublishAt.After(now)
 }
 
 func (bd *BlogDocument) GetMap() *map[string]interface{} {
@@ -45,12 +123,25 @@ func (bd *BlogDocument) GetMap() *map[string]interface{} {
 	m["title"] = bd.Title
 	m["slug"] = bd.Slug
 	m["body"] = bd.Body
+	m["bodyHtml"] = bd.BodyHtml
 	m["author"] = bd.Author
 	m["authorId"] = bd.AuthorId
 	m["dateAdded"] = bd.DateAdded.Unix()
 	m["updateAuthor"] = bd.UpdateAuthor
 	m["updateAuthorId"] = bd.UpdateAuthorId
 	m["dateUpdated"] = bd.DateUpdated.Unix()
+	m["status"] = bd.Status
+	m["publishAt"] = bd.PublishAt.Unix()
+	m["excerpt"] = bd.Summary.Excerpt
+	m["customExcerpt"] = bd.Summary.CustomExcerpt
+	m["wordCount"] = bd.Summary.WordCount
+	m["readingTimeMinutes"] = bd.Summary.ReadingTimeMinutes
+
+	// Only posts in the trash have the deleted fields
+	if bd.DeletedAt != nil {
+		m["deletedAt"] = bd.DeletedAt.Unix()
+		m["deletedBy"] = bd.DeletedBy
+	}
 
 	if bd.Tags != nil {
 		m["tags"] = bd.Tags
@@ -66,13 +157,170 @@ type EditBlogDocument struct {
 	Title          *string
 	Slug           *string
 	Body           *string
+	BodyHtml       *string
 	Tags           *[]string
 	AuthorId       *string
 	DateAdded      *time.Time
 	UpdateAuthorId *string
 	DateUpdated    *time.Time
+	Status         *string
+	PublishAt      *time.Time
+	Summary        *BlogSummary
 }
 
+// DeleteBlogDocument moves a blog post to the trash. DeletedBy is the uid of
+// the user that deleted the post.
 type DeleteBlogDocument struct {
-	Id string
+	Id        string
+	DeletedBy string
+}
+
+// EditUserDocument changes the information of the user with the Uid. Nil
+// fields are left as they are.
+type EditUserDocument struct {
+	Uid    string
+	Name   *string
+	Email  *string
+	Active *bool
+	Role   *user.UserType
+}
+
+// AddApiKeyDocument is an API key for a machine client. Only the Hash of the
+// key is stored. The Prefix is the start of the key, kept so that admins can
+// tell keys apart. A nil ExpiresAt means the key doesn't expire.
+type AddApiKeyDocument struct {
+	Name      string
+	OwnerId   string
+	Prefix    string
+	Hash      string
+	Scopes    []string
+	DateAdded time.Time
+	ExpiresAt *time.Time
+}
+
+type ApiKeyDocument struct {
+	Id        string
+	Name      string
+	OwnerId   string
+	Prefix    string
+	Hash      string
+	Scopes    []string
+	DateAdded time.Time
+	ExpiresAt *time.Time
+	LastUsed  *time.Time
+	RevokedAt *time.Time
+}
+
+// HasScope returns whether the key has the scope
+func (ak *ApiKeyDocument) HasScope(scope string) bool {
+	for _, keyScope := range ak.Scopes {
+		if keyScope == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsUsable returns whether the key can be used at the time now
+func (ak *ApiKeyDocument) IsUsable(now time.Time) bool {
+	return ak.RevokedAt == nil && (ak.ExpiresAt == nil || ak.ExpiresAt.After(now))
+}
+
+// GetMap leaves out the hash, which is only used to find the key
+func (ak *ApiKeyDocument) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["id"] = ak.Id
+	m["name"] = ak.Name
+	m["ownerId"] = ak.OwnerId
+	m["prefix"] = ak.Prefix
+	m["dateAdded"] = ak.DateAdded.Unix()
+
+	if ak.Scopes != nil {
+		m["scopes"] = ak.Scopes
+	} else {
+		m["scopes"] = make([]string, 0)
+	}
+
+	// The optional dates are null when they aren't set
+	optionalDates := map[string]*time.Time{
+		"expiresAt": ak.ExpiresAt,
+		"lastUsed":  ak.LastUsed,
+		"revokedAt": ak.RevokedAt,
+	}
+
+	for key, date := range optionalDates {
+		if date != nil {
+			m[key] = date.Unix()
+		} else {
+			m[key] = nil
+		}
+	}
+
+	return &m
+}
+
+// A BlogAuthor is the public profile of a user who writes blog posts
+type BlogAuthor struct {
+	UID  string
+	Name string
+}
+
+func (ba *BlogAuthor) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["uid"] = ba.UID
+	m["name"] = ba.Name
+
+	return &m
+}
+
+// An ArchiveCount is the number of blog posts added in a month. Months are
+// calendar months in UTC.
+type ArchiveCount struct {
+	Year  int
+	Month int
+	Count int
+}
+
+func (ac *ArchiveCount) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["year"] = ac.Year
+	m["month"] = ac.Month
+	m["count"] = ac.Count
+
+	return &m
+}
+
+// A TagCount is a tag and the number of blog posts that have it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+func (tc *TagCount) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["tag"] = tc.Tag
+	m["count"] = tc.Count
+
+	return &m
+}
+
+// A SitemapEntry is the part of a blog post that a sitemap lists
+type SitemapEntry struct {
+	Slug        string
+	DateAdded   time.Time
+	DateUpdated time.Time
+}
+
+// LastModified returns the last time the post changed
+func (se *SitemapEntry) LastModified() time.Time {
+	if se.DateUpdated.After(se.DateAdded) {
+		return se.DateUpdated
+	}
+
+	return se.DateAdded
 }
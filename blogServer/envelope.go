@@ -0,0 +1,284 @@
+package blogServer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"methompson.com/blog-microservice/blogServer/constants"
+)
+
+// API_VERSION_1 responds to list endpoints with the shape they had before the
+// page envelope, which is usually a bare array.
+const API_VERSION_1 = 1
+
+// API_VERSION_2 wraps the responses of list endpoints in a PageEnvelope
+const API_VERSION_2 = 2
+
+// DEFAULT_API_VERSION is used when neither the request nor the API_VERSION
+// environment variable ask for a version. It stays at version 1 so that
+// existing clients keep getting bare arrays until they opt in to version 2.
+const DEFAULT_API_VERSION = API_VERSION_1
+
+// Requests ask for a version with the header, or with the query parameter
+// where a header is awkward to set.
+const API_VERSION_HEADER = "X-API-Version"
+const API_VERSION_QUERY = "v"
+
+func parseApiVersion(value string) (int, error) {
+	version, versionErr := strconv.Atoi(value)
+
+	if versionErr != nil || (version != API_VERSION_1 && version != API_VERSION_2) {
+		return 0, errors.New("unsupported api version")
+	}
+
+	return version, nil
+}
+
+// getDefaultApiVersion returns the version used by requests that don't ask for
+// one.
+func getDefaultApiVersion() (int, error) {
+	value := os.Getenv(constants.API_VERSION)
+
+	if len(value) == 0 {
+		return DEFAULT_API_VERSION, nil
+	}
+
+	version, versionErr := parseApiVersion(value)
+
+	if versionErr != nil {
+		return 0, errors.New("API_VERSION must be 1 or 2")
+	}
+
+	return version, nil
+}
+
+// getApiVersion returns the response version the request asked for. The
+// request is aborted if the version isn't supported.
+func (srv *BlogServer) getApiVersion(ctx *gin.Context) (int, error) {
+	value := ctx.GetHeader(API_VERSION_HEADER)
+
+	if len(value) == 0 {
+		value = ctx.Query(API_VERSION_QUERY)
+	}
+
+	// API_VERSION is checked when the server starts
+	if len(value) == 0 {
+		version, versionErr := getDefaultApiVersion()
+
+		if versionErr != nil {
+			return DEFAULT_API_VERSION, nil
+		}
+
+		return version, nil
+	}
+
+	version, versionErr := parseApiVersion(value)
+
+	if versionErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": versionErr.Error(),
+			},
+		)
+	}
+
+	return version, versionErr
+}
+
+// A PageEnvelope is a page of a list endpoint along with what a client needs
+// to page through the rest of the list. Pages found with a cursor have a Page
+// of 0. Meta is merged into the top level of the response.
+type PageEnvelope struct {
+	Data       []map[string]interface{}
+	Page       int
+	Pagination int
+	Total      int
+	HasNext    bool
+	HasPrev    bool
+	Links      PageLinks
+	Meta       map[string]interface{}
+}
+
+// PageLinks are the urls of the pages around a page. Empty links don't exist.
+type PageLinks struct {
+	Self  string
+	First string
+	Last  string
+	Prev  string
+	Next  string
+}
+
+// NewPageEnvelope makes the envelope of a numbered page. pageUrl returns the
+// url of a page number.
+func NewPageEnvelope(data []map[string]interface{}, page int, pagination int, total int, pageUrl func(page int) string) *PageEnvelope {
+	envelope := PageEnvelope{
+		Data:       data,
+		Page:       page,
+		Pagination: pagination,
+		Total:      total,
+	}
+
+	totalPages := envelope.TotalPages()
+
+	envelope.HasNext = page < totalPages
+	envelope.HasPrev = page > 1
+
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	envelope.Links = PageLinks{
+		Self:  pageUrl(page),
+		First: pageUrl(1),
+		Last:  pageUrl(lastPage),
+	}
+
+	if envelope.HasNext {
+		envelope.Links.Next = pageUrl(page + 1)
+	}
+
+	if envelope.HasPrev {
+		envelope.Links.Prev = pageUrl(page - 1)
+	}
+
+	return &envelope
+}
+
+// NewCursorPageEnvelope makes the envelope of a page found with a cursor.
+// cursorUrl returns the url of the page next to a cursor token, or of the
+// first page for an empty token. There is no link to the last page.
+func NewCursorPageEnvelope(cursorPage *BlogCursorPage, pagination int, self string, cursorUrl func(token string) string) *PageEnvelope {
+	data := make([]map[string]interface{}, 0)
+	for _, post := range cursorPage.Posts {
+		data = append(data, *post.GetMap())
+	}
+
+	envelope := PageEnvelope{
+		Data:       data,
+		Pagination: pagination,
+		Total:      cursorPage.Total,
+		HasNext:    cursorPage.Next != nil,
+		HasPrev:    cursorPage.Prev != nil,
+		Links: PageLinks{
+			Self:  self,
+			First: cursorUrl(""),
+		},
+	}
+
+	if cursorPage.Next != nil {
+		envelope.Links.Next = cursorUrl(EncodeBlogCursor(cursorPage.Next))
+	}
+
+	if cursorPage.Prev != nil {
+		envelope.Links.Prev = cursorUrl(EncodeBlogCursor(cursorPage.Prev))
+	}
+
+	return &envelope
+}
+
+func (pe *PageEnvelope) TotalPages() int {
+	if pe.Pagination < 1 {
+		return 0
+	}
+
+	return (pe.Total + pe.Pagination - 1) / pe.Pagination
+}
+
+func (pe *PageEnvelope) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	for key, value := range pe.Meta {
+		m[key] = value
+	}
+
+	m["data"] = pe.Data
+	m["page"] = nil
+	m["pagination"] = pe.Pagination
+	m["total"] = pe.Total
+	m["totalPages"] = pe.TotalPages()
+	m["hasNext"] = pe.HasNext
+	m["hasPrev"] = pe.HasPrev
+	m["links"] = pe.Links.GetMap()
+
+	if pe.Page > 0 {
+		m["page"] = pe.Page
+	}
+
+	return &m
+}
+
+func (pl *PageLinks) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	links := map[string]string{
+		"self":  pl.Self,
+		"first": pl.First,
+		"last":  pl.Last,
+		"prev":  pl.Prev,
+		"next":  pl.Next,
+	}
+
+	for key, link := range links {
+		m[key] = nil
+
+		if len(link) > 0 {
+			m[key] = link
+		}
+	}
+
+	return &m
+}
+
+func urlWithQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+
+	return path + "?" + query.Encode()
+}
+
+// pathPageUrl returns the urls of pages at basePath/page/N. The query of the
+// request is kept.
+func pathPageUrl(ctx *gin.Context, basePath string) func(page int) string {
+	query := ctx.Request.URL.Query()
+
+	return func(page int) string {
+		return urlWithQuery(fmt.Sprintf("%s/page/%d", basePath, page), query)
+	}
+}
+
+// queryParamUrl returns the urls of the request path with the key query
+// parameter set to a value. An empty value removes the parameter. The rest of
+// the query of the request is kept.
+func queryParamUrl(ctx *gin.Context, key string) func(value string) string {
+	path := ctx.Request.URL.Path
+
+	return func(value string) string {
+		query := ctx.Request.URL.Query()
+
+		if len(value) == 0 {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
+
+		return urlWithQuery(path, query)
+	}
+}
+
+// queryPageUrl returns the urls of pages chosen with the page query parameter
+func queryPageUrl(ctx *gin.Context) func(page int) string {
+	paramUrl := queryParamUrl(ctx, "page")
+
+	return func(page int) string {
+		return paramUrl(strconv.Itoa(page))
+	}
+}
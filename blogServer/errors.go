@@ -4,3 +4,31 @@ type InputError struct{ ErrMsg string }
 
 func (err InputError) Error() string { return err.ErrMsg }
 func NewInputError(msg string) error { return InputError{msg} }
+
+// An UnauthorizedError means the caller isn't a user with the role of their
+// token.
+type UnauthorizedError struct{ ErrMsg string }
+
+func (err UnauthorizedError) Error() string { return err.ErrMsg }
+func NewUnauthorizedError(msg string) error { return UnauthorizedError{msg} }
+
+// A DeactivatedUserError means the caller is a user whose account was
+// deactivated.
+type DeactivatedUserError struct{ ErrMsg string }
+
+func (err DeactivatedUserError) Error() string { return err.ErrMsg }
+func NewDeactivatedUserError(msg string) error { return DeactivatedUserError{msg} }
+
+// An InvalidApiKeyError means the API key doesn't exist, was revoked or has
+// expired.
+type InvalidApiKeyError struct{ ErrMsg string }
+
+func (err InvalidApiKeyError) Error() string { return err.ErrMsg }
+func NewInvalidApiKeyError(msg string) error { return InvalidApiKeyError{msg} }
+
+// A MissingScopeError means the API key is valid, but doesn't have the scope
+// the route needs.
+type MissingScopeError struct{ ErrMsg string }
+
+func (err MissingScopeError) Error() string { return err.ErrMsg }
+func NewMissingScopeError(msg string) error { return MissingScopeError{msg} }
@@ -0,0 +1,483 @@
+package blogServer
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"methompson.com/blog-microservice/blogServer/constants"
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// FEED_LENGTH is the number of the latest posts in a feed
+const FEED_LENGTH = 20
+
+// DEFAULT_FEED_TITLE is used when FEED_TITLE isn't set
+const DEFAULT_FEED_TITLE = "Blog"
+
+// The formats feeds are served in. Each is also the extension of the feed urls.
+const FEED_FORMAT_RSS = "rss"
+const FEED_FORMAT_ATOM = "atom"
+const FEED_FORMAT_JSON = "json"
+
+var FEED_FORMATS = []string{FEED_FORMAT_RSS, FEED_FORMAT_ATOM, FEED_FORMAT_JSON}
+
+// A Feed is a list of the latest published posts along with the urls that
+// readers need to follow the feed.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedUrl     string
+	Updated     time.Time
+	Posts       []*dbController.BlogDocument
+	PostUrl     func(slug string) string
+}
+
+// postUpdated returns when the post last changed. Posts that were never edited
+// were last changed when they were added.
+func postUpdated(post *dbController.BlogDocument) time.Time {
+	if post.DateUpdated.After(post.DateAdded) {
+		return post.DateUpdated
+	}
+
+	return post.DateAdded
+}
+
+// makeFeed makes a feed of the posts. The subtitle is added to the title of
+// the feed, and is empty for the feed of every post. link is the public page
+// the feed follows.
+func (srv *BlogServer) makeFeed(ctx *gin.Context, posts []*dbController.BlogDocument, subtitle string, link string) *Feed {
+	title := os.Getenv(constants.FEED_TITLE)
+	if len(title) == 0 {
+		title = DEFAULT_FEED_TITLE
+	}
+
+	description := os.Getenv(constants.FEED_DESCRIPTION)
+
+	if len(subtitle) > 0 {
+		title = title + " - " + subtitle
+		description = subtitle
+	}
+
+	if len(description) == 0 {
+		description = title
+	}
+
+	feed := Feed{
+		Title:       title,
+		Description: description,
+		Link:        link,
+		FeedUrl:     srv.PublicUrl(ctx.Request.URL.Path),
+		Posts:       posts,
+		PostUrl: func(slug string) string {
+			return srv.PostUrl(slug)
+		},
+	}
+
+	// A scheduled post changes the feed when it's published
+	for _, post := range posts {
+		for _, changed := range []time.Time{postUpdated(post), post.PublishAt} {
+			if changed.After(feed.Updated) {
+				feed.Updated = changed
+			}
+		}
+	}
+
+	return &feed
+}
+
+// Render returns the feed in the format along with its content type
+func (f *Feed) Render(format string) ([]byte, string, error) {
+	switch format {
+	case FEED_FORMAT_RSS:
+		body, err := f.renderRss()
+		return body, "application/rss+xml; charset=utf-8", err
+	case FEED_FORMAT_ATOM:
+		body, err := f.renderAtom()
+		return body, "application/atom+xml; charset=utf-8", err
+	case FEED_FORMAT_JSON:
+		body, err := f.renderJson()
+		return body, "application/feed+json; charset=utf-8", err
+	}
+
+	return nil, "", fmt.Errorf("unknown feed format %s", format)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNs  string     `xml:"xmlns:atom,attr"`
+	DcNs    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        rssGuid  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (f *Feed) renderRss() ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		AtomLink: rssLink{
+			Href: f.FeedUrl,
+			Rel:  "self",
+			Type: "application/rss+xml",
+		},
+		Items: make([]rssItem, 0),
+	}
+
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, post := range f.Posts {
+		postUrl := f.PostUrl(post.Slug)
+
+		channel.Items = append(channel.Items, rssItem{
+			Title:       post.Title,
+			Link:        postUrl,
+			Guid:        rssGuid{IsPermaLink: true, Value: postUrl},
+			PubDate:     post.DateAdded.UTC().Format(time.RFC1123Z),
+			Creator:     post.Author,
+			Categories:  post.Tags,
+			Description: post.BodyHtml,
+		})
+	}
+
+	return marshalXml(rssFeed{
+		Version: "2.0",
+		AtomNs:  "http://www.w3.org/2005/Atom",
+		DcNs:    "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	})
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Id       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Id         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+func (f *Feed) renderAtom() ([]byte, error) {
+	feed := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		Id:       f.FeedUrl,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedUrl, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate"},
+		},
+		// Entries without an author fall back to the author of the feed
+		Author:  atomAuthor{Name: f.Title},
+		Entries: make([]atomEntry, 0),
+	}
+
+	for _, post := range f.Posts {
+		postUrl := f.PostUrl(post.Slug)
+
+		entry := atomEntry{
+			Id:         postUrl,
+			Title:      post.Title,
+			Link:       atomLink{Href: postUrl, Rel: "alternate"},
+			Published:  post.DateAdded.UTC().Format(time.RFC3339),
+			Updated:    postUpdated(post).UTC().Format(time.RFC3339),
+			Categories: make([]atomCategory, 0),
+			Content:    atomContent{Type: "html", Value: post.BodyHtml},
+		}
+
+		if len(post.Author) > 0 {
+			entry.Author = &atomAuthor{Name: post.Author}
+		}
+
+		for _, tag := range post.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalXml(feed)
+}
+
+func marshalXml(feed interface{}) ([]byte, error) {
+	body, marshalErr := xml.MarshalIndent(feed, "", "  ")
+
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageUrl string         `json:"home_page_url"`
+	FeedUrl     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	Id            string           `json:"id"`
+	Url           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHtml   string           `json:"content_html"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func (f *Feed) renderJson() ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageUrl: f.Link,
+		FeedUrl:     f.FeedUrl,
+		Description: f.Description,
+		Items:       make([]jsonFeedItem, 0),
+	}
+
+	for _, post := range f.Posts {
+		postUrl := f.PostUrl(post.Slug)
+
+		item := jsonFeedItem{
+			Id:            postUrl,
+			Url:           postUrl,
+			Title:         post.Title,
+			ContentHtml:   post.BodyHtml,
+			DatePublished: post.DateAdded.UTC().Format(time.RFC3339),
+			DateModified:  postUpdated(post).UTC().Format(time.RFC3339),
+			Tags:          post.Tags,
+		}
+
+		if len(post.Author) > 0 {
+			item.Authors = []jsonFeedAuthor{{Name: post.Author}}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return json.MarshalIndent(feed, "", "  ")
+}
+
+// isNotModified returns whether the client's copy of a response with the etag
+// and last modified time is still current. If-None-Match takes precedence over
+// If-Modified-Since.
+func isNotModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ifModifiedSince, parseErr := http.ParseTime(req.Header.Get("If-Modified-Since"))
+
+	if parseErr != nil {
+		return false
+	}
+
+	// HTTP dates don't have fractions of a second
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// writeFeed responds with the feed in the format, or with 304 Not Modified if
+// the client already has the current feed.
+func (srv *BlogServer) writeFeed(ctx *gin.Context, format string, feed *Feed) {
+	body, contentType, renderErr := feed.Render(format)
+
+	if renderErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "error rendering feed"},
+		)
+		return
+	}
+
+	writeConditional(ctx, body, contentType, feed.Updated)
+}
+
+// writeConditional responds with the body, or with 304 Not Modified if the
+// client already has it. The ETag is a hash of the body. A zero lastModified
+// leaves out the Last-Modified header.
+func writeConditional(ctx *gin.Context, body []byte, contentType string, lastModified time.Time) {
+	etag := fmt.Sprintf("\"%x\"", sha1.Sum(body))
+
+	ctx.Header("ETag", etag)
+
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if isNotModified(ctx.Request, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, body)
+}
+
+// GetFeed returns the handler of the feed of the latest published posts
+func (srv *BlogServer) GetFeed(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		posts, getPostsErr := srv.BlogController.GetBlogPosts(1, FEED_LENGTH, false)
+
+		if getPostsErr != nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving blog posts"},
+			)
+			return
+		}
+
+		srv.writeFeed(ctx, format, srv.makeFeed(ctx, posts, "", srv.PublicUrl("/")))
+	}
+}
+
+// GetTagFeed returns the handler of the feed of the latest published posts
+// with a tag
+func (srv *BlogServer) GetTagFeed(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tag := ctx.Param("tag")
+
+		posts, getPostsErr := srv.BlogController.GetBlogPostsByTag(tag, 1, FEED_LENGTH, false)
+
+		if getPostsErr != nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving blog posts"},
+			)
+			return
+		}
+
+		srv.writeFeed(ctx, format, srv.makeFeed(ctx, posts, "Posts tagged "+tag, srv.TagUrl(tag)))
+	}
+}
+
+// GetAuthorFeed returns the handler of the feed of the latest published posts
+// by an author
+func (srv *BlogServer) GetAuthorFeed(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uid := ctx.Param("uid")
+
+		author, getAuthorErr := srv.BlogController.GetBlogAuthor(uid)
+
+		if getAuthorErr != nil {
+			switch getAuthorErr.(type) {
+			case dbController.NoResultsError:
+				ctx.AbortWithStatusJSON(
+					http.StatusNotFound,
+					gin.H{"error": "author does not exist"},
+				)
+			default:
+				ctx.AbortWithStatusJSON(
+					http.StatusBadRequest,
+					gin.H{"error": "error retrieving author"},
+				)
+			}
+
+			return
+		}
+
+		posts, getPostsErr := srv.BlogController.GetBlogPostsByAuthor(uid, 1, FEED_LENGTH, false)
+
+		if getPostsErr != nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving blog posts"},
+			)
+			return
+		}
+
+		srv.writeFeed(ctx, format, srv.makeFeed(ctx, posts, "Posts by "+author.Name, srv.AuthorUrl(uid)))
+	}
+}
@@ -0,0 +1,49 @@
+package blogServer
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The fields query parameter of the blog post lists. Summaries leave out the
+// body of each post, for index pages that only show the excerpt.
+const (
+	POST_FIELDS_FULL    = "full"
+	POST_FIELDS_SUMMARY = "summary"
+)
+
+var SUMMARY_OMITTED_FIELDS = []string{"body", "bodyHtml"}
+
+// getFieldsQuery returns the fields query parameter, or full if there isn't
+// one. The request is aborted if the value isn't one of the POST_FIELDS.
+func (srv *BlogServer) getFieldsQuery(ctx *gin.Context) (string, error) {
+	fields := ctx.DefaultQuery("fields", POST_FIELDS_FULL)
+
+	if fields != POST_FIELDS_FULL && fields != POST_FIELDS_SUMMARY {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid fields",
+			},
+		)
+
+		return "", NewInputError("invalid fields")
+	}
+
+	return fields, nil
+}
+
+// selectPostFields removes the fields that aren't part of the fields option
+// from the maps of blog posts.
+func selectPostFields(posts []map[string]interface{}, fields string) {
+	if fields != POST_FIELDS_SUMMARY {
+		return
+	}
+
+	for _, post := range posts {
+		for _, field := range SUMMARY_OMITTED_FIELDS {
+			delete(post, field)
+		}
+	}
+}
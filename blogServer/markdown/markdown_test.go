@@ -0,0 +1,75 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, body string) string {
+	t.Helper()
+
+	html, renderErr := Render(body)
+
+	if renderErr != nil {
+		t.Fatalf("Render: %v", renderErr)
+	}
+
+	return html
+}
+
+func TestRenderRemovesUnsafeHtml(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		forbidden []string
+	}{
+		{"script tag", "Hello <script>alert(1)</script> world", []string{"<script", "alert(1)"}},
+		{"script block", "<script>\nalert(1)\n</script>", []string{"<script", "alert(1)"}},
+		{"javascript link", "[click](javascript:alert(1))", []string{"javascript:"}},
+		{"javascript html link", `<a href="javascript:alert(1)">click</a>`, []string{"javascript:"}},
+		{"javascript autolink", "<javascript:alert(1)>", []string{`href="javascript:`}},
+		{"onerror attribute", `<img src="x.png" onerror="alert(1)">`, []string{"onerror", "alert(1)"}},
+		{"onclick attribute", `<p onclick="alert(1)">text</p>`, []string{"onclick"}},
+		{"style attribute", `<p style="position:fixed">text</p>`, []string{"style="}},
+		{"iframe", `<iframe src="https://example.com"></iframe>`, []string{"<iframe"}},
+		{"unknown code class", "<code class=\"evil\">x</code>", []string{`class="evil"`}},
+		{"class on other elements", `<div class="language-go">x</div>`, []string{"class="}},
+	}
+
+	for _, test := range tests {
+		html := render(t, test.body)
+
+		for _, forbidden := range test.forbidden {
+			if strings.Contains(html, forbidden) {
+				t.Errorf("%s: %q kept %q", test.name, html, forbidden)
+			}
+		}
+	}
+}
+
+func TestRenderKeepsAllowedHtml(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"code language class", "```go\nfmt.Println(1)\n```", []string{`<code class="language-go">`}},
+		{"html code language class", `<code class="language-c++">x</code>`, []string{`<code class="language-c++">`}},
+		{"links", "[site](https://example.com)", []string{`href="https://example.com"`}},
+		{"images", "![alt](https://example.com/a.png)", []string{`src="https://example.com/a.png"`, `alt="alt"`}},
+		{"task lists", "- [x] done", []string{`type="checkbox"`, "checked"}},
+		{"table alignment", "| a |\n|:-:|\n| b |", []string{`align="center"`}},
+		{"footnotes", "Text[^1]\n\n[^1]: Note", []string{`class="footnote-ref"`, `class="footnotes"`}},
+		{"heading ids", "# Title", []string{`<h1 id="title">`}},
+	}
+
+	for _, test := range tests {
+		html := render(t, test.body)
+
+		for _, expected := range test.expected {
+			if !strings.Contains(html, expected) {
+				t.Errorf("%s: %q is missing %q", test.name, html, expected)
+			}
+		}
+	}
+}
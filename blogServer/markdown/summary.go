@@ -0,0 +1,73 @@
+package markdown
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// EXCERPT_LENGTH is the most characters a generated excerpt has, not counting
+// the ellipsis added when the text is cut short.
+const EXCERPT_LENGTH = 200
+
+// WORDS_PER_MINUTE is the reading speed used for the reading time of a post
+const WORDS_PER_MINUTE = 200
+
+// textPolicy removes every tag. The space keeps the words in neighbouring
+// blocks apart.
+var textPolicy = bluemonday.StrictPolicy().AddSpaceWhenStrippingTag(true)
+
+// A Summary is what the index pages show about a blog post in place of the
+// full body.
+type Summary struct {
+	Excerpt            string
+	WordCount          int
+	ReadingTimeMinutes int
+}
+
+// PlainText returns the words of the rendered HTML of a blog post, separated
+// by single spaces.
+func PlainText(bodyHtml string) string {
+	text := html.UnescapeString(textPolicy.Sanitize(bodyHtml))
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Excerpt returns the start of the text, cut at the end of a word so that it
+// isn't longer than EXCERPT_LENGTH characters.
+func Excerpt(text string) string {
+	if utf8.RuneCountInString(text) <= EXCERPT_LENGTH {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:EXCERPT_LENGTH+1])
+
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	} else {
+		cut = string(runes[:EXCERPT_LENGTH])
+	}
+
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
+
+// ReadingTime returns the minutes it takes to read the words, rounded up.
+// Empty posts take no time to read.
+func ReadingTime(wordCount int) int {
+	return (wordCount + WORDS_PER_MINUTE - 1) / WORDS_PER_MINUTE
+}
+
+// Summarize returns the summary of a blog post from its rendered HTML
+func Summarize(bodyHtml string) Summary {
+	text := PlainText(bodyHtml)
+	wordCount := len(strings.Fields(text))
+
+	return Summary{
+		Excerpt:            Excerpt(text),
+		WordCount:          wordCount,
+		ReadingTimeMinutes: ReadingTime(wordCount),
+	}
+}
@@ -0,0 +1,20 @@
+package memoryDbController
+
+import (
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// The MakeMemoryDbController creates an empty MemoryDbController. Nothing
+// stored in the controller outlives the process, so it's meant for local
+// development and testing rather than production use.
+func MakeMemoryDbController() *MemoryDbController {
+	return &MemoryDbController{
+		blogPosts:   make([]*blogRecord, 0),
+		users:       make(map[string]*user.UserInformation),
+		revisions:   make(map[string][]*dbController.BlogRevision),
+		requestLogs: make([]logging.RequestLogData, 0),
+		infoLogs:    make([]logging.InfoLogData, 0),
+	}
+}
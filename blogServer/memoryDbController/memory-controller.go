@@ -0,0 +1,1273 @@
+package memoryDbController
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// MAX_LOG_ENTRIES mirrors the capped logging collection used by the
+// MongoDbController. Once the limit is reached, the oldest entries are dropped.
+const MAX_LOG_ENTRIES = 1000
+
+// blogRecord is the internal representation of a blog post. Timestamps are
+// truncated to the second to match the precision of the MongoDB timestamps.
+type blogRecord struct {
+	Id             string
+	Title          string
+	Slug           string
+	Body           string
+	BodyHtml       string
+	Summary        dbController.BlogSummary
+	Tags           []string
+	AuthorId       string
+	DateAdded      time.Time
+	UpdateAuthorId string
+	DateUpdated    time.Time
+	Status         string
+	PublishAt      time.Time
+	DeletedAt      *time.Time
+	DeletedBy      string
+	OldSlugs       []string
+}
+
+type MemoryDbController struct {
+	mutex       sync.RWMutex
+	blogPosts   []*blogRecord
+	users       map[string]*user.UserInformation
+	revisions   map[string][]*dbController.BlogRevision
+	apiKeys     []*dbController.ApiKeyDocument
+	requestLogs []logging.RequestLogData
+	infoLogs    []logging.InfoLogData
+}
+
+// toTimestamp drops everything below a second from the time value, the same
+// way the MongoDbController does when it stores a primitive.Timestamp.
+func toTimestamp(t time.Time) time.Time {
+	return time.Unix(t.Unix(), 0)
+}
+
+func copyTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	output := make([]string, len(tags))
+	copy(output, tags)
+
+	return output
+}
+
+// getUserName returns the name of the user with the provided uid, or an empty
+// string if the user doesn't exist. The mutex must be held by the caller.
+func (mc *MemoryDbController) getUserName(uid string) string {
+	info, ok := mc.users[uid]
+
+	if !ok {
+		return ""
+	}
+
+	return info.Name
+}
+
+// getBlogDocument converts a blogRecord into a BlogDocument, joining the
+// author names from the users map. The mutex must be held by the caller.
+func (mc *MemoryDbController) getBlogDocument(record *blogRecord) *dbController.BlogDocument {
+	doc := dbController.BlogDocument{
+		Id:             record.Id,
+		Title:          record.Title,
+		Slug:           record.Slug,
+		Body:           record.Body,
+		BodyHtml:       record.BodyHtml,
+		Summary:        record.Summary,
+		Tags:           copyTags(record.Tags),
+		Author:         mc.getUserName(record.AuthorId),
+		AuthorId:       record.AuthorId,
+		DateAdded:      record.DateAdded,
+		UpdateAuthor:   mc.getUserName(record.UpdateAuthorId),
+		UpdateAuthorId: record.UpdateAuthorId,
+		DateUpdated:    record.DateUpdated,
+		Status:         record.Status,
+		PublishAt:      record.PublishAt,
+		DeletedBy:      record.DeletedBy,
+	}
+
+	if record.DeletedAt != nil {
+		deletedAt := *record.DeletedAt
+		doc.DeletedAt = &deletedAt
+	}
+
+	return &doc
+}
+
+// isVisible returns whether the blog post can be returned by the get functions
+func isVisible(record *blogRecord, includeUnpublished bool) bool {
+	if record.DeletedAt != nil {
+		return false
+	}
+
+	if includeUnpublished {
+		return true
+	}
+
+	return dbController.IsPublicBlogStatus(record.Status) && !record.PublishAt.After(time.Now())
+}
+
+// findBlogRecord returns the index of the blog post matching the matcher
+// function, or -1 if no post matches. The mutex must be held by the caller.
+func (mc *MemoryDbController) findBlogRecord(matcher func(*blogRecord) bool) int {
+	for i, record := range mc.blogPosts {
+		if matcher(record) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// slugExists checks whether a blog post other than the one with the excludeId
+// id already uses the slug. The mutex must be held by the caller.
+func (mc *MemoryDbController) slugExists(slug string, excludeId string) bool {
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Slug == slug && record.Id != excludeId
+	})
+
+	return idx >= 0
+}
+
+// oldSlugExists returns whether a post other than excludeId used to have the
+// slug. The mutex must be held by the caller.
+func (mc *MemoryDbController) oldSlugExists(slug string, excludeId string) bool {
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Id != excludeId && hasSlug(record.OldSlugs, slug)
+	})
+
+	return idx >= 0
+}
+
+func hasSlug(slugs []string, slug string) bool {
+	for _, s := range slugs {
+		if s == slug {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changeSlug returns the old slugs of a post after it changes from oldSlug to
+// newSlug. A post going back to one of its old slugs doesn't keep it as an
+// old slug.
+func changeSlug(oldSlugs []string, oldSlug string, newSlug string) []string {
+	output := make([]string, 0)
+
+	for _, s := range oldSlugs {
+		if s != newSlug && s != oldSlug {
+			output = append(output, s)
+		}
+	}
+
+	return append(output, oldSlug)
+}
+
+func (mc *MemoryDbController) InitDatabase() error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if mc.blogPosts == nil {
+		mc.blogPosts = make([]*blogRecord, 0)
+	}
+
+	if mc.users == nil {
+		mc.users = make(map[string]*user.UserInformation)
+	}
+
+	if mc.revisions == nil {
+		mc.revisions = make(map[string][]*dbController.BlogRevision)
+	}
+
+	if mc.apiKeys == nil {
+		mc.apiKeys = make([]*dbController.ApiKeyDocument, 0)
+	}
+
+	return nil
+}
+
+func (mc *MemoryDbController) AddBlogPost(doc *dbController.AddBlogDocument) (string, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if mc.slugExists(doc.Slug, "") {
+		msg := "Duplicate blog post. Blog Post with slug '" + doc.Slug + "' already exists."
+		return "", dbController.NewDuplicateEntryError(msg)
+	}
+
+	if mc.oldSlugExists(doc.Slug, "") {
+		msg := "Duplicate blog post. Slug '" + doc.Slug + "' was used by another blog post."
+		return "", dbController.NewDuplicateEntryError(msg)
+	}
+
+	dateAdded := toTimestamp(doc.DateAdded)
+
+	record := blogRecord{
+		Id:             primitive.NewObjectID().Hex(),
+		Title:          doc.Title,
+		Slug:           doc.Slug,
+		Body:           doc.Body,
+		BodyHtml:       doc.BodyHtml,
+		Summary:        doc.Summary,
+		AuthorId:       doc.AuthorId,
+		DateAdded:      dateAdded,
+		UpdateAuthorId: doc.AuthorId,
+		DateUpdated:    dateAdded,
+		Status:         dbController.BLOG_STATUS_PUBLISHED,
+		PublishAt:      dateAdded,
+	}
+
+	if doc.Tags != nil {
+		record.Tags = copyTags(*doc.Tags)
+	}
+
+	if doc.UpdateAuthorId != nil {
+		record.UpdateAuthorId = *doc.UpdateAuthorId
+	}
+
+	if doc.DateUpdated != nil {
+		record.DateUpdated = toTimestamp(*doc.DateUpdated)
+	}
+
+	if doc.Status != nil {
+		record.Status = *doc.Status
+	}
+
+	if doc.PublishAt != nil {
+		record.PublishAt = toTimestamp(*doc.PublishAt)
+	}
+
+	mc.blogPosts = append(mc.blogPosts, &record)
+	mc.addRevision(&record, nil)
+
+	return record.Id, nil
+}
+
+func (mc *MemoryDbController) GetBlogPostById(id string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	if _, idErr := primitive.ObjectIDFromHex(id); idErr != nil {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Id == id && isVisible(record, includeUnpublished)
+	})
+
+	if idx < 0 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return mc.getBlogDocument(mc.blogPosts[idx]), nil
+}
+
+func (mc *MemoryDbController) GetBlogPostBySlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Slug == slug && isVisible(record, includeUnpublished)
+	})
+
+	if idx < 0 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return mc.getBlogDocument(mc.blogPosts[idx]), nil
+}
+
+func (mc *MemoryDbController) GetBlogPostByOldSlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return hasSlug(record.OldSlugs, slug) && isVisible(record, includeUnpublished)
+	})
+
+	if idx < 0 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return mc.getBlogDocument(mc.blogPosts[idx]), nil
+}
+
+func (mc *MemoryDbController) IsSlugAvailable(slug string, id string) (bool, error) {
+	if _, idErr := primitive.ObjectIDFromHex(id); len(id) > 0 && idErr != nil {
+		return false, dbController.NewInvalidInputError("invalid id")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	return !mc.slugExists(slug, id) && !mc.oldSlugExists(slug, id), nil
+}
+
+func (mc *MemoryDbController) GetBlogPosts(page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	return mc.getBlogPostsMatching(page, pagination, includeUnpublished, func(record *blogRecord) bool {
+		return true
+	})
+}
+
+// getBlogPostsMatching returns a page of the visible blog posts that the
+// matcher returns true for, newest first.
+func (mc *MemoryDbController) getBlogPostsMatching(page int, pagination int, includeUnpublished bool, matcher func(*blogRecord) bool) ([]*dbController.BlogDocument, error) {
+	// MongoDB rejects negative $skip values and non-positive $limit values
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
+
+	for _, v := range mc.getBlogRecordPage(page, pagination, includeUnpublished, matcher) {
+		posts = append(posts, mc.getBlogDocument(v))
+	}
+
+	return posts, nil
+}
+
+// getBlogRecordPage returns a page of the visible blog posts that the matcher
+// returns true for, newest first. The mutex must be held by the caller.
+func (mc *MemoryDbController) getBlogRecordPage(page int, pagination int, includeUnpublished bool, matcher func(*blogRecord) bool) []*blogRecord {
+	sorted := make([]*blogRecord, 0)
+	for _, record := range mc.blogPosts {
+		if isVisible(record, includeUnpublished) && matcher(record) {
+			sorted = append(sorted, record)
+		}
+	}
+
+	// Newest first. Posts added at the same time keep their insertion order.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DateAdded.After(sorted[j].DateAdded)
+	})
+
+	start := (page - 1) * pagination
+	if start >= len(sorted) {
+		return []*blogRecord{}
+	}
+
+	end := start + pagination
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+
+	return sorted[start:end]
+}
+
+func (mc *MemoryDbController) GetSitemapEntries(page int, pagination int) ([]*dbController.SitemapEntry, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	entries := make([]*dbController.SitemapEntry, 0)
+
+	records := mc.getBlogRecordPage(page, pagination, false, func(record *blogRecord) bool {
+		return true
+	})
+
+	for _, record := range records {
+		entries = append(entries, &dbController.SitemapEntry{
+			Slug:        record.Slug,
+			DateAdded:   record.DateAdded,
+			DateUpdated: record.DateUpdated,
+		})
+	}
+
+	return entries, nil
+}
+
+// filterMatcher returns a matcher for the blog posts that match the filter
+func filterMatcher(filter *dbController.BlogPostFilter) func(*blogRecord) bool {
+	return func(record *blogRecord) bool {
+		if filter == nil {
+			return true
+		}
+
+		if len(filter.AuthorId) > 0 && record.AuthorId != filter.AuthorId {
+			return false
+		}
+
+		if filter.DateAddedStart != nil && record.DateAdded.Before(*filter.DateAddedStart) {
+			return false
+		}
+
+		if filter.DateAddedEnd != nil && !record.DateAdded.Before(*filter.DateAddedEnd) {
+			return false
+		}
+
+		if len(filter.Tag) > 0 {
+			for _, tag := range record.Tags {
+				if tag == filter.Tag {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		return true
+	}
+}
+
+func (mc *MemoryDbController) CountBlogPosts(filter *dbController.BlogPostFilter, includeUnpublished bool) (int, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	matcher := filterMatcher(filter)
+
+	count := 0
+	for _, record := range mc.blogPosts {
+		if isVisible(record, includeUnpublished) && matcher(record) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// comesBefore returns whether a post added at aDate with aId is listed before a
+// post added at bDate with bId, newest first, then by id.
+func comesBefore(aDate time.Time, aId string, bDate time.Time, bId string) bool {
+	if !aDate.Equal(bDate) {
+		return aDate.After(bDate)
+	}
+
+	return aId < bId
+}
+
+func (mc *MemoryDbController) GetBlogPostsByCursor(cursor *dbController.BlogCursor, limit int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if limit < 1 {
+		return nil, dbController.NewDBError("invalid limit")
+	}
+
+	if cursor != nil {
+		if _, idErr := primitive.ObjectIDFromHex(cursor.Id); idErr != nil {
+			return nil, dbController.NewInvalidInputError("invalid cursor")
+		}
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	sorted := make([]*blogRecord, 0)
+	for _, record := range mc.blogPosts {
+		if !isVisible(record, includeUnpublished) {
+			continue
+		}
+
+		if cursor != nil && cursor.Previous && !comesBefore(record.DateAdded, record.Id, cursor.DateAdded, cursor.Id) {
+			continue
+		}
+
+		if cursor != nil && !cursor.Previous && !comesBefore(cursor.DateAdded, cursor.Id, record.DateAdded, record.Id) {
+			continue
+		}
+
+		sorted = append(sorted, record)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return comesBefore(sorted[i].DateAdded, sorted[i].Id, sorted[j].DateAdded, sorted[j].Id)
+	})
+
+	// The posts closest to the cursor are at the end when paging back
+	if len(sorted) > limit {
+		if cursor != nil && cursor.Previous {
+			sorted = sorted[len(sorted)-limit:]
+		} else {
+			sorted = sorted[:limit]
+		}
+	}
+
+	posts := make([]*dbController.BlogDocument, 0)
+	for _, record := range sorted {
+		posts = append(posts, mc.getBlogDocument(record))
+	}
+
+	return posts, nil
+}
+
+func (mc *MemoryDbController) GetBlogTags(includeUnpublished bool) ([]*dbController.TagCount, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	counts := make(map[string]int)
+	for _, record := range mc.blogPosts {
+		if !isVisible(record, includeUnpublished) {
+			continue
+		}
+
+		// A post with the same tag twice is only counted once
+		seen := make(map[string]bool)
+		for _, tag := range record.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				counts[tag]++
+			}
+		}
+	}
+
+	tags := make([]*dbController.TagCount, 0)
+	for tag, count := range counts {
+		tags = append(tags, &dbController.TagCount{Tag: tag, Count: count})
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+
+		return tags[i].Tag < tags[j].Tag
+	})
+
+	return tags, nil
+}
+
+func (mc *MemoryDbController) GetBlogPostsByTag(tag string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	filter := dbController.BlogPostFilter{Tag: tag}
+
+	return mc.getBlogPostsMatching(page, pagination, includeUnpublished, filterMatcher(&filter))
+}
+
+func (mc *MemoryDbController) GetBlogAuthor(uid string) (*dbController.BlogAuthor, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	info, ok := mc.users[uid]
+
+	if !ok {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return &dbController.BlogAuthor{UID: info.Uid, Name: info.Name}, nil
+}
+
+func (mc *MemoryDbController) GetBlogPostsByAuthor(uid string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	filter := dbController.BlogPostFilter{AuthorId: uid}
+
+	return mc.getBlogPostsMatching(page, pagination, includeUnpublished, filterMatcher(&filter))
+}
+
+func (mc *MemoryDbController) GetBlogAuthors(includeUnpublished bool) ([]*dbController.BlogAuthor, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	authors := make([]*dbController.BlogAuthor, 0)
+
+	for _, record := range mc.blogPosts {
+		if !isVisible(record, includeUnpublished) || seen[record.AuthorId] {
+			continue
+		}
+
+		seen[record.AuthorId] = true
+
+		if info, ok := mc.users[record.AuthorId]; ok {
+			authors = append(authors, &dbController.BlogAuthor{UID: info.Uid, Name: info.Name})
+		}
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		return authors[i].UID < authors[j].UID
+	})
+
+	return authors, nil
+}
+
+func (mc *MemoryDbController) GetBlogArchive(includeUnpublished bool) ([]*dbController.ArchiveCount, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	counts := make(map[[2]int]int)
+	for _, record := range mc.blogPosts {
+		if isVisible(record, includeUnpublished) {
+			dateAdded := record.DateAdded.UTC()
+			counts[[2]int{dateAdded.Year(), int(dateAdded.Month())}]++
+		}
+	}
+
+	archive := make([]*dbController.ArchiveCount, 0)
+	for month, count := range counts {
+		archive = append(archive, &dbController.ArchiveCount{Year: month[0], Month: month[1], Count: count})
+	}
+
+	sort.Slice(archive, func(i, j int) bool {
+		if archive[i].Year != archive[j].Year {
+			return archive[i].Year > archive[j].Year
+		}
+
+		return archive[i].Month > archive[j].Month
+	})
+
+	return archive, nil
+}
+
+func (mc *MemoryDbController) GetBlogPostsByDateRange(start time.Time, end time.Time, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	filter := dbController.BlogPostFilter{DateAddedStart: &start, DateAddedEnd: &end}
+
+	return mc.getBlogPostsMatching(page, pagination, includeUnpublished, filterMatcher(&filter))
+}
+
+// findSearchResults returns the visible blog posts that contain any of the
+// terms, in no particular order. The mutex must be held by the caller.
+func (mc *MemoryDbController) findSearchResults(terms []string, includeUnpublished bool) []*dbController.SearchResult {
+	results := make([]*dbController.SearchResult, 0)
+	for _, record := range mc.blogPosts {
+		if !isVisible(record, includeUnpublished) {
+			continue
+		}
+
+		post := mc.getBlogDocument(record)
+		score := dbController.ScoreBlogPost(post, terms)
+
+		if score > 0 {
+			results = append(results, &dbController.SearchResult{Post: post, Score: score})
+		}
+	}
+
+	return results
+}
+
+func (mc *MemoryDbController) CountSearchResults(query string, includeUnpublished bool) (int, error) {
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return 0, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	return len(mc.findSearchResults(terms, includeUnpublished)), nil
+}
+
+func (mc *MemoryDbController) SearchBlogPosts(query string, page int, pagination int, includeUnpublished bool) ([]*dbController.SearchResult, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	results := mc.findSearchResults(terms, includeUnpublished)
+
+	dbController.SortSearchResults(results)
+	results = dbController.PageSearchResults(results, page, pagination)
+
+	for _, result := range results {
+		result.Snippet = dbController.MakeSearchSnippet(result.Post.Body, terms)
+	}
+
+	return results, nil
+}
+
+func (mc *MemoryDbController) EditBlogPost(doc *dbController.EditBlogDocument) error {
+	if _, idErr := primitive.ObjectIDFromHex(doc.Id); idErr != nil {
+		return dbController.NewInvalidInputError("Invalid User ID")
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Id == doc.Id && record.DeletedAt == nil
+	})
+
+	if idx < 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts")
+	}
+
+	if doc.Slug != nil && mc.slugExists(*doc.Slug, doc.Id) {
+		msg := "Duplicate blog post. Blog Post with slug '" + *doc.Slug + "' already exists."
+		return dbController.NewDuplicateEntryError(msg)
+	}
+
+	if doc.Slug != nil && mc.oldSlugExists(*doc.Slug, doc.Id) {
+		msg := "Duplicate blog post. Slug '" + *doc.Slug + "' was used by another blog post."
+		return dbController.NewDuplicateEntryError(msg)
+	}
+
+	// We make the changes to a copy, so that the stored record is replaced in one step
+	record := *mc.blogPosts[idx]
+
+	if doc.Title != nil {
+		record.Title = *doc.Title
+	}
+
+	if doc.Slug != nil && *doc.Slug != record.Slug {
+		record.OldSlugs = changeSlug(record.OldSlugs, record.Slug, *doc.Slug)
+		record.Slug = *doc.Slug
+	}
+
+	if doc.Body != nil {
+		record.Body = *doc.Body
+	}
+
+	if doc.BodyHtml != nil {
+		record.BodyHtml = *doc.BodyHtml
+	}
+
+	if doc.Summary != nil {
+		record.Summary = *doc.Summary
+	}
+
+	if doc.Tags != nil {
+		record.Tags = copyTags(*doc.Tags)
+	}
+
+	if doc.AuthorId != nil {
+		record.AuthorId = *doc.AuthorId
+	}
+
+	if doc.DateAdded != nil {
+		record.DateAdded = toTimestamp(*doc.DateAdded)
+	}
+
+	if doc.UpdateAuthorId != nil {
+		record.UpdateAuthorId = *doc.UpdateAuthorId
+	}
+
+	if doc.DateUpdated != nil {
+		record.DateUpdated = toTimestamp(*doc.DateUpdated)
+	}
+
+	if doc.Status != nil {
+		record.Status = *doc.Status
+	}
+
+	if doc.PublishAt != nil {
+		record.PublishAt = toTimestamp(*doc.PublishAt)
+	}
+
+	// Posts added before revisions were saved get their previous state saved
+	// as the first revision.
+	previous := mc.blogPosts[idx]
+	if len(mc.revisions[doc.Id]) == 0 {
+		mc.addRevision(previous, nil)
+	}
+
+	mc.blogPosts[idx] = &record
+	mc.addRevision(&record, previous)
+
+	return nil
+}
+
+func (mc *MemoryDbController) DeleteBlogPost(doc *dbController.DeleteBlogDocument) error {
+	if _, idErr := primitive.ObjectIDFromHex(doc.Id); idErr != nil {
+		return dbController.NewInvalidInputError("Invalid User ID")
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	idx := mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Id == doc.Id && record.DeletedAt == nil
+	})
+
+	if idx < 0 {
+		return dbController.NewInvalidInputError("invalid id. no blog posts deleted")
+	}
+
+	deletedAt := toTimestamp(time.Now())
+
+	record := *mc.blogPosts[idx]
+	record.DeletedAt = &deletedAt
+	record.DeletedBy = doc.DeletedBy
+
+	mc.blogPosts[idx] = &record
+
+	return nil
+}
+
+// findTrashedRecord returns the index of the blog post in the trash with the
+// id, or -1 if there isn't one. The mutex must be held by the caller.
+func (mc *MemoryDbController) findTrashedRecord(id string) int {
+	return mc.findBlogRecord(func(record *blogRecord) bool {
+		return record.Id == id && record.DeletedAt != nil
+	})
+}
+
+// purgeRecord removes the blog post at idx along with its revisions. The mutex
+// must be held by the caller.
+func (mc *MemoryDbController) purgeRecord(idx int) {
+	delete(mc.revisions, mc.blogPosts[idx].Id)
+	mc.blogPosts = append(mc.blogPosts[:idx], mc.blogPosts[idx+1:]...)
+}
+
+func (mc *MemoryDbController) GetTrashedBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	sorted := make([]*blogRecord, 0)
+	for _, record := range mc.blogPosts {
+		if record.DeletedAt != nil {
+			sorted = append(sorted, record)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DeletedAt.After(*sorted[j].DeletedAt)
+	})
+
+	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
+
+	start := (page - 1) * pagination
+	if start >= len(sorted) {
+		return posts, nil
+	}
+
+	end := start + pagination
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+
+	for _, v := range sorted[start:end] {
+		posts = append(posts, mc.getBlogDocument(v))
+	}
+
+	return posts, nil
+}
+
+func (mc *MemoryDbController) CountTrashedBlogPosts() (int, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	count := 0
+	for _, record := range mc.blogPosts {
+		if record.DeletedAt != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (mc *MemoryDbController) RestoreBlogPost(id string) error {
+	if _, idErr := primitive.ObjectIDFromHex(id); idErr != nil {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	idx := mc.findTrashedRecord(id)
+
+	if idx < 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts in the trash")
+	}
+
+	record := *mc.blogPosts[idx]
+	record.DeletedAt = nil
+	record.DeletedBy = ""
+
+	mc.blogPosts[idx] = &record
+
+	return nil
+}
+
+func (mc *MemoryDbController) PurgeBlogPost(id string) error {
+	if _, idErr := primitive.ObjectIDFromHex(id); idErr != nil {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	idx := mc.findTrashedRecord(id)
+
+	if idx < 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts in the trash")
+	}
+
+	mc.purgeRecord(idx)
+
+	return nil
+}
+
+func (mc *MemoryDbController) PurgeTrashedBlogPosts(deletedBefore time.Time) (int, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	purged := 0
+
+	for {
+		idx := mc.findBlogRecord(func(record *blogRecord) bool {
+			return record.DeletedAt != nil && record.DeletedAt.Before(deletedBefore)
+		})
+
+		if idx < 0 {
+			return purged, nil
+		}
+
+		mc.purgeRecord(idx)
+		purged++
+	}
+}
+
+// addRevision saves the current state of the record as its next revision. The
+// mutex must be held by the caller.
+func (mc *MemoryDbController) addRevision(record *blogRecord, previous *blogRecord) {
+	var previousDoc *dbController.BlogDocument
+	if previous != nil {
+		previousDoc = mc.getBlogDocument(previous)
+	}
+
+	revisions := mc.revisions[record.Id]
+	rev := dbController.NewBlogRevision(mc.getBlogDocument(record), len(revisions)+1, previousDoc)
+
+	mc.revisions[record.Id] = append(revisions, rev)
+}
+
+func copyRevision(rev *dbController.BlogRevision) *dbController.BlogRevision {
+	output := *rev
+	output.Tags = copyTags(rev.Tags)
+	output.ChangedFields = copyTags(rev.ChangedFields)
+
+	return &output
+}
+
+func (mc *MemoryDbController) GetBlogPostRevisions(postId string) ([]*dbController.BlogRevision, error) {
+	if _, idErr := primitive.ObjectIDFromHex(postId); idErr != nil {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	revisions := mc.revisions[postId]
+	output := make([]*dbController.BlogRevision, 0)
+
+	for i := len(revisions) - 1; i >= 0; i-- {
+		output = append(output, copyRevision(revisions[i]))
+	}
+
+	return output, nil
+}
+
+func (mc *MemoryDbController) GetBlogPostRevision(postId string, revision int) (*dbController.BlogRevision, error) {
+	if _, idErr := primitive.ObjectIDFromHex(postId); idErr != nil {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	revisions := mc.revisions[postId]
+
+	if revision < 1 || revision > len(revisions) {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return copyRevision(revisions[revision-1]), nil
+}
+
+// AddUserInformation inserts the user information or replaces the information
+// of the user with the same uid. Emails must be unique among users.
+func (mc *MemoryDbController) AddUserInformation(info *user.UserInformation) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if mc.emailExists(info.Email, info.Uid) {
+		return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + info.Email + "' already exists.")
+	}
+
+	stored := *info
+	mc.users[info.Uid] = &stored
+
+	return nil
+}
+
+// emailExists returns whether a user other than the one with excludeUid has
+// the email.
+func (mc *MemoryDbController) emailExists(email string, excludeUid string) bool {
+	for uid, existing := range mc.users {
+		if uid != excludeUid && existing.Email == email {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (mc *MemoryDbController) GetUserInformation(uid string) (*user.UserInformation, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	info, ok := mc.users[uid]
+
+	if !ok {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	output := *info
+
+	return &output, nil
+}
+
+func (mc *MemoryDbController) GetUsers(page int, pagination int) ([]*user.UserInformation, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	uids := make([]string, 0, len(mc.users))
+	for uid := range mc.users {
+		uids = append(uids, uid)
+	}
+
+	sort.Strings(uids)
+
+	users := make([]*user.UserInformation, 0)
+
+	start := (page - 1) * pagination
+	if start >= len(uids) {
+		return users, nil
+	}
+
+	end := start + pagination
+	if end > len(uids) {
+		end = len(uids)
+	}
+
+	for _, uid := range uids[start:end] {
+		info := *mc.users[uid]
+		users = append(users, &info)
+	}
+
+	return users, nil
+}
+
+func (mc *MemoryDbController) CountUsers() (int, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	return len(mc.users), nil
+}
+
+func (mc *MemoryDbController) EditUserInformation(doc *dbController.EditUserDocument) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	existing, ok := mc.users[doc.Uid]
+
+	if !ok {
+		return dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	if doc.Email != nil && mc.emailExists(*doc.Email, doc.Uid) {
+		return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + *doc.Email + "' already exists.")
+	}
+
+	edited := *existing
+
+	if doc.Name != nil {
+		edited.Name = *doc.Name
+	}
+
+	if doc.Email != nil {
+		edited.Email = *doc.Email
+	}
+
+	if doc.Active != nil {
+		edited.Active = *doc.Active
+	}
+
+	if doc.Role != nil {
+		edited.Role = *doc.Role
+	}
+
+	mc.users[doc.Uid] = &edited
+
+	return nil
+}
+
+func (mc *MemoryDbController) DeleteUserInformation(uid string) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if _, ok := mc.users[uid]; !ok {
+		return dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	delete(mc.users, uid)
+
+	return nil
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	output := toTimestamp(*t)
+	return &output
+}
+
+func copyApiKey(key *dbController.ApiKeyDocument) *dbController.ApiKeyDocument {
+	output := *key
+	output.Scopes = copyTags(key.Scopes)
+	output.ExpiresAt = copyTime(key.ExpiresAt)
+	output.LastUsed = copyTime(key.LastUsed)
+	output.RevokedAt = copyTime(key.RevokedAt)
+
+	return &output
+}
+
+func (mc *MemoryDbController) findApiKey(id string) *dbController.ApiKeyDocument {
+	for _, key := range mc.apiKeys {
+		if key.Id == id {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (mc *MemoryDbController) AddApiKey(doc *dbController.AddApiKeyDocument) (string, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	for _, key := range mc.apiKeys {
+		if key.Hash == doc.Hash {
+			return "", dbController.NewDuplicateEntryError("Duplicate API key")
+		}
+	}
+
+	key := dbController.ApiKeyDocument{
+		Id:        primitive.NewObjectID().Hex(),
+		Name:      doc.Name,
+		OwnerId:   doc.OwnerId,
+		Prefix:    doc.Prefix,
+		Hash:      doc.Hash,
+		Scopes:    doc.Scopes,
+		DateAdded: toTimestamp(doc.DateAdded),
+		ExpiresAt: doc.ExpiresAt,
+	}
+
+	mc.apiKeys = append(mc.apiKeys, copyApiKey(&key))
+
+	return key.Id, nil
+}
+
+func (mc *MemoryDbController) GetApiKeyByHash(hash string) (*dbController.ApiKeyDocument, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	for _, key := range mc.apiKeys {
+		if key.Hash == hash {
+			return copyApiKey(key), nil
+		}
+	}
+
+	return nil, dbController.NewNoResultsError("")
+}
+
+func (mc *MemoryDbController) GetApiKeys() ([]*dbController.ApiKeyDocument, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	keys := make([]*dbController.ApiKeyDocument, 0, len(mc.apiKeys))
+
+	for _, key := range mc.apiKeys {
+		keys = append(keys, copyApiKey(key))
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return comesBefore(keys[i].DateAdded, keys[i].Id, keys[j].DateAdded, keys[j].Id)
+	})
+
+	return keys, nil
+}
+
+func (mc *MemoryDbController) RevokeApiKey(id string, revokedAt time.Time) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	key := mc.findApiKey(id)
+
+	if key == nil {
+		return dbController.NewInvalidInputError("id did not match any API keys")
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = copyTime(&revokedAt)
+	}
+
+	return nil
+}
+
+func (mc *MemoryDbController) SetApiKeyLastUsed(id string, lastUsed time.Time) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	key := mc.findApiKey(id)
+
+	if key == nil {
+		return dbController.NewInvalidInputError("id did not match any API keys")
+	}
+
+	key.LastUsed = copyTime(&lastUsed)
+
+	return nil
+}
+
+func (mc *MemoryDbController) AddRequestLog(log *logging.RequestLogData) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	entry := *log
+	entry.Timestamp = toTimestamp(log.Timestamp)
+
+	mc.requestLogs = append(mc.requestLogs, entry)
+
+	if len(mc.requestLogs) > MAX_LOG_ENTRIES {
+		mc.requestLogs = mc.requestLogs[len(mc.requestLogs)-MAX_LOG_ENTRIES:]
+	}
+
+	return nil
+}
+
+func (mc *MemoryDbController) AddInfoLog(log *logging.InfoLogData) error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	entry := *log
+	entry.Timestamp = toTimestamp(log.Timestamp)
+
+	mc.infoLogs = append(mc.infoLogs, entry)
+
+	if len(mc.infoLogs) > MAX_LOG_ENTRIES {
+		mc.infoLogs = mc.infoLogs[len(mc.infoLogs)-MAX_LOG_ENTRIES:]
+	}
+
+	return nil
+}
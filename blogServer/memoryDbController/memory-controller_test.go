@@ -0,0 +1,14 @@
+package memoryDbController
+
+import (
+	"testing"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/dbController/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RunConformanceSuite(t, func(t *testing.T) dbController.DatabaseController {
+		return MakeMemoryDbController()
+	})
+}
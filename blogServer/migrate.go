@@ -0,0 +1,56 @@
+package blogServer
+
+import (
+	"fmt"
+	"log"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// DoMigrate applies or reverts database migrations until the schema is at the
+// version pointed to by versionPtr. A version of -1 applies every migration.
+// When dryRunPtr points to true, the changes are printed, but not made.
+func DoMigrate(versionPtr *int, dryRunPtr *bool) {
+	fmt.Println("Doing Database Migration")
+
+	// We don't use makeAndInitDatabase here, since InitDatabase would migrate
+	// the database to the latest version before we get a chance to.
+	dbc, dbcErr := makeDatabaseController()
+
+	if dbcErr != nil {
+		log.Fatal("Error Making Database Controller: ", dbcErr.Error())
+	}
+
+	migrator, ok := dbc.(dbController.Migrator)
+
+	if !ok {
+		log.Fatal("The configured database type doesn't support migrations")
+	}
+
+	startVersion, startVersionErr := migrator.GetMigrationVersion()
+
+	if startVersionErr != nil {
+		log.Fatal("Error getting migration version: ", startVersionErr.Error())
+	}
+
+	fmt.Println("Current version:", startVersion)
+
+	migrateErr := migrator.MigrateTo(*versionPtr, *dryRunPtr)
+
+	if migrateErr != nil {
+		log.Fatal("Error migrating database: ", migrateErr.Error())
+	}
+
+	if *dryRunPtr {
+		fmt.Println("Dry run complete. No changes were made.")
+		return
+	}
+
+	endVersion, endVersionErr := migrator.GetMigrationVersion()
+
+	if endVersionErr != nil {
+		log.Fatal("Error getting migration version: ", endVersionErr.Error())
+	}
+
+	fmt.Println("Migrated to version:", endVersion)
+}
@@ -0,0 +1,243 @@
+package mongoDbController
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const API_KEY_COLLECTION = "apiKeys"
+const API_KEY_HASH_INDEX = "hash"
+
+func getApiKeyJsonSchema() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"name", "ownerId", "prefix", "hash", "scopes", "dateAdded"},
+		"properties": bson.M{
+			"name": bson.M{
+				"bsonType":    "string",
+				"description": "name must be a string",
+			},
+			"ownerId": bson.M{
+				"bsonType":    "string",
+				"description": "ownerId must be a string",
+			},
+			"prefix": bson.M{
+				"bsonType":    "string",
+				"description": "prefix must be a string",
+			},
+			"hash": bson.M{
+				"bsonType":    "string",
+				"description": "hash must be a string",
+			},
+			"scopes": bson.M{
+				"bsonType":    "array",
+				"description": "scopes must be an array",
+			},
+			"dateAdded": bson.M{
+				"bsonType":    "timestamp",
+				"description": "dateAdded must be a timestamp",
+			},
+			"expiresAt": bson.M{
+				"bsonType":    "timestamp",
+				"description": "expiresAt must be a timestamp",
+			},
+			"lastUsed": bson.M{
+				"bsonType":    "timestamp",
+				"description": "lastUsed must be a timestamp",
+			},
+			"revokedAt": bson.M{
+				"bsonType":    "timestamp",
+				"description": "revokedAt must be a timestamp",
+			},
+		},
+	}
+}
+
+func getApiKeyHashIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.M{"hash": 1},
+		Options: options.Index().SetUnique(true).SetName(API_KEY_HASH_INDEX),
+	}
+}
+
+func (mdbc *MongoDbController) initApiKeyCollection(dbName string) error {
+	db := mdbc.MongoClient.Database(dbName)
+
+	colOpts := options.CreateCollection().SetValidator(bson.M{"$jsonSchema": getApiKeyJsonSchema()})
+
+	createCollectionErr := db.CreateCollection(context.TODO(), API_KEY_COLLECTION, colOpts)
+
+	if createCollectionErr != nil {
+		return dbController.NewDBError(createCollectionErr.Error())
+	}
+
+	opts := options.CreateIndexes().SetMaxTime(2 * time.Second)
+
+	collection, _, _ := mdbc.getCollection(API_KEY_COLLECTION)
+	_, setIndexErr := collection.Indexes().CreateOne(context.TODO(), getApiKeyHashIndexModel(), opts)
+
+	if setIndexErr != nil {
+		return dbController.NewDBError(setIndexErr.Error())
+	}
+
+	return nil
+}
+
+// ApiKeyDocResult is an API key read from the database. The optional dates
+// are missing until they are set.
+type ApiKeyDocResult struct {
+	Id        primitive.ObjectID `bson:"_id"`
+	Name      string             `bson:"name"`
+	OwnerId   string             `bson:"ownerId"`
+	Prefix    string             `bson:"prefix"`
+	Hash      string             `bson:"hash"`
+	Scopes    []string           `bson:"scopes"`
+	DateAdded time.Time          `bson:"dateAdded"`
+	ExpiresAt *time.Time         `bson:"expiresAt"`
+	LastUsed  *time.Time         `bson:"lastUsed"`
+	RevokedAt *time.Time         `bson:"revokedAt"`
+}
+
+func (akr *ApiKeyDocResult) GetApiKeyDoc() *dbController.ApiKeyDocument {
+	return &dbController.ApiKeyDocument{
+		Id:        akr.Id.Hex(),
+		Name:      akr.Name,
+		OwnerId:   akr.OwnerId,
+		Prefix:    akr.Prefix,
+		Hash:      akr.Hash,
+		Scopes:    akr.Scopes,
+		DateAdded: akr.DateAdded,
+		ExpiresAt: akr.ExpiresAt,
+		LastUsed:  akr.LastUsed,
+		RevokedAt: akr.RevokedAt,
+	}
+}
+
+func (mdbc *MongoDbController) AddApiKey(doc *dbController.AddApiKeyDocument) (string, error) {
+	collection, backCtx, cancel := mdbc.getCollection(API_KEY_COLLECTION)
+	defer cancel()
+
+	scopes := doc.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	insert := bson.M{
+		"name":      doc.Name,
+		"ownerId":   doc.OwnerId,
+		"prefix":    doc.Prefix,
+		"hash":      doc.Hash,
+		"scopes":    scopes,
+		"dateAdded": primitive.Timestamp{T: uint32(doc.DateAdded.Unix())},
+	}
+
+	if doc.ExpiresAt != nil {
+		insert["expiresAt"] = primitive.Timestamp{T: uint32(doc.ExpiresAt.Unix())}
+	}
+
+	result, insertErr := collection.InsertOne(backCtx, insert)
+
+	if insertErr != nil {
+		if mongo.IsDuplicateKeyError(insertErr) {
+			return "", dbController.NewDuplicateEntryError("Duplicate API key")
+		}
+
+		return "", dbController.NewDBError(insertErr.Error())
+	}
+
+	id, ok := result.InsertedID.(primitive.ObjectID)
+
+	if !ok {
+		return "", dbController.NewDBError("invalid id returned by database")
+	}
+
+	return id.Hex(), nil
+}
+
+func (mdbc *MongoDbController) GetApiKeyByHash(hash string) (*dbController.ApiKeyDocument, error) {
+	collection, backCtx, cancel := mdbc.getCollection(API_KEY_COLLECTION)
+	defer cancel()
+
+	var result ApiKeyDocResult
+	findErr := collection.FindOne(backCtx, bson.M{"hash": hash}).Decode(&result)
+
+	if findErr == mongo.ErrNoDocuments {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	return result.GetApiKeyDoc(), nil
+}
+
+func (mdbc *MongoDbController) GetApiKeys() ([]*dbController.ApiKeyDocument, error) {
+	collection, backCtx, cancel := mdbc.getCollection(API_KEY_COLLECTION)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "dateAdded", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, findErr := collection.Find(backCtx, bson.M{}, opts)
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	var results []ApiKeyDocResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	keys := make([]*dbController.ApiKeyDocument, 0)
+	for _, v := range results {
+		keys = append(keys, v.GetApiKeyDoc())
+	}
+
+	return keys, nil
+}
+
+// updateApiKey runs the update on the API key with the id
+func (mdbc *MongoDbController) updateApiKey(id string, update bson.M) error {
+	idObj, idErr := primitive.ObjectIDFromHex(id)
+
+	if idErr != nil {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(API_KEY_COLLECTION)
+	defer cancel()
+
+	result, updateErr := collection.UpdateOne(backCtx, bson.M{"_id": idObj}, update)
+
+	if updateErr != nil {
+		return dbController.NewDBError(updateErr.Error())
+	}
+
+	if result.MatchedCount == 0 {
+		return dbController.NewInvalidInputError("id did not match any API keys")
+	}
+
+	return nil
+}
+
+// RevokeApiKey uses $min, which sets revokedAt when it's missing and keeps an
+// earlier time otherwise.
+func (mdbc *MongoDbController) RevokeApiKey(id string, revokedAt time.Time) error {
+	return mdbc.updateApiKey(id, bson.M{
+		"$min": bson.M{"revokedAt": primitive.Timestamp{T: uint32(revokedAt.Unix())}},
+	})
+}
+
+func (mdbc *MongoDbController) SetApiKeyLastUsed(id string, lastUsed time.Time) error {
+	return mdbc.updateApiKey(id, bson.M{
+		"$set": bson.M{"lastUsed": primitive.Timestamp{T: uint32(lastUsed.Unix())}},
+	})
+}
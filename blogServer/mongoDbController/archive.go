@@ -0,0 +1,58 @@
+package mongoDbController
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (mdbc *MongoDbController) GetBlogArchive(includeUnpublished bool) ([]*dbController.ArchiveCount, error) {
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	matchStage := bson.D{{Key: "$match", Value: mdbc.getVisibilityFilter(includeUnpublished)}}
+
+	groupStage, sortStage := mdbc.GetArchiveAggregationStages()
+
+	cursor, aggErr := collection.Aggregate(backCtx, mongo.Pipeline{
+		matchStage,
+		*groupStage,
+		*sortStage,
+	})
+
+	if aggErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
+	}
+
+	var results []ArchiveCountResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	archive := make([]*dbController.ArchiveCount, 0)
+	for _, v := range results {
+		archive = append(archive, &dbController.ArchiveCount{
+			Year:  v.Id.Year,
+			Month: v.Id.Month,
+			Count: v.Count,
+		})
+	}
+
+	return archive, nil
+}
+
+func (mdbc *MongoDbController) GetBlogPostsByDateRange(start time.Time, end time.Time, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	filter := mdbc.addBlogPostFilter(
+		mdbc.getVisibilityFilter(includeUnpublished),
+		&dbController.BlogPostFilter{DateAddedStart: &start, DateAddedEnd: &end},
+	)
+
+	return mdbc.getBlogPostsWithFilter(filter, page, pagination)
+}
@@ -0,0 +1,90 @@
+package mongoDbController
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const BLOG_AUTHOR_INDEX = "authorId_dateAdded"
+
+func getBlogAuthorIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "authorId", Value: 1}, {Key: "dateAdded", Value: -1}},
+		Options: options.Index().SetName(BLOG_AUTHOR_INDEX),
+	}
+}
+
+func (mdbc *MongoDbController) GetBlogAuthor(uid string) (*dbController.BlogAuthor, error) {
+	collection, backCtx, cancel := mdbc.getCollection(USER_COLLECTION)
+	defer cancel()
+
+	var result UserDocResult
+	findErr := collection.FindOne(backCtx, bson.M{"uid": uid}).Decode(&result)
+
+	if findErr == mongo.ErrNoDocuments {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	return &dbController.BlogAuthor{UID: result.UID, Name: result.Name}, nil
+}
+
+func (mdbc *MongoDbController) GetBlogPostsByAuthor(uid string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	filter := mdbc.addBlogPostFilter(
+		mdbc.getVisibilityFilter(includeUnpublished),
+		&dbController.BlogPostFilter{AuthorId: uid},
+	)
+
+	return mdbc.getBlogPostsWithFilter(filter, page, pagination)
+}
+
+func (mdbc *MongoDbController) GetBlogAuthors(includeUnpublished bool) ([]*dbController.BlogAuthor, error) {
+	blogCollection, blogCtx, blogCancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer blogCancel()
+
+	authorIds, distinctErr := blogCollection.Distinct(blogCtx, "authorId", mdbc.getVisibilityFilter(includeUnpublished))
+
+	if distinctErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + distinctErr.Error())
+	}
+
+	authors := make([]*dbController.BlogAuthor, 0)
+
+	if len(authorIds) == 0 {
+		return authors, nil
+	}
+
+	userCollection, userCtx, userCancel := mdbc.getCollection(USER_COLLECTION)
+	defer userCancel()
+
+	cursor, findErr := userCollection.Find(
+		userCtx,
+		bson.M{"uid": bson.M{"$in": authorIds}},
+		options.Find().SetSort(bson.M{"uid": 1}),
+	)
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	var results []UserDocResult
+	if allErr := cursor.All(userCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	for _, v := range results {
+		authors = append(authors, &dbController.BlogAuthor{UID: v.UID, Name: v.Name})
+	}
+
+	return authors, nil
+}
@@ -0,0 +1,117 @@
+package mongoDbController
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const BLOG_CURSOR_INDEX = "dateAdded_id"
+
+func getBlogCursorIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "dateAdded", Value: -1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName(BLOG_CURSOR_INDEX),
+	}
+}
+
+func (mdbc *MongoDbController) CountBlogPosts(filter *dbController.BlogPostFilter, includeUnpublished bool) (int, error) {
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	countFilter := mdbc.addBlogPostFilter(mdbc.getVisibilityFilter(includeUnpublished), filter)
+
+	count, countErr := collection.CountDocuments(backCtx, countFilter)
+
+	if countErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + countErr.Error())
+	}
+
+	return int(count), nil
+}
+
+// GetBlogPostsByCursor uses a range on dateAdded and _id instead of $skip, so
+// later pages are as quick to find as the first one.
+func (mdbc *MongoDbController) GetBlogPostsByCursor(cursor *dbController.BlogCursor, limit int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if limit < 1 {
+		return nil, dbController.NewDBError("invalid limit")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	filter := mdbc.getVisibilityFilter(includeUnpublished)
+	dateOrder, idOrder := -1, 1
+
+	if cursor != nil {
+		idObj, idObjErr := primitive.ObjectIDFromHex(cursor.Id)
+
+		if idObjErr != nil {
+			return nil, dbController.NewInvalidInputError("invalid cursor")
+		}
+
+		dateAdded := primitive.Timestamp{T: uint32(cursor.DateAdded.Unix())}
+
+		// The posts before the cursor are read in reverse order, so that the
+		// limit keeps the posts closest to the cursor.
+		dateOp, idOp := "$lt", "$gt"
+		if cursor.Previous {
+			dateOp, idOp = "$gt", "$lt"
+			dateOrder, idOrder = 1, -1
+		}
+
+		filter["$or"] = bson.A{
+			bson.M{"dateAdded": bson.M{dateOp: dateAdded}},
+			bson.M{"dateAdded": dateAdded, "_id": bson.M{idOp: idObj}},
+		}
+	}
+
+	matchStage := bson.D{{Key: "$match", Value: filter}}
+
+	projectStage, authorLookupStage, updateAuthorLookupStage := mdbc.GetAggregationStages()
+
+	sortStage := bson.D{{
+		Key: "$sort",
+		Value: bson.D{
+			{Key: "dateAdded", Value: dateOrder},
+			{Key: "_id", Value: idOrder},
+		},
+	}}
+
+	limitStage := bson.D{{
+		Key:   "$limit",
+		Value: int32(limit),
+	}}
+
+	cursorResult, aggErr := collection.Aggregate(backCtx, mongo.Pipeline{
+		matchStage,
+		*projectStage,
+		sortStage,
+		limitStage,
+		*authorLookupStage,
+		*updateAuthorLookupStage,
+	})
+
+	if aggErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
+	}
+
+	var results []BlogDocResult
+	if allErr := cursorResult.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
+	for _, v := range results {
+		posts = append(posts, v.GetBlogDocument())
+	}
+
+	if cursor != nil && cursor.Previous {
+		dbController.ReverseBlogDocuments(posts)
+	}
+
+	return posts, nil
+}
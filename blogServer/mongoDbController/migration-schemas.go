@@ -0,0 +1,151 @@
+package mongoDbController
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// The validators set by the migrations, frozen as they were when each
+// migration was released. Migrations must use these instead of
+// getBlogJsonSchema and the other current schemas, which change along with
+// the collections. A change to a current schema needs a new frozen schema and
+// a migration that sets it.
+
+// schemaProperty is a property of a $jsonSchema validator with a bsonType
+func schemaProperty(bsonType interface{}, description string) bson.M {
+	return bson.M{
+		"bsonType":    bsonType,
+		"description": description,
+	}
+}
+
+// extendSchema returns a copy of the schema with the extra required fields
+// and properties. The schema itself isn't changed.
+func extendSchema(schema bson.M, required []string, properties bson.M) bson.M {
+	oldRequired := schema["required"].([]string)
+	newRequired := make([]string, 0, len(oldRequired)+len(required))
+	newRequired = append(newRequired, oldRequired...)
+	newRequired = append(newRequired, required...)
+
+	newProperties := bson.M{}
+	for key, value := range schema["properties"].(bson.M) {
+		newProperties[key] = value
+	}
+	for key, value := range properties {
+		newProperties[key] = value
+	}
+
+	return bson.M{
+		"bsonType":   schema["bsonType"],
+		"required":   newRequired,
+		"properties": newProperties,
+	}
+}
+
+func blogSchemaV1() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"title", "slug", "authorId", "dateAdded", "updateAuthorId", "dateUpdated"},
+		"properties": bson.M{
+			"title":          schemaProperty("string", "title must be a string"),
+			"slug":           schemaProperty("string", "slug must be a string"),
+			"body":           schemaProperty("string", "body must be a string"),
+			"tags":           schemaProperty("array", "tags must be an array"),
+			"authorId":       schemaProperty("string", "authorId must be a string"),
+			"dateAdded":      schemaProperty("timestamp", "dateAdded must be a timestamp"),
+			"updateAuthorId": schemaProperty("string", "updateAuthorId must be a string"),
+			"dateUpdated":    schemaProperty("timestamp", "dateUpdated must be a timestamp"),
+		},
+	}
+}
+
+func blogSchemaV3() bson.M {
+	return extendSchema(blogSchemaV1(), []string{"status", "publishAt"}, bson.M{
+		"status": bson.M{
+			"enum": []string{
+				dbController.BLOG_STATUS_DRAFT,
+				dbController.BLOG_STATUS_SCHEDULED,
+				dbController.BLOG_STATUS_PUBLISHED,
+				dbController.BLOG_STATUS_ARCHIVED,
+			},
+			"description": "status must be draft, scheduled, published or archived",
+		},
+		"publishAt": schemaProperty("timestamp", "publishAt must be a timestamp"),
+	})
+}
+
+func blogSchemaV5() bson.M {
+	return extendSchema(blogSchemaV3(), nil, bson.M{
+		"deletedAt": schemaProperty("timestamp", "deletedAt must be a timestamp"),
+		"deletedBy": schemaProperty("string", "deletedBy must be a string"),
+	})
+}
+
+func blogSchemaV10() bson.M {
+	return extendSchema(blogSchemaV5(), nil, bson.M{
+		"bodyHtml": schemaProperty("string", "bodyHtml must be a string"),
+	})
+}
+
+func blogSchemaV11() bson.M {
+	return extendSchema(blogSchemaV10(), nil, bson.M{
+		"excerpt":       schemaProperty("string", "excerpt must be a string"),
+		"customExcerpt": schemaProperty("bool", "customExcerpt must be a boolean"),
+		"wordCount":     schemaProperty([]string{"int", "long"}, "wordCount must be an integer"),
+		"readingTime":   schemaProperty([]string{"int", "long"}, "readingTime must be an integer"),
+	})
+}
+
+func blogSchemaV12() bson.M {
+	return extendSchema(blogSchemaV11(), nil, bson.M{
+		"oldSlugs": schemaProperty("array", "oldSlugs must be an array"),
+	})
+}
+
+func userSchemaV1() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"uid", "name", "email", "active", "role"},
+		"properties": bson.M{
+			"uid":    schemaProperty("string", "uid must be a string"),
+			"name":   schemaProperty("string", "name must be a string"),
+			"email":  schemaProperty("string", "email must be a string"),
+			"active": schemaProperty("bool", "active must be a bool"),
+			"role":   schemaProperty("string", "role must be a string"),
+		},
+	}
+}
+
+func revisionSchemaV4() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"postId", "revision", "title", "slug", "authorId", "dateAdded", "updateAuthorId", "dateUpdated", "status", "publishAt", "changedFields"},
+		"properties": bson.M{
+			"postId":        schemaProperty("string", "postId must be a string"),
+			"revision":      schemaProperty([]string{"int", "long"}, "revision must be an integer"),
+			"dateAdded":     schemaProperty("timestamp", "dateAdded must be a timestamp"),
+			"dateUpdated":   schemaProperty("timestamp", "dateUpdated must be a timestamp"),
+			"publishAt":     schemaProperty("timestamp", "publishAt must be a timestamp"),
+			"changedFields": schemaProperty("array", "changedFields must be an array"),
+		},
+	}
+}
+
+func apiKeySchemaV13() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"name", "ownerId", "prefix", "hash", "scopes", "dateAdded"},
+		"properties": bson.M{
+			"name":      schemaProperty("string", "name must be a string"),
+			"ownerId":   schemaProperty("string", "ownerId must be a string"),
+			"prefix":    schemaProperty("string", "prefix must be a string"),
+			"hash":      schemaProperty("string", "hash must be a string"),
+			"scopes":    schemaProperty("array", "scopes must be an array"),
+			"dateAdded": schemaProperty("timestamp", "dateAdded must be a timestamp"),
+			"expiresAt": schemaProperty("timestamp", "expiresAt must be a timestamp"),
+			"lastUsed":  schemaProperty("timestamp", "lastUsed must be a timestamp"),
+			"revokedAt": schemaProperty("timestamp", "revokedAt must be a timestamp"),
+		},
+	}
+}
@@ -0,0 +1,475 @@
+package mongoDbController
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const MIGRATIONS_COLLECTION = "migrations"
+
+// Migrations can take much longer than a regular query
+const MIGRATION_TIMEOUT = 5 * time.Minute
+
+// A migrationStep is a single change made to the database by a migration.
+// Steps should be safe to run more than once, since two servers starting at
+// the same time may both run a migration before either records it.
+type migrationStep interface {
+	Describe() string
+	Run(ctx context.Context, db *mongo.Database) error
+}
+
+// collModValidatorStep replaces the $jsonSchema validator of a collection.
+// Missing collections are skipped, since InitDatabase creates them with the
+// latest validator.
+type collModValidatorStep struct {
+	Collection string
+	JsonSchema bson.M
+}
+
+func (step collModValidatorStep) Describe() string {
+	return "update the validator of " + step.Collection
+}
+
+func (step collModValidatorStep) Run(ctx context.Context, db *mongo.Database) error {
+	command := bson.D{
+		{Key: "collMod", Value: step.Collection},
+		{Key: "validator", Value: bson.M{"$jsonSchema": step.JsonSchema}},
+	}
+
+	commandErr := db.RunCommand(ctx, command).Err()
+
+	if commandErr != nil && strings.Contains(commandErr.Error(), "ns does not exist") {
+		return nil
+	}
+
+	return commandErr
+}
+
+// createCollectionStep creates a collection with a $jsonSchema validator, so
+// that a later createIndexStep doesn't create it without one when InitDatabase
+// hasn't run. Existing collections are left as they are, so a
+// collModValidatorStep should follow to update their validator.
+type createCollectionStep struct {
+	Collection string
+	JsonSchema bson.M
+}
+
+func (step createCollectionStep) Describe() string {
+	return "create collection " + step.Collection
+}
+
+func (step createCollectionStep) Run(ctx context.Context, db *mongo.Database) error {
+	opts := options.CreateCollection().SetValidator(bson.M{"$jsonSchema": step.JsonSchema})
+
+	createErr := db.CreateCollection(ctx, step.Collection, opts)
+
+	if createErr != nil && strings.Contains(createErr.Error(), "Collection already exists") {
+		return nil
+	}
+
+	return createErr
+}
+
+// createIndexStep adds an index to a collection. The index model must have a
+// name so that it can be dropped again.
+type createIndexStep struct {
+	Collection string
+	Model      mongo.IndexModel
+}
+
+func (step createIndexStep) Describe() string {
+	return fmt.Sprintf("create index %s on %s", *step.Model.Options.Name, step.Collection)
+}
+
+func (step createIndexStep) Run(ctx context.Context, db *mongo.Database) error {
+	_, indexErr := db.Collection(step.Collection).Indexes().CreateOne(ctx, step.Model)
+	return indexErr
+}
+
+// dropIndexStep removes an index from a collection by name. Missing indexes
+// are ignored.
+type dropIndexStep struct {
+	Collection string
+	Name       string
+}
+
+func (step dropIndexStep) Describe() string {
+	return fmt.Sprintf("drop index %s on %s", step.Name, step.Collection)
+}
+
+func (step dropIndexStep) Run(ctx context.Context, db *mongo.Database) error {
+	_, dropErr := db.Collection(step.Collection).Indexes().DropOne(ctx, step.Name)
+
+	if dropErr != nil && strings.Contains(dropErr.Error(), "index not found") {
+		return nil
+	}
+
+	return dropErr
+}
+
+// backfillStep runs an update on every document matching the filter. The
+// update can be an update document or an aggregation pipeline. Validation is
+// bypassed, since the validator may already require fields that a later
+// migration backfills.
+type backfillStep struct {
+	Collection  string
+	Description string
+	Filter      bson.M
+	Update      interface{}
+}
+
+func (step backfillStep) Describe() string {
+	return "backfill " + step.Collection + ": " + step.Description
+}
+
+func (step backfillStep) Run(ctx context.Context, db *mongo.Database) error {
+	opts := options.Update().SetBypassDocumentValidation(true)
+
+	_, updateErr := db.Collection(step.Collection).UpdateMany(ctx, step.Filter, step.Update, opts)
+	return updateErr
+}
+
+// A migration moves the database from Version - 1 to Version with the Up
+// steps, and back again with the Down steps.
+type mongoMigration struct {
+	Version int
+	Name    string
+	Up      []migrationStep
+	Down    []migrationStep
+}
+
+// migrations must be kept in order. Once a migration has been released, it
+// should never be changed. Add a new migration instead.
+//
+// The collModValidatorSteps use the frozen validators in migration-schemas.go,
+// so that migrating to a version installs that version's validators. Down
+// steps restore the validator of the previous version.
+var migrations = []mongoMigration{
+	{
+		Version: 1,
+		Name:    "sync_validators",
+		Up: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV1()},
+			collModValidatorStep{USER_COLLECTION, userSchemaV1()},
+		},
+		Down: []migrationStep{},
+	},
+	{
+		Version: 2,
+		Name:    "backfill_update_fields",
+		Up: []migrationStep{
+			backfillStep{
+				Collection:  BLOG_COLLECTION,
+				Description: "set a missing updateAuthorId to authorId",
+				Filter:      bson.M{"updateAuthorId": bson.M{"$exists": false}},
+				Update:      bson.A{bson.M{"$set": bson.M{"updateAuthorId": "$authorId"}}},
+			},
+			backfillStep{
+				Collection:  BLOG_COLLECTION,
+				Description: "set a missing dateUpdated to dateAdded",
+				Filter:      bson.M{"dateUpdated": bson.M{"$exists": false}},
+				Update:      bson.A{bson.M{"$set": bson.M{"dateUpdated": "$dateAdded"}}},
+			},
+		},
+		Down: []migrationStep{},
+	},
+	{
+		Version: 3,
+		Name:    "add_blog_status",
+		Up: []migrationStep{
+			backfillStep{
+				Collection:  BLOG_COLLECTION,
+				Description: "set a missing status to published",
+				Filter:      bson.M{"status": bson.M{"$exists": false}},
+				Update:      bson.M{"$set": bson.M{"status": dbController.BLOG_STATUS_PUBLISHED}},
+			},
+			backfillStep{
+				Collection:  BLOG_COLLECTION,
+				Description: "set a missing publishAt to dateAdded",
+				Filter:      bson.M{"publishAt": bson.M{"$exists": false}},
+				Update:      bson.A{bson.M{"$set": bson.M{"publishAt": "$dateAdded"}}},
+			},
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV3()},
+			createIndexStep{
+				Collection: BLOG_COLLECTION,
+				Model: mongo.IndexModel{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "publishAt", Value: -1}},
+					Options: options.Index().SetName("status_publishAt"),
+				},
+			},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, "status_publishAt"},
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV1()},
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_blog_revisions",
+		Up: []migrationStep{
+			createCollectionStep{REVISION_COLLECTION, revisionSchemaV4()},
+			collModValidatorStep{REVISION_COLLECTION, revisionSchemaV4()},
+			createIndexStep{REVISION_COLLECTION, getRevisionIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{REVISION_COLLECTION, "postId_revision"},
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_blog_trash",
+		Up: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV5()},
+			createIndexStep{
+				Collection: BLOG_COLLECTION,
+				Model: mongo.IndexModel{
+					Keys:    bson.M{"deletedAt": -1},
+					Options: options.Index().SetSparse(true).SetName("deletedAt"),
+				},
+			},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, "deletedAt"},
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV3()},
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add_blog_search",
+		Up: []migrationStep{
+			createIndexStep{BLOG_COLLECTION, getBlogTextIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, BLOG_TEXT_INDEX},
+		},
+	},
+	{
+		Version: 7,
+		Name:    "add_blog_tags_index",
+		Up: []migrationStep{
+			createIndexStep{BLOG_COLLECTION, getBlogTagsIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, BLOG_TAGS_INDEX},
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_blog_author_index",
+		Up: []migrationStep{
+			createIndexStep{BLOG_COLLECTION, getBlogAuthorIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, BLOG_AUTHOR_INDEX},
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add_blog_cursor_index",
+		Up: []migrationStep{
+			createIndexStep{BLOG_COLLECTION, getBlogCursorIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, BLOG_CURSOR_INDEX},
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_blog_body_html",
+		Up: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV10()},
+		},
+		Down: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV5()},
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add_blog_summary",
+		Up: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV11()},
+		},
+		Down: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV10()},
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_blog_old_slugs",
+		Up: []migrationStep{
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV12()},
+			createIndexStep{BLOG_COLLECTION, getBlogOldSlugsIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{BLOG_COLLECTION, BLOG_OLD_SLUGS_INDEX},
+			collModValidatorStep{BLOG_COLLECTION, blogSchemaV11()},
+		},
+	},
+	{
+		Version: 13,
+		Name:    "add_api_keys",
+		Up: []migrationStep{
+			createCollectionStep{API_KEY_COLLECTION, apiKeySchemaV13()},
+			collModValidatorStep{API_KEY_COLLECTION, apiKeySchemaV13()},
+			createIndexStep{API_KEY_COLLECTION, getApiKeyHashIndexModel()},
+		},
+		Down: []migrationStep{
+			dropIndexStep{API_KEY_COLLECTION, API_KEY_HASH_INDEX},
+		},
+	},
+}
+
+func latestMigrationVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+func (mdbc *MongoDbController) getMigrationVersion(ctx context.Context) (int, error) {
+	collection := mdbc.MongoClient.Database(mdbc.dbName).Collection(MIGRATIONS_COLLECTION)
+
+	opts := options.FindOne().SetSort(bson.M{"_id": -1})
+
+	var result struct {
+		Version int `bson:"_id"`
+	}
+
+	findErr := collection.FindOne(ctx, bson.M{}, opts).Decode(&result)
+
+	if findErr == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+
+	if findErr != nil {
+		return 0, findErr
+	}
+
+	return result.Version, nil
+}
+
+// runMigration runs the Up or Down steps of a migration, then records the
+// result in the migrations collection. Nothing is changed when dryRun is true.
+func (mdbc *MongoDbController) runMigration(ctx context.Context, m mongoMigration, up bool, dryRun bool) error {
+	db := mdbc.MongoClient.Database(mdbc.dbName)
+
+	steps := m.Down
+	if up {
+		steps = m.Up
+	}
+
+	for _, step := range steps {
+		if dryRun {
+			print("  Would " + step.Describe() + "\n")
+			continue
+		}
+
+		print("  " + step.Describe() + "\n")
+
+		if stepErr := step.Run(ctx, db); stepErr != nil {
+			return stepErr
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	collection := db.Collection(MIGRATIONS_COLLECTION)
+
+	if !up {
+		_, deleteErr := collection.DeleteOne(ctx, bson.M{"_id": m.Version})
+		return deleteErr
+	}
+
+	_, insertErr := collection.InsertOne(ctx, bson.M{
+		"_id":       m.Version,
+		"name":      m.Name,
+		"appliedAt": time.Now(),
+	})
+
+	// Another server recorded the migration first
+	if insertErr != nil && strings.Contains(insertErr.Error(), "duplicate key error") {
+		return nil
+	}
+
+	return insertErr
+}
+
+// GetMigrationVersion returns the version of the latest migration applied to
+// the database, or 0 if no migrations have been applied. Reading from a
+// missing migrations collection doesn't create it, so this is read only.
+func (mdbc *MongoDbController) GetMigrationVersion() (int, error) {
+	backCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	version, versionErr := mdbc.getMigrationVersion(backCtx)
+
+	if versionErr != nil {
+		return 0, dbController.NewDBError(versionErr.Error())
+	}
+
+	return version, nil
+}
+
+// MigrateTo applies the Up migrations above the current version up to and
+// including targetVersion, or applies the Down migrations above targetVersion
+// in reverse order. Passing dbController.LATEST_MIGRATION applies every
+// migration. When dryRun is true, the steps are printed, but not run.
+func (mdbc *MongoDbController) MigrateTo(targetVersion int, dryRun bool) error {
+	if targetVersion == dbController.LATEST_MIGRATION {
+		targetVersion = latestMigrationVersion()
+	}
+
+	if targetVersion < 0 || targetVersion > latestMigrationVersion() {
+		return dbController.NewInvalidInputError(fmt.Sprintf("invalid migration version %d", targetVersion))
+	}
+
+	backCtx, cancel := context.WithTimeout(context.Background(), MIGRATION_TIMEOUT)
+	defer cancel()
+
+	currentVersion, versionErr := mdbc.getMigrationVersion(backCtx)
+
+	if versionErr != nil {
+		return dbController.NewDBError(versionErr.Error())
+	}
+
+	if currentVersion < targetVersion {
+		for _, m := range migrations {
+			if m.Version <= currentVersion || m.Version > targetVersion {
+				continue
+			}
+
+			print(fmt.Sprintf("Applying migration %d %s\n", m.Version, m.Name))
+
+			if migrateErr := mdbc.runMigration(backCtx, m, true, dryRun); migrateErr != nil {
+				msg := fmt.Sprintf("error applying migration %d %s: %s", m.Version, m.Name, migrateErr.Error())
+				return dbController.NewDBError(msg)
+			}
+		}
+	}
+
+	if currentVersion > targetVersion {
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+
+			if m.Version > currentVersion || m.Version <= targetVersion {
+				continue
+			}
+
+			print(fmt.Sprintf("Reverting migration %d %s\n", m.Version, m.Name))
+
+			if migrateErr := mdbc.runMigration(backCtx, m, false, dryRun); migrateErr != nil {
+				msg := fmt.Sprintf("error reverting migration %d %s: %s", m.Version, m.Name, migrateErr.Error())
+				return dbController.NewDBError(msg)
+			}
+		}
+	}
+
+	return nil
+}
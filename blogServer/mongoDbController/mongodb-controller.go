@@ -2,7 +2,6 @@ package mongoDbController
 
 import (
 	"context"
-	"errors"
 	"strings"
 	"time"
 
@@ -37,12 +36,10 @@ func (mdbc *MongoDbController) getCollection(collectionName string) (*mongo.Coll
 	return collection, backCtx, cancel
 }
 
-func (mdbc *MongoDbController) initBlogCollection(dbName string) error {
-	db := mdbc.MongoClient.Database(dbName)
-
-	jsonSchema := bson.M{
+func getBlogJsonSchema() bson.M {
+	return bson.M{
 		"bsonType": "object",
-		"required": []string{"title", "slug", "authorId", "dateAdded", "updateAuthorId", "dateUpdated"},
+		"required": []string{"title", "slug", "authorId", "dateAdded", "updateAuthorId", "dateUpdated", "status", "publishAt"},
 		"properties": bson.M{
 			"title": bson.M{
 				"bsonType":    "string",
@@ -56,10 +53,34 @@ func (mdbc *MongoDbController) initBlogCollection(dbName string) error {
 				"bsonType":    "string",
 				"description": "body must be a string",
 			},
+			"bodyHtml": bson.M{
+				"bsonType":    "string",
+				"description": "bodyHtml must be a string",
+			},
+			"excerpt": bson.M{
+				"bsonType":    "string",
+				"description": "excerpt must be a string",
+			},
+			"customExcerpt": bson.M{
+				"bsonType":    "bool",
+				"description": "customExcerpt must be a boolean",
+			},
+			"wordCount": bson.M{
+				"bsonType":    []string{"int", "long"},
+				"description": "wordCount must be an integer",
+			},
+			"readingTime": bson.M{
+				"bsonType":    []string{"int", "long"},
+				"description": "readingTime must be an integer",
+			},
 			"tags": bson.M{
 				"bsonType":    "array",
 				"description": "tags must be an array",
 			},
+			"oldSlugs": bson.M{
+				"bsonType":    "array",
+				"description": "oldSlugs must be an array",
+			},
 			"authorId": bson.M{
 				"bsonType":    "string",
 				"description": "authorId must be a string",
@@ -76,8 +97,54 @@ func (mdbc *MongoDbController) initBlogCollection(dbName string) error {
 				"bsonType":    "timestamp",
 				"description": "dateUpdated must be a timestamp",
 			},
+			"status": bson.M{
+				"enum": []string{
+					dbController.BLOG_STATUS_DRAFT,
+					dbController.BLOG_STATUS_SCHEDULED,
+					dbController.BLOG_STATUS_PUBLISHED,
+					dbController.BLOG_STATUS_ARCHIVED,
+				},
+				"description": "status must be draft, scheduled, published or archived",
+			},
+			"publishAt": bson.M{
+				"bsonType":    "timestamp",
+				"description": "publishAt must be a timestamp",
+			},
+			"deletedAt": bson.M{
+				"bsonType":    "timestamp",
+				"description": "deletedAt must be a timestamp",
+			},
+			"deletedBy": bson.M{
+				"bsonType":    "string",
+				"description": "deletedBy must be a string",
+			},
+		},
+	}
+}
+
+// getBlogTextIndexModel returns the text index used by SearchBlogPosts. A
+// collection can only have one text index, so it covers every searched field.
+func getBlogTextIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "tags", Value: "text"},
+			{Key: "body", Value: "text"},
 		},
+		Options: options.Index().
+			SetName(BLOG_TEXT_INDEX).
+			SetWeights(bson.M{
+				"title": int32(dbController.SEARCH_TITLE_WEIGHT),
+				"tags":  int32(dbController.SEARCH_TAG_WEIGHT),
+				"body":  int32(dbController.SEARCH_BODY_WEIGHT),
+			}),
 	}
+}
+
+func (mdbc *MongoDbController) initBlogCollection(dbName string) error {
+	db := mdbc.MongoClient.Database(dbName)
+
+	jsonSchema := getBlogJsonSchema()
 
 	colOpts := options.CreateCollection().SetValidator(bson.M{"$jsonSchema": jsonSchema})
 
@@ -92,6 +159,11 @@ func (mdbc *MongoDbController) initBlogCollection(dbName string) error {
 			Keys:    bson.M{"slug": 1},
 			Options: options.Index().SetUnique(true),
 		},
+		getBlogTextIndexModel(),
+		getBlogTagsIndexModel(),
+		getBlogAuthorIndexModel(),
+		getBlogCursorIndexModel(),
+		getBlogOldSlugsIndexModel(),
 	}
 
 	opts := options.CreateIndexes().SetMaxTime(2 * time.Second)
@@ -106,10 +178,8 @@ func (mdbc *MongoDbController) initBlogCollection(dbName string) error {
 	return nil
 }
 
-func (mdbc *MongoDbController) initUserCollection(dbName string) error {
-	db := mdbc.MongoClient.Database(dbName)
-
-	jsonSchema := bson.M{
+func getUserJsonSchema() bson.M {
+	return bson.M{
 		"bsonType": "object",
 		// "required": []string{"uid"},
 		"required": []string{"uid", "name", "email", "active", "role"},
@@ -136,6 +206,12 @@ func (mdbc *MongoDbController) initUserCollection(dbName string) error {
 			},
 		},
 	}
+}
+
+func (mdbc *MongoDbController) initUserCollection(dbName string) error {
+	db := mdbc.MongoClient.Database(dbName)
+
+	jsonSchema := getUserJsonSchema()
 
 	colOpts := options.CreateCollection().SetValidator(bson.M{"$jsonSchema": jsonSchema})
 
@@ -212,27 +288,50 @@ func (mdbc *MongoDbController) InitDatabase() error {
 		return userCreationErr
 	}
 
+	revisionCreationErr := mdbc.initRevisionCollection(mdbc.dbName)
+
+	if revisionCreationErr != nil && !strings.Contains(revisionCreationErr.Error(), "Collection already exists") {
+		return revisionCreationErr
+	}
+
+	apiKeyCreationErr := mdbc.initApiKeyCollection(mdbc.dbName)
+
+	if apiKeyCreationErr != nil && !strings.Contains(apiKeyCreationErr.Error(), "Collection already exists") {
+		return apiKeyCreationErr
+	}
+
 	loggingCreationErr := mdbc.initLoggingCollection(mdbc.dbName)
 
 	if loggingCreationErr != nil && !strings.Contains(loggingCreationErr.Error(), "Collection already exists") {
 		return loggingCreationErr
 	}
 
-	return nil
+	// Collections that already existed keep their old validators and indexes
+	// until the migrations bring them up to date.
+	return mdbc.MigrateTo(dbController.LATEST_MIGRATION, false)
 }
 
 func (mdbc *MongoDbController) AddBlogPost(doc *dbController.AddBlogDocument) (string, error) {
 	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
 	defer cancel()
 
+	if slugErr := checkOldSlug(backCtx, collection, doc.Slug, nil); slugErr != nil {
+		return "", slugErr
+	}
+
 	dateAdded := primitive.Timestamp{T: uint32(doc.DateAdded.Unix())}
 
 	insert := bson.M{
-		"title":     doc.Title,
-		"slug":      doc.Slug,
-		"body":      doc.Body,
-		"authorId":  doc.AuthorId,
-		"dateAdded": dateAdded,
+		"title":         doc.Title,
+		"slug":          doc.Slug,
+		"body":          doc.Body,
+		"bodyHtml":      doc.BodyHtml,
+		"excerpt":       doc.Summary.Excerpt,
+		"customExcerpt": doc.Summary.CustomExcerpt,
+		"wordCount":     doc.Summary.WordCount,
+		"readingTime":   doc.Summary.ReadingTimeMinutes,
+		"authorId":      doc.AuthorId,
+		"dateAdded":     dateAdded,
 	}
 
 	if doc.Tags != nil {
@@ -251,6 +350,18 @@ func (mdbc *MongoDbController) AddBlogPost(doc *dbController.AddBlogDocument) (s
 		insert["dateUpdated"] = dateAdded
 	}
 
+	if doc.Status != nil {
+		insert["status"] = *doc.Status
+	} else {
+		insert["status"] = dbController.BLOG_STATUS_PUBLISHED
+	}
+
+	if doc.PublishAt != nil {
+		insert["publishAt"] = primitive.Timestamp{T: uint32((*doc.PublishAt).Unix())}
+	} else {
+		insert["publishAt"] = dateAdded
+	}
+
 	insertResult, mdbErr := collection.InsertOne(backCtx, insert)
 
 	if mdbErr != nil {
@@ -275,6 +386,12 @@ func (mdbc *MongoDbController) AddBlogPost(doc *dbController.AddBlogDocument) (s
 		return "", dbController.NewDBError("invalid id returned by database")
 	}
 
+	// The blog post has already been added, so a missing revision is only
+	// reported. The next edit saves the previous state as the first revision.
+	if revisionErr := mdbc.saveRevision(objectId.Hex(), nil); revisionErr != nil {
+		print("Add blog revision error. Error: " + revisionErr.Error() + "\n")
+	}
+
 	return objectId.Hex(), nil
 }
 
@@ -283,6 +400,11 @@ func (mdbc *MongoDbController) GetAggregationStages() (projectStage, authorLooku
 		{
 			Key: "$project", Value: bson.M{
 				"body":           1,
+				"bodyHtml":       1,
+				"excerpt":        1,
+				"customExcerpt":  1,
+				"wordCount":      1,
+				"readingTime":    1,
 				"slug":           1,
 				"title":          1,
 				"authorId":       1,
@@ -290,6 +412,10 @@ func (mdbc *MongoDbController) GetAggregationStages() (projectStage, authorLooku
 				"dateAdded":      1,
 				"dateUpdated":    1,
 				"tags":           1,
+				"status":         1,
+				"publishAt":      1,
+				"deletedAt":      1,
+				"deletedBy":      1,
 			},
 		},
 	}
@@ -317,6 +443,31 @@ func (mdbc *MongoDbController) GetAggregationStages() (projectStage, authorLooku
 	return &ps, &als, &uals
 }
 
+// GetArchiveAggregationStages returns the stages that group blog posts by the
+// year and month of dateAdded, in UTC, and sort the groups newest first.
+func (mdbc *MongoDbController) GetArchiveAggregationStages() (groupStage, sortStage *bson.D) {
+	gs := bson.D{{
+		Key: "$group",
+		Value: bson.M{
+			"_id": bson.M{
+				"year":  bson.M{"$year": "$dateAdded"},
+				"month": bson.M{"$month": "$dateAdded"},
+			},
+			"count": bson.M{"$sum": 1},
+		},
+	}}
+
+	ss := bson.D{{
+		Key: "$sort",
+		Value: bson.D{
+			{Key: "_id.year", Value: -1},
+			{Key: "_id.month", Value: -1},
+		},
+	}}
+
+	return &gs, &ss
+}
+
 func (mdbc *MongoDbController) GetBlogPostWithMatcher(matchStage *bson.D) (*dbController.BlogDocument, error) {
 	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
 	defer cancel()
@@ -354,33 +505,92 @@ func (mdbc *MongoDbController) GetBlogPostWithMatcher(matchStage *bson.D) (*dbCo
 	return post, nil
 }
 
-func (mdbc *MongoDbController) GetBlogPostById(id string) (*dbController.BlogDocument, error) {
+// getVisibilityFilter returns the filter that limits blog posts to the ones the
+// public can see, or only leaves out posts in the trash if includeUnpublished
+// is true.
+func (mdbc *MongoDbController) getVisibilityFilter(includeUnpublished bool) bson.M {
+	if includeUnpublished {
+		return bson.M{"deletedAt": bson.M{"$exists": false}}
+	}
+
+	return bson.M{
+		"deletedAt": bson.M{"$exists": false},
+		"status": bson.M{"$in": []string{
+			dbController.BLOG_STATUS_PUBLISHED,
+			dbController.BLOG_STATUS_SCHEDULED,
+		}},
+		"publishAt": bson.M{"$lte": primitive.Timestamp{T: uint32(time.Now().Unix())}},
+	}
+}
+
+// addBlogPostFilter adds the fields of the BlogPostFilter that are set to the
+// filter.
+func (mdbc *MongoDbController) addBlogPostFilter(filter bson.M, postFilter *dbController.BlogPostFilter) bson.M {
+	if postFilter == nil {
+		return filter
+	}
+
+	// Matching a value against an array field matches any element of it
+	if len(postFilter.Tag) > 0 {
+		filter["tags"] = postFilter.Tag
+	}
+
+	if len(postFilter.AuthorId) > 0 {
+		filter["authorId"] = postFilter.AuthorId
+	}
+
+	dateAdded := bson.M{}
+
+	if postFilter.DateAddedStart != nil {
+		dateAdded["$gte"] = primitive.Timestamp{T: uint32(postFilter.DateAddedStart.Unix())}
+	}
+
+	if postFilter.DateAddedEnd != nil {
+		dateAdded["$lt"] = primitive.Timestamp{T: uint32(postFilter.DateAddedEnd.Unix())}
+	}
+
+	if len(dateAdded) > 0 {
+		filter["dateAdded"] = dateAdded
+	}
+
+	return filter
+}
+
+func (mdbc *MongoDbController) GetBlogPostById(id string, includeUnpublished bool) (*dbController.BlogDocument, error) {
 	idObj, idObjErr := primitive.ObjectIDFromHex(id)
 
 	if idObjErr != nil {
 		return nil, dbController.NewInvalidInputError("invalid id")
 	}
 
-	matchStage := bson.D{{Key: "$match", Value: bson.M{
-		"_id": idObj,
-	}}}
+	filter := mdbc.getVisibilityFilter(includeUnpublished)
+	filter["_id"] = idObj
+
+	matchStage := bson.D{{Key: "$match", Value: filter}}
 
 	return mdbc.GetBlogPostWithMatcher(&matchStage)
 }
 
-func (mdbc *MongoDbController) GetBlogPostBySlug(slug string) (*dbController.BlogDocument, error) {
-	matchStage := bson.D{{Key: "$match", Value: bson.M{
-		"slug": slug,
-	}}}
+func (mdbc *MongoDbController) GetBlogPostBySlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	filter := mdbc.getVisibilityFilter(includeUnpublished)
+	filter["slug"] = slug
+
+	matchStage := bson.D{{Key: "$match", Value: filter}}
 
 	return mdbc.GetBlogPostWithMatcher(&matchStage)
 }
 
-func (mdbc *MongoDbController) GetBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
+func (mdbc *MongoDbController) GetBlogPosts(page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	return mdbc.getBlogPostsWithFilter(mdbc.getVisibilityFilter(includeUnpublished), page, pagination)
+}
+
+// getBlogPostsWithFilter returns a page of the blog posts that match the
+// filter, newest first. The filter should include the visibility filter.
+func (mdbc *MongoDbController) getBlogPostsWithFilter(filter bson.M, page int, pagination int) ([]*dbController.BlogDocument, error) {
 	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
 	defer cancel()
 
-	matchStage := bson.D{{Key: "$match", Value: bson.M{}}}
+	matchStage := bson.D{{Key: "$match", Value: filter}}
 
 	projectStage, authorLookupStage, updateAuthorLookupStage := mdbc.GetAggregationStages()
 
@@ -412,12 +622,12 @@ func (mdbc *MongoDbController) GetBlogPosts(page int, pagination int) ([]*dbCont
 	})
 
 	if aggErr != nil {
-		return nil, dbController.NewDBError("")
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
 	}
 
 	var results []BlogDocResult
 	if allErr := cursor.All(backCtx, &results); allErr != nil {
-		return nil, errors.New("error parsing results")
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
 	}
 
 	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
@@ -439,7 +649,7 @@ func (mdbc *MongoDbController) EditBlogPost(doc *dbController.EditBlogDocument)
 		return dbController.NewInvalidInputError("Invalid User ID")
 	}
 
-	filter := bson.M{"_id": id}
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}}
 
 	values := bson.M{}
 
@@ -455,6 +665,17 @@ func (mdbc *MongoDbController) EditBlogPost(doc *dbController.EditBlogDocument)
 		values["body"] = *doc.Body
 	}
 
+	if doc.BodyHtml != nil {
+		values["bodyHtml"] = *doc.BodyHtml
+	}
+
+	if doc.Summary != nil {
+		values["excerpt"] = doc.Summary.Excerpt
+		values["customExcerpt"] = doc.Summary.CustomExcerpt
+		values["wordCount"] = doc.Summary.WordCount
+		values["readingTime"] = doc.Summary.ReadingTimeMinutes
+	}
+
 	if doc.Tags != nil {
 		values["tags"] = *doc.Tags
 	}
@@ -463,23 +684,55 @@ func (mdbc *MongoDbController) EditBlogPost(doc *dbController.EditBlogDocument)
 		values["authorId"] = *doc.AuthorId
 	}
 
-	if doc.DateUpdated != nil {
+	if doc.DateAdded != nil {
 		values["dateAdded"] = primitive.Timestamp{T: uint32((*doc.DateAdded).Unix())}
 	}
 
 	if doc.UpdateAuthorId != nil {
-		values["updateAuthorId"] = doc.UpdateAuthorId
+		values["updateAuthorId"] = *doc.UpdateAuthorId
 	}
 
 	if doc.DateUpdated != nil {
 		values["dateUpdated"] = primitive.Timestamp{T: uint32((*doc.DateUpdated).Unix())}
 	}
 
-	update := bson.M{
-		"$set": values,
+	if doc.Status != nil {
+		values["status"] = *doc.Status
+	}
+
+	if doc.PublishAt != nil {
+		values["publishAt"] = primitive.Timestamp{T: uint32((*doc.PublishAt).Unix())}
+	}
+
+	// The update is a pipeline, so that the old slugs can be worked out from
+	// the slug the post has. Values are literals, so that strings starting
+	// with $ aren't read as field paths.
+	stage := bson.M{}
+	for key, value := range values {
+		stage[key] = bson.M{"$literal": value}
+	}
+
+	if doc.Slug != nil {
+		if slugErr := checkOldSlug(backCtx, collection, *doc.Slug, &id); slugErr != nil {
+			return slugErr
+		}
+
+		stage["oldSlugs"] = getOldSlugsExpression(*doc.Slug)
+	}
+
+	update := bson.A{
+		bson.M{"$set": stage},
 	}
 
-	result, mdbErr := collection.UpdateOne(backCtx, filter, update)
+	// The previous state of the post is needed for the revision
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var previous BlogDocResult
+	mdbErr := collection.FindOneAndUpdate(backCtx, filter, update, opts).Decode(&previous)
+
+	if mdbErr == mongo.ErrNoDocuments {
+		return dbController.NewInvalidInputError("id did not match any blog posts")
+	}
 
 	if mdbErr != nil {
 		err := mdbErr.Error()
@@ -497,8 +750,9 @@ func (mdbc *MongoDbController) EditBlogPost(doc *dbController.EditBlogDocument)
 		return dbController.NewDBError(mdbErr.Error())
 	}
 
-	if result.MatchedCount == 0 {
-		return dbController.NewInvalidInputError("id did not match any blog posts")
+	// The edit has already been made, so a missing revision is only reported
+	if revisionErr := mdbc.saveRevision(doc.Id, previous.GetBlogDocument()); revisionErr != nil {
+		print("Edit blog revision error. Error: " + revisionErr.Error() + "\n")
 	}
 
 	return nil
@@ -515,21 +769,28 @@ func (mdbc *MongoDbController) DeleteBlogPost(doc *dbController.DeleteBlogDocume
 		return dbController.NewInvalidInputError("Invalid User ID")
 	}
 
-	delResult, delErr := collection.DeleteOne(
+	delResult, delErr := collection.UpdateOne(
 		backCtx,
 		bson.M{
-			"_id": id,
+			"_id":       id,
+			"deletedAt": bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{
+				"deletedAt": primitive.Timestamp{T: uint32(time.Now().Unix())},
+				"deletedBy": doc.DeletedBy,
+			},
 		},
 	)
 
-	if delResult.DeletedCount == 0 {
-		return dbController.NewInvalidInputError("invalid id. no blog posts deleted")
-	}
-
 	if delErr != nil {
 		return dbController.NewDBError(delErr.Error())
 	}
 
+	if delResult.MatchedCount == 0 {
+		return dbController.NewInvalidInputError("invalid id. no blog posts deleted")
+	}
+
 	return nil
 }
 
@@ -549,12 +810,18 @@ func (mdbc *MongoDbController) AddUserInformation(info *user.UserInformation) er
 
 	opts := options.Update().SetUpsert(true)
 
-	filter := bson.M{}
+	filter := bson.M{"uid": info.Uid}
 
 	updateResult, mdbErr := collection.UpdateOne(backCtx, filter, update, opts)
 
 	if mdbErr != nil {
-		return mdbErr
+		err := mdbErr.Error()
+
+		if strings.Contains(err, "duplicate key error") {
+			return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + info.Email + "' already exists.")
+		}
+
+		return dbController.NewDBError(err)
 	}
 
 	if updateResult.MatchedCount == 0 && updateResult.UpsertedCount == 0 {
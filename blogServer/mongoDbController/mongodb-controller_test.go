@@ -0,0 +1,78 @@
+package mongoDbController
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/dbController/conformance"
+)
+
+// MONGO_TEST_DB_URL is the full connection URL of a MongoDB server the
+// conformance suite can use, e.g. mongodb://localhost:27017. The suite is
+// skipped when it isn't set. Every test runs in its own database, which is
+// dropped afterwards.
+const MONGO_TEST_DB_URL = "MONGO_TEST_DB_URL"
+
+func TestConformance(t *testing.T) {
+	dbUrl := os.Getenv(MONGO_TEST_DB_URL)
+
+	if len(dbUrl) == 0 {
+		t.Skip(MONGO_TEST_DB_URL + " is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, connectErr := mongo.Connect(ctx, options.Client().ApplyURI(dbUrl))
+
+	if connectErr != nil {
+		t.Fatalf("mongo.Connect: %v", connectErr)
+	}
+	defer client.Disconnect(context.Background())
+
+	count := 0
+
+	conformance.RunConformanceSuite(t, func(t *testing.T) dbController.DatabaseController {
+		count++
+		dbName := fmt.Sprintf("conformance_%d_%d", time.Now().UnixNano(), count)
+
+		t.Cleanup(func() {
+			if dropErr := client.Database(dbName).Drop(context.Background()); dropErr != nil {
+				t.Errorf("Drop: %v", dropErr)
+			}
+		})
+
+		return &MongoDbController{client, dbName}
+	})
+}
+
+// InitDatabase creates collections with the current validators, while the
+// migrations set the frozen ones. They have to agree at the latest version, or
+// a migrated database would validate differently from a new one.
+func TestLatestMigrationSchemas(t *testing.T) {
+	schemas := []struct {
+		name    string
+		current bson.M
+		frozen  bson.M
+	}{
+		{BLOG_COLLECTION, getBlogJsonSchema(), blogSchemaV12()},
+		{USER_COLLECTION, getUserJsonSchema(), userSchemaV1()},
+		{REVISION_COLLECTION, getRevisionJsonSchema(), revisionSchemaV4()},
+		{API_KEY_COLLECTION, getApiKeyJsonSchema(), apiKeySchemaV13()},
+	}
+
+	for _, schema := range schemas {
+		if !reflect.DeepEqual(schema.current, schema.frozen) {
+			t.Errorf("the %s validator doesn't match its latest migration schema", schema.name)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package mongoDbController
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const REVISION_COLLECTION = "blogRevisions"
+
+func getRevisionJsonSchema() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"postId", "revision", "title", "slug", "authorId", "dateAdded", "updateAuthorId", "dateUpdated", "status", "publishAt", "changedFields"},
+		"properties": bson.M{
+			"postId": bson.M{
+				"bsonType":    "string",
+				"description": "postId must be a string",
+			},
+			"revision": bson.M{
+				"bsonType":    []string{"int", "long"},
+				"description": "revision must be an integer",
+			},
+			"dateAdded": bson.M{
+				"bsonType":    "timestamp",
+				"description": "dateAdded must be a timestamp",
+			},
+			"dateUpdated": bson.M{
+				"bsonType":    "timestamp",
+				"description": "dateUpdated must be a timestamp",
+			},
+			"publishAt": bson.M{
+				"bsonType":    "timestamp",
+				"description": "publishAt must be a timestamp",
+			},
+			"changedFields": bson.M{
+				"bsonType":    "array",
+				"description": "changedFields must be an array",
+			},
+		},
+	}
+}
+
+func getRevisionIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "postId", Value: 1}, {Key: "revision", Value: -1}},
+		Options: options.Index().SetUnique(true).SetName("postId_revision"),
+	}
+}
+
+func (mdbc *MongoDbController) initRevisionCollection(dbName string) error {
+	db := mdbc.MongoClient.Database(dbName)
+
+	colOpts := options.CreateCollection().SetValidator(bson.M{"$jsonSchema": getRevisionJsonSchema()})
+
+	createCollectionErr := db.CreateCollection(context.TODO(), REVISION_COLLECTION, colOpts)
+
+	if createCollectionErr != nil {
+		return dbController.NewDBError(createCollectionErr.Error())
+	}
+
+	opts := options.CreateIndexes().SetMaxTime(2 * time.Second)
+
+	collection, _, _ := mdbc.getCollection(REVISION_COLLECTION)
+	_, setIndexErr := collection.Indexes().CreateOne(context.TODO(), getRevisionIndexModel(), opts)
+
+	if setIndexErr != nil {
+		return dbController.NewDBError(setIndexErr.Error())
+	}
+
+	return nil
+}
+
+func insertRevision(backCtx context.Context, collection *mongo.Collection, rev *dbController.BlogRevision) error {
+	tags := rev.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	_, insertErr := collection.InsertOne(backCtx, bson.M{
+		"postId":         rev.PostId,
+		"revision":       rev.Revision,
+		"title":          rev.Title,
+		"slug":           rev.Slug,
+		"body":           rev.Body,
+		"tags":           tags,
+		"authorId":       rev.AuthorId,
+		"dateAdded":      primitive.Timestamp{T: uint32(rev.DateAdded.Unix())},
+		"updateAuthorId": rev.UpdateAuthorId,
+		"dateUpdated":    primitive.Timestamp{T: uint32(rev.DateUpdated.Unix())},
+		"status":         rev.Status,
+		"publishAt":      primitive.Timestamp{T: uint32(rev.PublishAt.Unix())},
+		"changedFields":  rev.ChangedFields,
+	})
+
+	return insertErr
+}
+
+// saveRevision saves the current state of the blog post as its next revision.
+// previous is the state of the post before an edit, or nil for a new post.
+// Posts added before revisions were saved get their previous state saved as
+// the first revision. The unique postId_revision index makes one of two
+// concurrent edits fail to save its revision, rather than both saving the
+// same revision number.
+func (mdbc *MongoDbController) saveRevision(postId string, previous *dbController.BlogDocument) error {
+	post, postErr := mdbc.GetBlogPostById(postId, true)
+
+	if postErr != nil {
+		return postErr
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(REVISION_COLLECTION)
+	defer cancel()
+
+	var latest struct {
+		Revision int `bson:"revision"`
+	}
+
+	opts := options.FindOne().SetSort(bson.M{"revision": -1})
+	findErr := collection.FindOne(backCtx, bson.M{"postId": postId}, opts).Decode(&latest)
+
+	if findErr != nil && findErr != mongo.ErrNoDocuments {
+		return findErr
+	}
+
+	if latest.Revision == 0 && previous != nil {
+		if insertErr := insertRevision(backCtx, collection, dbController.NewBlogRevision(previous, 1, nil)); insertErr != nil {
+			return insertErr
+		}
+
+		latest.Revision = 1
+	}
+
+	return insertRevision(backCtx, collection, dbController.NewBlogRevision(post, latest.Revision+1, previous))
+}
+
+func (mdbc *MongoDbController) findRevisions(filter bson.M, opts *options.FindOptions) ([]*dbController.BlogRevision, error) {
+	collection, backCtx, cancel := mdbc.getCollection(REVISION_COLLECTION)
+	defer cancel()
+
+	cursor, findErr := collection.Find(backCtx, filter, opts)
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	var results []BlogRevisionResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	revisions := make([]*dbController.BlogRevision, 0)
+	for _, v := range results {
+		revisions = append(revisions, v.GetBlogRevision())
+	}
+
+	return revisions, nil
+}
+
+func (mdbc *MongoDbController) GetBlogPostRevisions(postId string) ([]*dbController.BlogRevision, error) {
+	if _, idErr := primitive.ObjectIDFromHex(postId); idErr != nil {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	opts := options.Find().SetSort(bson.M{"revision": -1})
+
+	return mdbc.findRevisions(bson.M{"postId": postId}, opts)
+}
+
+func (mdbc *MongoDbController) GetBlogPostRevision(postId string, revision int) (*dbController.BlogRevision, error) {
+	if _, idErr := primitive.ObjectIDFromHex(postId); idErr != nil {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	revisions, revisionsErr := mdbc.findRevisions(bson.M{"postId": postId, "revision": revision}, options.Find().SetLimit(1))
+
+	if revisionsErr != nil {
+		return nil, revisionsErr
+	}
+
+	if len(revisions) < 1 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return revisions[0], nil
+}
@@ -0,0 +1,115 @@
+package mongoDbController
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const BLOG_TEXT_INDEX = "blog_text"
+
+// getSearchFilter returns the visible blog posts that match any of the terms.
+// The terms are joined back together so that quotes and minus signs in the
+// query aren't treated as phrase or negation operators.
+func (mdbc *MongoDbController) getSearchFilter(terms []string, includeUnpublished bool) bson.M {
+	filter := mdbc.getVisibilityFilter(includeUnpublished)
+	filter["$text"] = bson.M{"$search": strings.Join(terms, " ")}
+
+	return filter
+}
+
+// SearchBlogPosts uses the text index on the blog collection
+func (mdbc *MongoDbController) SearchBlogPosts(query string, page int, pagination int, includeUnpublished bool) ([]*dbController.SearchResult, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	// $text has to be in the first stage of the pipeline
+	filter := mdbc.getSearchFilter(terms, includeUnpublished)
+
+	matchStage := bson.D{{Key: "$match", Value: filter}}
+
+	projectStage, authorLookupStage, updateAuthorLookupStage := mdbc.GetAggregationStages()
+
+	projection := (*projectStage)[0].Value.(bson.M)
+	projection["score"] = bson.M{"$meta": "textScore"}
+
+	sortStage := bson.D{{
+		Key: "$sort",
+		Value: bson.D{
+			{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			{Key: "dateAdded", Value: -1},
+		},
+	}}
+
+	limitStage := bson.D{{
+		Key:   "$limit",
+		Value: int32(pagination),
+	}}
+
+	skipStage := bson.D{{
+		Key:   "$skip",
+		Value: int64((page - 1) * pagination),
+	}}
+
+	cursor, aggErr := collection.Aggregate(backCtx, mongo.Pipeline{
+		matchStage,
+		*projectStage,
+		sortStage,
+		skipStage,
+		limitStage,
+		*authorLookupStage,
+		*updateAuthorLookupStage,
+	})
+
+	if aggErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
+	}
+
+	var docs []SearchResultDoc
+	if allErr := cursor.All(backCtx, &docs); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	results := make([]*dbController.SearchResult, 0)
+	for _, v := range docs {
+		post := v.GetBlogDocument()
+
+		results = append(results, &dbController.SearchResult{
+			Post:    post,
+			Score:   v.Score,
+			Snippet: dbController.MakeSearchSnippet(post.Body, terms),
+		})
+	}
+
+	return results, nil
+}
+
+func (mdbc *MongoDbController) CountSearchResults(query string, includeUnpublished bool) (int, error) {
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return 0, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	count, countErr := collection.CountDocuments(backCtx, mdbc.getSearchFilter(terms, includeUnpublished))
+
+	if countErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + countErr.Error())
+	}
+
+	return int(count), nil
+}
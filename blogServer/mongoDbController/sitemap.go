@@ -0,0 +1,42 @@
+package mongoDbController
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (mdbc *MongoDbController) GetSitemapEntries(page int, pagination int) ([]*dbController.SitemapEntry, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	// The same order as getBlogPostsWithFilter, without reading the bodies
+	cursor, aggErr := collection.Aggregate(backCtx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: mdbc.getVisibilityFilter(false)}},
+		bson.D{{Key: "$project", Value: bson.M{"slug": 1, "dateAdded": 1, "dateUpdated": 1}}},
+		bson.D{{Key: "$sort", Value: bson.M{"dateAdded": -1}}},
+		bson.D{{Key: "$skip", Value: int64((page - 1) * pagination)}},
+		bson.D{{Key: "$limit", Value: int32(pagination)}},
+	})
+
+	if aggErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
+	}
+
+	var results []SitemapEntryResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	entries := make([]*dbController.SitemapEntry, 0)
+	for _, v := range results {
+		entries = append(entries, v.GetSitemapEntry())
+	}
+
+	return entries, nil
+}
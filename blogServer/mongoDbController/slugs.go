@@ -0,0 +1,109 @@
+package mongoDbController
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const BLOG_OLD_SLUGS_INDEX = "oldSlugs"
+
+// getBlogOldSlugsIndexModel returns the index used to find posts by the slugs
+// they had before. The slugs are kept in the oldSlugs array of each post.
+func getBlogOldSlugsIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "oldSlugs", Value: 1}},
+		Options: options.Index().SetName(BLOG_OLD_SLUGS_INDEX),
+	}
+}
+
+// checkOldSlug returns a DuplicateEntryError if a post other than excludeId
+// used to have the slug. New posts pass a nil excludeId.
+func checkOldSlug(backCtx context.Context, collection *mongo.Collection, slug string, excludeId *primitive.ObjectID) error {
+	filter := bson.M{"oldSlugs": slug}
+
+	if excludeId != nil {
+		filter["_id"] = bson.M{"$ne": *excludeId}
+	}
+
+	count, countErr := collection.CountDocuments(backCtx, filter)
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count > 0 {
+		msg := "Duplicate blog post. Slug '" + slug + "' was used by another blog post."
+		return dbController.NewDuplicateEntryError(msg)
+	}
+
+	return nil
+}
+
+// getOldSlugsExpression returns the oldSlugs of a post after its slug is set
+// to newSlug. The current slug is added if it changes, and a post going back
+// to one of its old slugs doesn't keep it as an old slug. It's used in the
+// same $set stage as the slug, so $slug is still the slug before the edit.
+func getOldSlugsExpression(newSlug string) bson.M {
+	oldSlugs := bson.M{"$ifNull": bson.A{"$oldSlugs", bson.A{}}}
+
+	keptSlugs := bson.M{
+		"$filter": bson.M{
+			"input": oldSlugs,
+			"cond": bson.M{"$and": bson.A{
+				bson.M{"$ne": bson.A{"$$this", bson.M{"$literal": newSlug}}},
+				bson.M{"$ne": bson.A{"$$this", "$slug"}},
+			}},
+		},
+	}
+
+	return bson.M{
+		"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$slug", bson.M{"$literal": newSlug}}},
+			oldSlugs,
+			bson.M{"$concatArrays": bson.A{keptSlugs, bson.A{"$slug"}}},
+		},
+	}
+}
+
+func (mdbc *MongoDbController) GetBlogPostByOldSlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	filter := mdbc.getVisibilityFilter(includeUnpublished)
+	filter["oldSlugs"] = slug
+
+	matchStage := bson.D{{Key: "$match", Value: filter}}
+
+	return mdbc.GetBlogPostWithMatcher(&matchStage)
+}
+
+func (mdbc *MongoDbController) IsSlugAvailable(slug string, id string) (bool, error) {
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"oldSlugs": slug},
+	}}
+
+	if len(id) > 0 {
+		objectId, idErr := primitive.ObjectIDFromHex(id)
+
+		if idErr != nil {
+			return false, dbController.NewInvalidInputError("invalid id")
+		}
+
+		filter["_id"] = bson.M{"$ne": objectId}
+	}
+
+	count, countErr := collection.CountDocuments(backCtx, filter)
+
+	if countErr != nil {
+		return false, dbController.NewDBError(countErr.Error())
+	}
+
+	return count == 0, nil
+}
@@ -0,0 +1,90 @@
+package mongoDbController
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const BLOG_TAGS_INDEX = "tags"
+
+// getBlogTagsIndexModel returns the index used to find posts by tag. Indexes on
+// array fields are multikey indexes, with an entry for every tag of a post.
+func getBlogTagsIndexModel() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "tags", Value: 1}, {Key: "dateAdded", Value: -1}},
+		Options: options.Index().SetName(BLOG_TAGS_INDEX),
+	}
+}
+
+func (mdbc *MongoDbController) GetBlogTags(includeUnpublished bool) ([]*dbController.TagCount, error) {
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	matchStage := bson.D{{Key: "$match", Value: mdbc.getVisibilityFilter(includeUnpublished)}}
+
+	// A post with the same tag twice is only counted once
+	projectStage := bson.D{{
+		Key: "$project",
+		Value: bson.M{
+			"tags": bson.M{"$setUnion": bson.A{bson.M{"$ifNull": bson.A{"$tags", bson.A{}}}, bson.A{}}},
+		},
+	}}
+
+	unwindStage := bson.D{{Key: "$unwind", Value: "$tags"}}
+
+	groupStage := bson.D{{
+		Key: "$group",
+		Value: bson.M{
+			"_id":   "$tags",
+			"count": bson.M{"$sum": 1},
+		},
+	}}
+
+	sortStage := bson.D{{
+		Key: "$sort",
+		Value: bson.D{
+			{Key: "count", Value: -1},
+			{Key: "_id", Value: 1},
+		},
+	}}
+
+	cursor, aggErr := collection.Aggregate(backCtx, mongo.Pipeline{
+		matchStage,
+		projectStage,
+		unwindStage,
+		groupStage,
+		sortStage,
+	})
+
+	if aggErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
+	}
+
+	var results []TagCountResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	tags := make([]*dbController.TagCount, 0)
+	for _, v := range results {
+		tags = append(tags, &dbController.TagCount{Tag: v.Tag, Count: v.Count})
+	}
+
+	return tags, nil
+}
+
+func (mdbc *MongoDbController) GetBlogPostsByTag(tag string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	filter := mdbc.addBlogPostFilter(
+		mdbc.getVisibilityFilter(includeUnpublished),
+		&dbController.BlogPostFilter{Tag: tag},
+	)
+
+	return mdbc.getBlogPostsWithFilter(filter, page, pagination)
+}
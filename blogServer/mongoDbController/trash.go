@@ -0,0 +1,179 @@
+package mongoDbController
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (mdbc *MongoDbController) GetTrashedBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	matchStage := bson.D{{Key: "$match", Value: bson.M{"deletedAt": bson.M{"$exists": true}}}}
+
+	projectStage, authorLookupStage, updateAuthorLookupStage := mdbc.GetAggregationStages()
+
+	sortStage := bson.D{{Key: "$sort", Value: bson.M{"deletedAt": -1}}}
+	skipStage := bson.D{{Key: "$skip", Value: int64((page - 1) * pagination)}}
+	limitStage := bson.D{{Key: "$limit", Value: int32(pagination)}}
+
+	cursor, aggErr := collection.Aggregate(backCtx, mongo.Pipeline{
+		matchStage,
+		*projectStage,
+		sortStage,
+		skipStage,
+		limitStage,
+		*authorLookupStage,
+		*updateAuthorLookupStage,
+	})
+
+	if aggErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + aggErr.Error())
+	}
+
+	var results []BlogDocResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
+	for _, v := range results {
+		posts = append(posts, v.GetBlogDocument())
+	}
+
+	return posts, nil
+}
+
+func (mdbc *MongoDbController) CountTrashedBlogPosts() (int, error) {
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	count, countErr := collection.CountDocuments(backCtx, bson.M{"deletedAt": bson.M{"$exists": true}})
+
+	if countErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + countErr.Error())
+	}
+
+	return int(count), nil
+}
+
+func (mdbc *MongoDbController) RestoreBlogPost(id string) error {
+	idObj, idObjErr := primitive.ObjectIDFromHex(id)
+
+	if idObjErr != nil {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	result, updateErr := collection.UpdateOne(
+		backCtx,
+		bson.M{"_id": idObj, "deletedAt": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"deletedAt": "", "deletedBy": ""}},
+	)
+
+	if updateErr != nil {
+		return dbController.NewDBError(updateErr.Error())
+	}
+
+	if result.MatchedCount == 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts in the trash")
+	}
+
+	return nil
+}
+
+// deleteRevisions deletes the revisions of every post in postIds
+func (mdbc *MongoDbController) deleteRevisions(postIds []string) error {
+	collection, backCtx, cancel := mdbc.getCollection(REVISION_COLLECTION)
+	defer cancel()
+
+	_, deleteErr := collection.DeleteMany(backCtx, bson.M{"postId": bson.M{"$in": postIds}})
+
+	return deleteErr
+}
+
+func (mdbc *MongoDbController) PurgeBlogPost(id string) error {
+	idObj, idObjErr := primitive.ObjectIDFromHex(id)
+
+	if idObjErr != nil {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	delResult, delErr := collection.DeleteOne(backCtx, bson.M{"_id": idObj, "deletedAt": bson.M{"$exists": true}})
+
+	if delErr != nil {
+		return dbController.NewDBError(delErr.Error())
+	}
+
+	if delResult.DeletedCount == 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts in the trash")
+	}
+
+	if revisionErr := mdbc.deleteRevisions([]string{id}); revisionErr != nil {
+		return dbController.NewDBError(revisionErr.Error())
+	}
+
+	return nil
+}
+
+// PurgeTrashedBlogPosts finds the posts to purge first, so that their
+// revisions can be deleted along with them.
+func (mdbc *MongoDbController) PurgeTrashedBlogPosts(deletedBefore time.Time) (int, error) {
+	collection, backCtx, cancel := mdbc.getCollection(BLOG_COLLECTION)
+	defer cancel()
+
+	filter := bson.M{"deletedAt": bson.M{"$lt": primitive.Timestamp{T: uint32(deletedBefore.Unix())}}}
+
+	cursor, findErr := collection.Find(backCtx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+
+	if findErr != nil {
+		return 0, dbController.NewDBError(findErr.Error())
+	}
+
+	var results []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return 0, dbController.NewDBError(allErr.Error())
+	}
+
+	if len(results) == 0 {
+		return 0, nil
+	}
+
+	objectIds := make([]primitive.ObjectID, 0)
+	postIds := make([]string, 0)
+
+	for _, result := range results {
+		objectIds = append(objectIds, result.Id)
+		postIds = append(postIds, result.Id.Hex())
+	}
+
+	delResult, delErr := collection.DeleteMany(backCtx, bson.M{"_id": bson.M{"$in": objectIds}})
+
+	if delErr != nil {
+		return 0, dbController.NewDBError(delErr.Error())
+	}
+
+	if revisionErr := mdbc.deleteRevisions(postIds); revisionErr != nil {
+		return 0, dbController.NewDBError(revisionErr.Error())
+	}
+
+	return int(delResult.DeletedCount), nil
+}
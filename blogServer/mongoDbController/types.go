@@ -4,14 +4,16 @@ import (
 	"time"
 
 	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/user"
 )
 
 type UserDocResult struct {
-	Id    string `bson:"_id"`
-	UID   string `bson:"uid"`
-	Name  string `bson:"name"`
-	Role  string `bson:"role"`
-	Email string `bson:"email"`
+	Id     string `bson:"_id"`
+	UID    string `bson:"uid"`
+	Name   string `bson:"name"`
+	Role   string `bson:"role"`
+	Email  string `bson:"email"`
+	Active bool   `bson:"active"`
 }
 
 func (udr *UserDocResult) GetUserDataDoc() *dbController.UserDataDocument {
@@ -26,11 +28,32 @@ func (udr *UserDocResult) GetUserDataDoc() *dbController.UserDataDocument {
 	return &doc
 }
 
+// GetUserInformation returns the user. Unknown roles are read as the reader
+// role.
+func (udr *UserDocResult) GetUserInformation() *user.UserInformation {
+	role, _ := user.ParseUserType(udr.Role)
+
+	info := user.UserInformation{
+		Uid:    udr.UID,
+		Name:   udr.Name,
+		Email:  udr.Email,
+		Active: udr.Active,
+		Role:   role,
+	}
+
+	return &info
+}
+
 type BlogDocResult struct {
 	Id             string          `bson:"_id"`
 	Title          string          `bson:"title"`
 	Slug           string          `bson:"slug"`
 	Body           string          `bson:"body"`
+	BodyHtml       string          `bson:"bodyHtml"`
+	Excerpt        string          `bson:"excerpt"`
+	CustomExcerpt  bool            `bson:"customExcerpt"`
+	WordCount      int             `bson:"wordCount"`
+	ReadingTime    int             `bson:"readingTime"`
 	Tags           []string        `bson:"tags"`
 	Author         []UserDocResult `bson:"author"`
 	AuthorId       string          `bson:"authorId"`
@@ -38,6 +61,10 @@ type BlogDocResult struct {
 	UpdateAuthor   []UserDocResult `bson:"updateAuthor"`
 	UpdateAuthorId string          `bson:"updateAuthorId"`
 	DateUpdated    time.Time       `bson:"dateUpdated"`
+	Status         string          `bson:"status"`
+	PublishAt      time.Time       `bson:"publishAt"`
+	DeletedAt      *time.Time      `bson:"deletedAt"`
+	DeletedBy      string          `bson:"deletedBy"`
 }
 
 func (bdr *BlogDocResult) GetBlogDocument() *dbController.BlogDocument {
@@ -48,14 +75,21 @@ func (bdr *BlogDocResult) GetBlogDocument() *dbController.BlogDocument {
 
 	var updateAuthor string = ""
 	if len(bdr.UpdateAuthor) > 0 {
-		updateAuthor = bdr.Author[0].Name
+		updateAuthor = bdr.UpdateAuthor[0].Name
 	}
 
 	doc := dbController.BlogDocument{
-		Id:             bdr.Id,
-		Title:          bdr.Title,
-		Slug:           bdr.Slug,
-		Body:           bdr.Body,
+		Id:       bdr.Id,
+		Title:    bdr.Title,
+		Slug:     bdr.Slug,
+		Body:     bdr.Body,
+		BodyHtml: bdr.BodyHtml,
+		Summary: dbController.BlogSummary{
+			Excerpt:            bdr.Excerpt,
+			CustomExcerpt:      bdr.CustomExcerpt,
+			WordCount:          bdr.WordCount,
+			ReadingTimeMinutes: bdr.ReadingTime,
+		},
 		Tags:           bdr.Tags,
 		Author:         author,
 		AuthorId:       bdr.AuthorId,
@@ -63,7 +97,82 @@ func (bdr *BlogDocResult) GetBlogDocument() *dbController.BlogDocument {
 		UpdateAuthor:   updateAuthor,
 		UpdateAuthorId: bdr.UpdateAuthorId,
 		DateUpdated:    bdr.DateUpdated,
+		Status:         bdr.Status,
+		PublishAt:      bdr.PublishAt,
+		DeletedAt:      bdr.DeletedAt,
+		DeletedBy:      bdr.DeletedBy,
 	}
 
 	return &doc
 }
+
+// SearchResultDoc is a blog post with the text score added by SearchBlogPosts
+type SearchResultDoc struct {
+	BlogDocResult `bson:",inline"`
+	Score         float64 `bson:"score"`
+}
+
+// TagCountResult is a tag grouped by GetBlogTags
+type TagCountResult struct {
+	Tag   string `bson:"_id"`
+	Count int    `bson:"count"`
+}
+
+// ArchiveCountResult is a month grouped by GetBlogArchive
+type SitemapEntryResult struct {
+	Slug        string    `bson:"slug"`
+	DateAdded   time.Time `bson:"dateAdded"`
+	DateUpdated time.Time `bson:"dateUpdated"`
+}
+
+func (ser *SitemapEntryResult) GetSitemapEntry() *dbController.SitemapEntry {
+	return &dbController.SitemapEntry{
+		Slug:        ser.Slug,
+		DateAdded:   ser.DateAdded,
+		DateUpdated: ser.DateUpdated,
+	}
+}
+
+type ArchiveCountResult struct {
+	Id struct {
+		Year  int `bson:"year"`
+		Month int `bson:"month"`
+	} `bson:"_id"`
+	Count int `bson:"count"`
+}
+
+type BlogRevisionResult struct {
+	PostId         string    `bson:"postId"`
+	Revision       int       `bson:"revision"`
+	Title          string    `bson:"title"`
+	Slug           string    `bson:"slug"`
+	Body           string    `bson:"body"`
+	Tags           []string  `bson:"tags"`
+	AuthorId       string    `bson:"authorId"`
+	DateAdded      time.Time `bson:"dateAdded"`
+	UpdateAuthorId string    `bson:"updateAuthorId"`
+	DateUpdated    time.Time `bson:"dateUpdated"`
+	Status         string    `bson:"status"`
+	PublishAt      time.Time `bson:"publishAt"`
+	ChangedFields  []string  `bson:"changedFields"`
+}
+
+func (brr *BlogRevisionResult) GetBlogRevision() *dbController.BlogRevision {
+	rev := dbController.BlogRevision{
+		PostId:         brr.PostId,
+		Revision:       brr.Revision,
+		Title:          brr.Title,
+		Slug:           brr.Slug,
+		Body:           brr.Body,
+		Tags:           brr.Tags,
+		AuthorId:       brr.AuthorId,
+		DateAdded:      brr.DateAdded,
+		UpdateAuthorId: brr.UpdateAuthorId,
+		DateUpdated:    brr.DateUpdated,
+		Status:         brr.Status,
+		PublishAt:      brr.PublishAt,
+		ChangedFields:  brr.ChangedFields,
+	}
+
+	return &rev
+}
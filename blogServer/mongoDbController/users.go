@@ -0,0 +1,150 @@
+package mongoDbController
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+func (mdbc *MongoDbController) GetUserInformation(uid string) (*user.UserInformation, error) {
+	collection, backCtx, cancel := mdbc.getCollection(USER_COLLECTION)
+	defer cancel()
+
+	var result UserDocResult
+	findErr := collection.FindOne(backCtx, bson.M{"uid": uid}).Decode(&result)
+
+	if findErr == mongo.ErrNoDocuments {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	return result.GetUserInformation(), nil
+}
+
+func (mdbc *MongoDbController) GetUsers(page int, pagination int) ([]*user.UserInformation, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	collection, backCtx, cancel := mdbc.getCollection(USER_COLLECTION)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.M{"uid": 1}).
+		SetSkip(int64((page - 1) * pagination)).
+		SetLimit(int64(pagination))
+
+	cursor, findErr := collection.Find(backCtx, bson.M{}, opts)
+
+	if findErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + findErr.Error())
+	}
+
+	var results []UserDocResult
+	if allErr := cursor.All(backCtx, &results); allErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + allErr.Error())
+	}
+
+	users := make([]*user.UserInformation, 0)
+	for _, v := range results {
+		users = append(users, v.GetUserInformation())
+	}
+
+	return users, nil
+}
+
+func (mdbc *MongoDbController) CountUsers() (int, error) {
+	collection, backCtx, cancel := mdbc.getCollection(USER_COLLECTION)
+	defer cancel()
+
+	count, countErr := collection.CountDocuments(backCtx, bson.M{})
+
+	if countErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + countErr.Error())
+	}
+
+	return int(count), nil
+}
+
+func (mdbc *MongoDbController) EditUserInformation(doc *dbController.EditUserDocument) error {
+	collection, backCtx, cancel := mdbc.getCollection(USER_COLLECTION)
+	defer cancel()
+
+	setDoc := bson.M{}
+
+	if doc.Name != nil {
+		setDoc["name"] = *doc.Name
+	}
+
+	if doc.Email != nil {
+		setDoc["email"] = *doc.Email
+	}
+
+	if doc.Active != nil {
+		setDoc["active"] = *doc.Active
+	}
+
+	if doc.Role != nil {
+		setDoc["role"] = doc.Role.String()
+	}
+
+	filter := bson.M{"uid": doc.Uid}
+
+	// Nothing to change, but the user still has to exist
+	if len(setDoc) == 0 {
+		count, countErr := collection.CountDocuments(backCtx, filter)
+
+		if countErr != nil {
+			return dbController.NewDBError(countErr.Error())
+		}
+
+		if count == 0 {
+			return dbController.NewInvalidInputError("uid did not match any users")
+		}
+
+		return nil
+	}
+
+	result, updateErr := collection.UpdateOne(backCtx, filter, bson.M{"$set": setDoc})
+
+	if updateErr != nil {
+		err := updateErr.Error()
+
+		if strings.Contains(err, "duplicate key error") {
+			return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + *doc.Email + "' already exists.")
+		}
+
+		return dbController.NewDBError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	return nil
+}
+
+func (mdbc *MongoDbController) DeleteUserInformation(uid string) error {
+	collection, backCtx, cancel := mdbc.getCollection(USER_COLLECTION)
+	defer cancel()
+
+	result, deleteErr := collection.DeleteOne(backCtx, bson.M{"uid": uid})
+
+	if deleteErr != nil {
+		return dbController.NewDBError(deleteErr.Error())
+	}
+
+	if result.DeletedCount == 0 {
+		return dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	return nil
+}
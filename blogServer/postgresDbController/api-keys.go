@@ -0,0 +1,169 @@
+package postgresDbController
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const apiKeySelect = `SELECT id, name, owner_id, prefix, hash, scopes, date_added, expires_at, last_used, revoked_at FROM ` + API_KEY_TABLE
+
+// nullTime returns the time of a nullable timestamp column, truncated to the
+// second like the other backends.
+func nullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	t := time.Unix(value.Time.Unix(), 0)
+	return &t
+}
+
+func scanApiKey(row rowScanner) (*dbController.ApiKeyDocument, error) {
+	var key dbController.ApiKeyDocument
+	var dateAdded time.Time
+	var expiresAt, lastUsed, revokedAt sql.NullTime
+
+	scanErr := row.Scan(
+		&key.Id,
+		&key.Name,
+		&key.OwnerId,
+		&key.Prefix,
+		&key.Hash,
+		pq.Array(&key.Scopes),
+		&dateAdded,
+		&expiresAt,
+		&lastUsed,
+		&revokedAt,
+	)
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	key.DateAdded = time.Unix(dateAdded.Unix(), 0)
+	key.ExpiresAt = nullTime(expiresAt)
+	key.LastUsed = nullTime(lastUsed)
+	key.RevokedAt = nullTime(revokedAt)
+
+	return &key, nil
+}
+
+func (pdbc *PostgresDbController) AddApiKey(doc *dbController.AddApiKeyDocument) (string, error) {
+	scopes := doc.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	id := primitive.NewObjectID().Hex()
+
+	_, insertErr := pdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+API_KEY_TABLE+` (id, name, owner_id, prefix, hash, scopes, date_added, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		id, doc.Name, doc.OwnerId, doc.Prefix, doc.Hash, pq.Array(scopes), doc.DateAdded, doc.ExpiresAt,
+	)
+
+	if insertErr != nil {
+		if isDuplicateError(insertErr) {
+			return "", dbController.NewDuplicateEntryError("Duplicate API key")
+		}
+
+		return "", dbController.NewDBError(insertErr.Error())
+	}
+
+	return id, nil
+}
+
+func (pdbc *PostgresDbController) GetApiKeyByHash(hash string) (*dbController.ApiKeyDocument, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	key, queryErr := scanApiKey(pdbc.DB.QueryRowContext(backCtx, apiKeySelect+` WHERE hash = $1`, hash))
+
+	if queryErr == sql.ErrNoRows {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return key, nil
+}
+
+func (pdbc *PostgresDbController) GetApiKeys() ([]*dbController.ApiKeyDocument, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := pdbc.DB.QueryContext(backCtx, apiKeySelect+` ORDER BY date_added DESC, id DESC`)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	keys := make([]*dbController.ApiKeyDocument, 0)
+
+	for rows.Next() {
+		key, scanErr := scanApiKey(rows)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		keys = append(keys, key)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return keys, nil
+}
+
+// updateApiKey runs an update of the API key with the id, which is the last
+// argument.
+func (pdbc *PostgresDbController) updateApiKey(statement string, args ...interface{}) error {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	result, updateErr := pdbc.DB.ExecContext(backCtx, statement, args...)
+
+	if updateErr != nil {
+		return dbController.NewDBError(updateErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count == 0 {
+		return dbController.NewInvalidInputError("id did not match any API keys")
+	}
+
+	return nil
+}
+
+func (pdbc *PostgresDbController) RevokeApiKey(id string, revokedAt time.Time) error {
+	return pdbc.updateApiKey(
+		`UPDATE `+API_KEY_TABLE+` SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2`,
+		revokedAt, id,
+	)
+}
+
+func (pdbc *PostgresDbController) SetApiKeyLastUsed(id string, lastUsed time.Time) error {
+	return pdbc.updateApiKey(
+		`UPDATE `+API_KEY_TABLE+` SET last_used = $1 WHERE id = $2`,
+		lastUsed, id,
+	)
+}
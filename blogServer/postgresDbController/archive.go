@@ -0,0 +1,57 @@
+package postgresDbController
+
+import (
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (pdbc *PostgresDbController) GetBlogArchive(includeUnpublished bool) ([]*dbController.ArchiveCount, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := pdbc.DB.QueryContext(
+		backCtx,
+		`SELECT
+			EXTRACT(YEAR FROM p.date_added AT TIME ZONE 'UTC')::INTEGER AS year,
+			EXTRACT(MONTH FROM p.date_added AT TIME ZONE 'UTC')::INTEGER AS month,
+			COUNT(*)
+		FROM `+BLOG_TABLE+` p
+		WHERE `+visibilityCondition(includeUnpublished)+`
+		GROUP BY year, month
+		ORDER BY year DESC, month DESC`,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	archive := make([]*dbController.ArchiveCount, 0)
+
+	for rows.Next() {
+		var count dbController.ArchiveCount
+
+		if scanErr := rows.Scan(&count.Year, &count.Month, &count.Count); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		archive = append(archive, &count)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return archive, nil
+}
+
+func (pdbc *PostgresDbController) GetBlogPostsByDateRange(start time.Time, end time.Time, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	where, args := filterCondition(&dbController.BlogPostFilter{DateAddedStart: &start, DateAddedEnd: &end}, 1)
+
+	return pdbc.getBlogPostsWhere(page, pagination, includeUnpublished, where, args...)
+}
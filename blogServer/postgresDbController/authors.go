@@ -0,0 +1,85 @@
+package postgresDbController
+
+import (
+	"database/sql"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (pdbc *PostgresDbController) GetBlogAuthor(uid string) (*dbController.BlogAuthor, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	var author dbController.BlogAuthor
+
+	queryErr := pdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT uid, name FROM `+USER_TABLE+` WHERE uid = $1`,
+		uid,
+	).Scan(&author.UID, &author.Name)
+
+	if queryErr == sql.ErrNoRows {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return &author, nil
+}
+
+func (pdbc *PostgresDbController) GetBlogPostsByAuthor(uid string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	where, args := filterCondition(&dbController.BlogPostFilter{AuthorId: uid}, 1)
+
+	return pdbc.getBlogPostsWhere(page, pagination, includeUnpublished, where, args...)
+}
+
+func (pdbc *PostgresDbController) GetBlogAuthors(includeUnpublished bool) ([]*dbController.BlogAuthor, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	visibility := visibilityCondition(includeUnpublished)
+	args := []interface{}{}
+
+	// Uids are sorted by their bytes, the same way the other backends sort
+	// them.
+	rows, queryErr := pdbc.DB.QueryContext(
+		backCtx,
+		`SELECT u.uid, u.name
+		FROM `+USER_TABLE+` u
+		WHERE EXISTS (
+			SELECT 1 FROM `+BLOG_TABLE+` p
+			WHERE p.author_id = u.uid AND `+visibility+`
+		)
+		ORDER BY u.uid COLLATE "C" ASC`,
+		args...,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	authors := make([]*dbController.BlogAuthor, 0)
+
+	for rows.Next() {
+		var author dbController.BlogAuthor
+
+		if scanErr := rows.Scan(&author.UID, &author.Name); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		authors = append(authors, &author)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return authors, nil
+}
@@ -0,0 +1,70 @@
+package postgresDbController
+
+import (
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (pdbc *PostgresDbController) CountBlogPosts(filter *dbController.BlogPostFilter, includeUnpublished bool) (int, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	where, args := filterCondition(filter, 1)
+
+	var count int
+
+	queryErr := pdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM `+BLOG_TABLE+` p WHERE `+visibilityCondition(includeUnpublished)+` AND `+where,
+		args...,
+	).Scan(&count)
+
+	if queryErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return count, nil
+}
+
+func (pdbc *PostgresDbController) GetBlogPostsByCursor(cursor *dbController.BlogCursor, limit int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if limit < 1 {
+		return nil, dbController.NewDBError("invalid limit")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	where := visibilityCondition(includeUnpublished)
+	order := `p.date_added DESC, p.id ASC`
+	args := []interface{}{limit}
+
+	if cursor != nil {
+		if !isValidId(cursor.Id) {
+			return nil, dbController.NewInvalidInputError("invalid cursor")
+		}
+
+		// The posts before the cursor are read in reverse order, so that the
+		// limit keeps the posts closest to the cursor.
+		if cursor.Previous {
+			where += ` AND (p.date_added > $2 OR (p.date_added = $2 AND p.id < $3))`
+			order = `p.date_added ASC, p.id DESC`
+		} else {
+			where += ` AND (p.date_added < $2 OR (p.date_added = $2 AND p.id > $3))`
+		}
+
+		args = append(args, toTimestamp(cursor.DateAdded), cursor.Id)
+	}
+
+	query := blogPostSelect + ` WHERE ` + where + ` ORDER BY ` + order + ` LIMIT $1`
+
+	posts, postsErr := queryBlogPosts(backCtx, pdbc.DB, query, args...)
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	if cursor != nil && cursor.Previous {
+		dbController.ReverseBlogDocuments(posts)
+	}
+
+	return posts, nil
+}
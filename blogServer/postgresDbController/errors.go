@@ -0,0 +1,7 @@
+package postgresDbController
+
+// Used for when there's an issue with reading environment variables
+type EnvironmentVariableError struct{ ErrMsg string }
+
+func (err EnvironmentVariableError) Error() string { return err.ErrMsg }
+func NewEnvironmentVariableError(msg string) error { return EnvironmentVariableError{msg} }
@@ -0,0 +1,470 @@
+package postgresDbController
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const MIGRATIONS_TABLE = "schema_migrations"
+
+// MIGRATION_LOCK_ID is the key of the advisory lock that keeps two processes
+// from running migrations at the same time.
+const MIGRATION_LOCK_ID = 7262834
+
+// Migrations can take much longer than a regular query
+const MIGRATION_TIMEOUT = 5 * time.Minute
+
+// A migration moves the schema from Version - 1 to Version with the Up
+// statements, and back again with the Down statements. Each migration runs in
+// its own transaction.
+type migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// migrations must be kept in order. Once a migration has been released, it
+// should never be changed. Add a new migration instead.
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "create_blog_posts",
+		Up: []string{
+			`CREATE TABLE ` + BLOG_TABLE + ` (
+				seq              BIGSERIAL   NOT NULL,
+				id               TEXT        NOT NULL PRIMARY KEY,
+				title            TEXT        NOT NULL,
+				slug             TEXT        NOT NULL,
+				body             TEXT,
+				tags             TEXT[],
+				author_id        TEXT        NOT NULL,
+				date_added       TIMESTAMPTZ NOT NULL,
+				update_author_id TEXT        NOT NULL,
+				date_updated     TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX blog_posts_slug ON ` + BLOG_TABLE + ` (slug)`,
+			`CREATE INDEX blog_posts_date_added ON ` + BLOG_TABLE + ` (date_added DESC, seq)`,
+		},
+		Down: []string{
+			`DROP TABLE ` + BLOG_TABLE,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_users",
+		Up: []string{
+			`CREATE TABLE ` + USER_TABLE + ` (
+				uid    TEXT    NOT NULL,
+				name   TEXT    NOT NULL,
+				email  TEXT    NOT NULL,
+				active BOOLEAN NOT NULL,
+				role   TEXT    NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX users_uid ON ` + USER_TABLE + ` (uid)`,
+			`CREATE UNIQUE INDEX users_email ON ` + USER_TABLE + ` (email)`,
+		},
+		Down: []string{
+			`DROP TABLE ` + USER_TABLE,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_logging",
+		Up: []string{
+			`CREATE TABLE ` + LOGGING_TABLE + ` (
+				id            BIGSERIAL   NOT NULL PRIMARY KEY,
+				timestamp     TIMESTAMPTZ NOT NULL,
+				type          TEXT        NOT NULL,
+				client_ip     TEXT,
+				method        TEXT,
+				path          TEXT,
+				protocol      TEXT,
+				status_code   INTEGER,
+				latency       BIGINT,
+				user_agent    TEXT,
+				error_message TEXT,
+				message       TEXT
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE ` + LOGGING_TABLE,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_blog_status",
+		Up: []string{
+			`ALTER TABLE ` + BLOG_TABLE + `
+				ADD COLUMN status TEXT NOT NULL DEFAULT 'published'
+					CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+				ADD COLUMN publish_at TIMESTAMPTZ`,
+			`UPDATE ` + BLOG_TABLE + ` SET publish_at = date_added`,
+			`ALTER TABLE ` + BLOG_TABLE + `
+				ALTER COLUMN status DROP DEFAULT,
+				ALTER COLUMN publish_at SET NOT NULL`,
+			`CREATE INDEX blog_posts_status_publish_at ON ` + BLOG_TABLE + ` (status, publish_at)`,
+		},
+		Down: []string{
+			`DROP INDEX blog_posts_status_publish_at`,
+			`ALTER TABLE ` + BLOG_TABLE + ` DROP COLUMN status, DROP COLUMN publish_at`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create_blog_revisions",
+		Up: []string{
+			`CREATE TABLE ` + BLOG_REVISIONS_TABLE + ` (
+				post_id          TEXT        NOT NULL REFERENCES ` + BLOG_TABLE + ` (id) ON DELETE CASCADE,
+				revision         INTEGER     NOT NULL,
+				title            TEXT        NOT NULL,
+				slug             TEXT        NOT NULL,
+				body             TEXT        NOT NULL,
+				tags             TEXT[]      NOT NULL,
+				author_id        TEXT        NOT NULL,
+				date_added       TIMESTAMPTZ NOT NULL,
+				update_author_id TEXT        NOT NULL,
+				date_updated     TIMESTAMPTZ NOT NULL,
+				status           TEXT        NOT NULL,
+				publish_at       TIMESTAMPTZ NOT NULL,
+				changed_fields   TEXT[]      NOT NULL,
+				PRIMARY KEY (post_id, revision)
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE ` + BLOG_REVISIONS_TABLE,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add_blog_trash",
+		Up: []string{
+			`ALTER TABLE ` + BLOG_TABLE + `
+				ADD COLUMN deleted_at TIMESTAMPTZ,
+				ADD COLUMN deleted_by TEXT`,
+			`CREATE INDEX blog_posts_deleted_at ON ` + BLOG_TABLE + ` (deleted_at) WHERE deleted_at IS NOT NULL`,
+		},
+		Down: []string{
+			`DROP INDEX blog_posts_deleted_at`,
+			`ALTER TABLE ` + BLOG_TABLE + ` DROP COLUMN deleted_at, DROP COLUMN deleted_by`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "add_blog_search",
+		Up: []string{
+			`ALTER TABLE ` + BLOG_TABLE + ` ADD COLUMN search_vector TSVECTOR`,
+			`CREATE FUNCTION blog_posts_search_vector() RETURNS trigger AS $$
+			BEGIN
+				NEW.search_vector :=
+					setweight(to_tsvector('english', NEW.title), 'A') ||
+					setweight(to_tsvector('english', array_to_string(COALESCE(NEW.tags, '{}'), ' ')), 'B') ||
+					setweight(to_tsvector('english', COALESCE(NEW.body, '')), 'C');
+				RETURN NEW;
+			END
+			$$ LANGUAGE plpgsql`,
+			`CREATE TRIGGER blog_posts_search_vector BEFORE INSERT OR UPDATE ON ` + BLOG_TABLE + `
+				FOR EACH ROW EXECUTE FUNCTION blog_posts_search_vector()`,
+			// Sets the search vector of the existing posts through the trigger
+			`UPDATE ` + BLOG_TABLE + ` SET title = title`,
+			`CREATE INDEX blog_posts_search_vector ON ` + BLOG_TABLE + ` USING GIN (search_vector)`,
+		},
+		Down: []string{
+			`DROP INDEX blog_posts_search_vector`,
+			`DROP TRIGGER blog_posts_search_vector ON ` + BLOG_TABLE,
+			`DROP FUNCTION blog_posts_search_vector()`,
+			`ALTER TABLE ` + BLOG_TABLE + ` DROP COLUMN search_vector`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_blog_tags_index",
+		Up: []string{
+			`CREATE INDEX blog_posts_tags ON ` + BLOG_TABLE + ` USING GIN (tags)`,
+		},
+		Down: []string{
+			`DROP INDEX blog_posts_tags`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add_blog_author_index",
+		Up: []string{
+			`CREATE INDEX blog_posts_author_id ON ` + BLOG_TABLE + ` (author_id, date_added DESC, seq)`,
+		},
+		Down: []string{
+			`DROP INDEX blog_posts_author_id`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_blog_cursor_index",
+		Up: []string{
+			`CREATE INDEX blog_posts_date_added_id ON ` + BLOG_TABLE + ` (date_added DESC, id)`,
+		},
+		Down: []string{
+			`DROP INDEX blog_posts_date_added_id`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add_blog_body_html",
+		Up: []string{
+			`ALTER TABLE ` + BLOG_TABLE + ` ADD COLUMN body_html TEXT NOT NULL DEFAULT ''`,
+		},
+		Down: []string{
+			`ALTER TABLE ` + BLOG_TABLE + ` DROP COLUMN body_html`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_blog_summary",
+		Up: []string{
+			`ALTER TABLE ` + BLOG_TABLE + `
+				ADD COLUMN excerpt        TEXT    NOT NULL DEFAULT '',
+				ADD COLUMN custom_excerpt BOOLEAN NOT NULL DEFAULT FALSE,
+				ADD COLUMN word_count     INTEGER NOT NULL DEFAULT 0,
+				ADD COLUMN reading_time   INTEGER NOT NULL DEFAULT 0`,
+		},
+		Down: []string{
+			`ALTER TABLE ` + BLOG_TABLE + ` DROP COLUMN excerpt, DROP COLUMN custom_excerpt, DROP COLUMN word_count, DROP COLUMN reading_time`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "create_blog_post_slugs",
+		Up: []string{
+			`CREATE TABLE ` + BLOG_SLUGS_TABLE + ` (
+				slug         TEXT        NOT NULL PRIMARY KEY,
+				post_id      TEXT        NOT NULL REFERENCES ` + BLOG_TABLE + ` (id) ON DELETE CASCADE,
+				date_changed TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX blog_post_slugs_post_id ON ` + BLOG_SLUGS_TABLE + ` (post_id)`,
+		},
+		Down: []string{
+			`DROP TABLE ` + BLOG_SLUGS_TABLE,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "create_api_keys",
+		Up: []string{
+			`CREATE TABLE ` + API_KEY_TABLE + ` (
+				id         TEXT        NOT NULL PRIMARY KEY,
+				name       TEXT        NOT NULL,
+				owner_id   TEXT        NOT NULL,
+				prefix     TEXT        NOT NULL,
+				hash       TEXT        NOT NULL,
+				scopes     TEXT[]      NOT NULL,
+				date_added TIMESTAMPTZ NOT NULL,
+				expires_at TIMESTAMPTZ,
+				last_used  TIMESTAMPTZ,
+				revoked_at TIMESTAMPTZ
+			)`,
+			`CREATE UNIQUE INDEX api_keys_hash ON ` + API_KEY_TABLE + ` (hash)`,
+		},
+		Down: []string{
+			`DROP TABLE ` + API_KEY_TABLE,
+		},
+	},
+}
+
+func latestMigrationVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+func createMigrationsTable(backCtx context.Context, conn *sql.Conn) error {
+	_, createErr := conn.ExecContext(
+		backCtx,
+		`CREATE TABLE IF NOT EXISTS `+MIGRATIONS_TABLE+` (
+			version    INTEGER     NOT NULL PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+	)
+
+	return createErr
+}
+
+// getMigrationVersion returns 0 when the migrations table doesn't exist yet,
+// so that reading the version never changes the database.
+func getMigrationVersion(backCtx context.Context, conn *sql.Conn) (int, error) {
+	var tableExists bool
+
+	existsErr := conn.QueryRowContext(
+		backCtx,
+		`SELECT to_regclass($1) IS NOT NULL`,
+		MIGRATIONS_TABLE,
+	).Scan(&tableExists)
+
+	if existsErr != nil || !tableExists {
+		return 0, existsErr
+	}
+
+	var version int
+
+	queryErr := conn.QueryRowContext(
+		backCtx,
+		`SELECT COALESCE(MAX(version), 0) FROM `+MIGRATIONS_TABLE,
+	).Scan(&version)
+
+	return version, queryErr
+}
+
+// runMigration runs the Up or Down statements of a migration and records the
+// result in the migrations table within a single transaction. Nothing is
+// changed when dryRun is true.
+func runMigration(backCtx context.Context, conn *sql.Conn, m migration, up bool, dryRun bool) error {
+	statements := m.Down
+	if up {
+		statements = m.Up
+	}
+
+	if dryRun {
+		for _, statement := range statements {
+			print("  Would run: " + statement + "\n")
+		}
+
+		return nil
+	}
+
+	tx, txErr := conn.BeginTx(backCtx, nil)
+
+	if txErr != nil {
+		return txErr
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, execErr := tx.ExecContext(backCtx, statement); execErr != nil {
+			return execErr
+		}
+	}
+
+	var recordErr error
+	if up {
+		_, recordErr = tx.ExecContext(
+			backCtx,
+			`INSERT INTO `+MIGRATIONS_TABLE+` (version, name) VALUES ($1, $2)`,
+			m.Version, m.Name,
+		)
+	} else {
+		_, recordErr = tx.ExecContext(
+			backCtx,
+			`DELETE FROM `+MIGRATIONS_TABLE+` WHERE version = $1`,
+			m.Version,
+		)
+	}
+
+	if recordErr != nil {
+		return recordErr
+	}
+
+	return tx.Commit()
+}
+
+// GetMigrationVersion returns the version of the latest migration applied to
+// the database, or 0 if no migrations have been applied.
+func (pdbc *PostgresDbController) GetMigrationVersion() (int, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	conn, connErr := pdbc.DB.Conn(backCtx)
+
+	if connErr != nil {
+		return 0, dbController.NewDBError(connErr.Error())
+	}
+	defer conn.Close()
+
+	version, versionErr := getMigrationVersion(backCtx, conn)
+
+	if versionErr != nil {
+		return 0, dbController.NewDBError(versionErr.Error())
+	}
+
+	return version, nil
+}
+
+// MigrateTo applies the Up migrations above the current version up to and
+// including targetVersion, or applies the Down migrations above targetVersion
+// in reverse order. Passing dbController.LATEST_MIGRATION applies every
+// migration. When dryRun is true, the statements are printed, but not run, and
+// the database isn't changed at all.
+func (pdbc *PostgresDbController) MigrateTo(targetVersion int, dryRun bool) error {
+	if targetVersion == dbController.LATEST_MIGRATION {
+		targetVersion = latestMigrationVersion()
+	}
+
+	if targetVersion < 0 || targetVersion > latestMigrationVersion() {
+		return dbController.NewInvalidInputError(fmt.Sprintf("invalid migration version %d", targetVersion))
+	}
+
+	backCtx, cancel := context.WithTimeout(context.Background(), MIGRATION_TIMEOUT)
+	defer cancel()
+
+	// The advisory lock belongs to a session, so every statement has to run
+	// on the same connection.
+	conn, connErr := pdbc.DB.Conn(backCtx)
+
+	if connErr != nil {
+		return dbController.NewDBError(connErr.Error())
+	}
+	defer conn.Close()
+
+	if _, lockErr := conn.ExecContext(backCtx, `SELECT pg_advisory_lock($1)`, MIGRATION_LOCK_ID); lockErr != nil {
+		return dbController.NewDBError("error acquiring migration lock: " + lockErr.Error())
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, MIGRATION_LOCK_ID)
+
+	// A dry run must not change anything, including creating the migrations
+	// table. runMigration creates nothing either when dryRun is true.
+	if !dryRun {
+		if createErr := createMigrationsTable(backCtx, conn); createErr != nil {
+			return dbController.NewDBError(createErr.Error())
+		}
+	}
+
+	currentVersion, versionErr := getMigrationVersion(backCtx, conn)
+
+	if versionErr != nil {
+		return dbController.NewDBError(versionErr.Error())
+	}
+
+	if currentVersion < targetVersion {
+		for _, m := range migrations {
+			if m.Version <= currentVersion || m.Version > targetVersion {
+				continue
+			}
+
+			print(fmt.Sprintf("Applying migration %d %s\n", m.Version, m.Name))
+
+			if migrateErr := runMigration(backCtx, conn, m, true, dryRun); migrateErr != nil {
+				msg := fmt.Sprintf("error applying migration %d %s: %s", m.Version, m.Name, migrateErr.Error())
+				return dbController.NewDBError(msg)
+			}
+		}
+	}
+
+	if currentVersion > targetVersion {
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+
+			if m.Version > currentVersion || m.Version <= targetVersion {
+				continue
+			}
+
+			print(fmt.Sprintf("Reverting migration %d %s\n", m.Version, m.Name))
+
+			if migrateErr := runMigration(backCtx, conn, m, false, dryRun); migrateErr != nil {
+				msg := fmt.Sprintf("error reverting migration %d %s: %s", m.Version, m.Name, migrateErr.Error())
+				return dbController.NewDBError(msg)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,604 @@
+package postgresDbController
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+const BLOG_TABLE = "blog_posts"
+const BLOG_REVISIONS_TABLE = "blog_revisions"
+const BLOG_SLUGS_TABLE = "blog_post_slugs"
+const LOGGING_TABLE = "logging"
+const USER_TABLE = "users"
+const API_KEY_TABLE = "api_keys"
+
+// MAX_LOG_ROWS keeps the logging table from growing forever, in the same way
+// the MongoDbController uses a capped collection.
+const MAX_LOG_ROWS = 10000
+
+// blogPostSelect joins the users table twice to get the names of the author
+// and the update author, the same way GetAggregationStages does with $lookup.
+const blogPostSelect = `SELECT
+		p.id,
+		p.title,
+		p.slug,
+		COALESCE(p.body, ''),
+		p.body_html,
+		p.excerpt,
+		p.custom_excerpt,
+		p.word_count,
+		p.reading_time,
+		p.tags,
+		p.author_id,
+		p.date_added,
+		p.update_author_id,
+		p.date_updated,
+		p.status,
+		p.publish_at,
+		p.deleted_at,
+		COALESCE(p.deleted_by, ''),
+		COALESCE(a.name, ''),
+		COALESCE(u.name, '')
+	FROM ` + BLOG_TABLE + ` p
+	LEFT JOIN ` + USER_TABLE + ` a ON a.uid = p.author_id
+	LEFT JOIN ` + USER_TABLE + ` u ON u.uid = p.update_author_id`
+
+type PostgresDbController struct {
+	DB *sql.DB
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getContext is a convenience function that returns a context with the same
+// timeout the MongoDbController uses for its operations.
+func (pdbc *PostgresDbController) getContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 5*time.Second)
+}
+
+// toTimestamp drops everything below a second from the time value, the same
+// way the MongoDbController does when it stores a primitive.Timestamp.
+func toTimestamp(t time.Time) time.Time {
+	return time.Unix(t.Unix(), 0).UTC()
+}
+
+// isDuplicateError returns whether the error is a unique_violation
+func isDuplicateError(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+func isValidId(id string) bool {
+	_, idErr := primitive.ObjectIDFromHex(id)
+	return idErr == nil
+}
+
+// visibilityCondition returns a WHERE condition that limits blog posts to the
+// ones the public can see. Posts in the trash are always left out. The
+// condition has no arguments.
+func visibilityCondition(includeUnpublished bool) string {
+	if includeUnpublished {
+		return `p.deleted_at IS NULL`
+	}
+
+	return `p.deleted_at IS NULL AND p.status IN ('` + dbController.BLOG_STATUS_PUBLISHED + `', '` + dbController.BLOG_STATUS_SCHEDULED + `') AND p.publish_at <= now()`
+}
+
+// filterCondition returns a WHERE condition and its arguments that limit blog
+// posts to the ones that match the filter. The arguments are numbered from
+// firstArg.
+func filterCondition(filter *dbController.BlogPostFilter, firstArg int) (string, []interface{}) {
+	conditions := []string{`TRUE`}
+	args := []interface{}{}
+
+	if filter == nil {
+		return conditions[0], args
+	}
+
+	// @> can use the GIN index on tags
+	if len(filter.Tag) > 0 {
+		args = append(args, pq.Array([]string{filter.Tag}))
+		conditions = append(conditions, fmt.Sprintf(`p.tags @> $%d`, firstArg+len(args)-1))
+	}
+
+	if len(filter.AuthorId) > 0 {
+		args = append(args, filter.AuthorId)
+		conditions = append(conditions, fmt.Sprintf(`p.author_id = $%d`, firstArg+len(args)-1))
+	}
+
+	if filter.DateAddedStart != nil {
+		args = append(args, toTimestamp(*filter.DateAddedStart))
+		conditions = append(conditions, fmt.Sprintf(`p.date_added >= $%d`, firstArg+len(args)-1))
+	}
+
+	if filter.DateAddedEnd != nil {
+		args = append(args, toTimestamp(*filter.DateAddedEnd))
+		conditions = append(conditions, fmt.Sprintf(`p.date_added < $%d`, firstArg+len(args)-1))
+	}
+
+	return strings.Join(conditions, ` AND `), args
+}
+
+// InitDatabase brings the schema up to the latest migration
+func (pdbc *PostgresDbController) InitDatabase() error {
+	return pdbc.MigrateTo(dbController.LATEST_MIGRATION, false)
+}
+
+// queryBlogPosts runs a query built on blogPostSelect and returns the posts
+func queryBlogPosts(backCtx context.Context, q queryer, query string, args ...interface{}) ([]*dbController.BlogDocument, error) {
+	rows, queryErr := q.QueryContext(backCtx, query, args...)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
+
+	for rows.Next() {
+		var post dbController.BlogDocument
+		var dateAdded, dateUpdated, publishAt time.Time
+		var deletedAt sql.NullTime
+
+		scanErr := rows.Scan(
+			&post.Id,
+			&post.Title,
+			&post.Slug,
+			&post.Body,
+			&post.BodyHtml,
+			&post.Summary.Excerpt,
+			&post.Summary.CustomExcerpt,
+			&post.Summary.WordCount,
+			&post.Summary.ReadingTimeMinutes,
+			pq.Array(&post.Tags),
+			&post.AuthorId,
+			&dateAdded,
+			&post.UpdateAuthorId,
+			&dateUpdated,
+			&post.Status,
+			&publishAt,
+			&deletedAt,
+			&post.DeletedBy,
+			&post.Author,
+			&post.UpdateAuthor,
+		)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		post.DateAdded = time.Unix(dateAdded.Unix(), 0)
+		post.DateUpdated = time.Unix(dateUpdated.Unix(), 0)
+		post.PublishAt = time.Unix(publishAt.Unix(), 0)
+
+		if deletedAt.Valid {
+			t := time.Unix(deletedAt.Time.Unix(), 0)
+			post.DeletedAt = &t
+		}
+
+		posts = append(posts, &post)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return posts, nil
+}
+
+func (pdbc *PostgresDbController) getBlogPostWhere(includeUnpublished bool, where string, args ...interface{}) (*dbController.BlogDocument, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	query := blogPostSelect + ` WHERE ` + where + ` AND ` + visibilityCondition(includeUnpublished) + ` LIMIT 1`
+
+	posts, postsErr := queryBlogPosts(backCtx, pdbc.DB, query, args...)
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	if len(posts) < 1 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return posts[0], nil
+}
+
+func (pdbc *PostgresDbController) AddBlogPost(doc *dbController.AddBlogDocument) (string, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	id := primitive.NewObjectID().Hex()
+	dateAdded := toTimestamp(doc.DateAdded)
+
+	updateAuthorId := doc.AuthorId
+	if doc.UpdateAuthorId != nil {
+		updateAuthorId = *doc.UpdateAuthorId
+	}
+
+	dateUpdated := dateAdded
+	if doc.DateUpdated != nil {
+		dateUpdated = toTimestamp(*doc.DateUpdated)
+	}
+
+	status := dbController.BLOG_STATUS_PUBLISHED
+	if doc.Status != nil {
+		status = *doc.Status
+	}
+
+	publishAt := dateAdded
+	if doc.PublishAt != nil {
+		publishAt = toTimestamp(*doc.PublishAt)
+	}
+
+	var tags interface{}
+	if doc.Tags != nil {
+		tags = pq.Array(*doc.Tags)
+	}
+
+	tx, txErr := pdbc.DB.BeginTx(backCtx, nil)
+
+	if txErr != nil {
+		return "", dbController.NewDBError(txErr.Error())
+	}
+	defer tx.Rollback()
+
+	if slugErr := checkOldSlug(backCtx, tx, doc.Slug, ""); slugErr != nil {
+		return "", slugErr
+	}
+
+	_, insertErr := tx.ExecContext(
+		backCtx,
+		`INSERT INTO `+BLOG_TABLE+` (id, title, slug, body, body_html, excerpt, custom_excerpt, word_count, reading_time, tags, author_id, date_added, update_author_id, date_updated, status, publish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		id, doc.Title, doc.Slug, doc.Body, doc.BodyHtml,
+		doc.Summary.Excerpt, doc.Summary.CustomExcerpt, doc.Summary.WordCount, doc.Summary.ReadingTimeMinutes,
+		tags, doc.AuthorId, dateAdded, updateAuthorId, dateUpdated, status, publishAt,
+	)
+
+	if insertErr != nil {
+		err := insertErr.Error()
+		print("Add blog error. Error: " + err + "\n")
+
+		if isDuplicateError(insertErr) {
+			msg := "Duplicate blog post."
+			if strings.Contains(err, "slug") {
+				msg = msg + " Blog Post with slug '" + doc.Slug + "' already exists."
+			}
+
+			return "", dbController.NewDuplicateEntryError(msg)
+		}
+
+		return "", dbController.NewDBError(err)
+	}
+
+	if revisionErr := saveRevision(backCtx, tx, id, nil); revisionErr != nil {
+		return "", dbController.NewDBError(revisionErr.Error())
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return "", dbController.NewDBError(commitErr.Error())
+	}
+
+	return id, nil
+}
+
+func (pdbc *PostgresDbController) GetBlogPostById(id string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	if !isValidId(id) {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	return pdbc.getBlogPostWhere(includeUnpublished, `p.id = $1`, id)
+}
+
+func (pdbc *PostgresDbController) GetBlogPostBySlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return pdbc.getBlogPostWhere(includeUnpublished, `p.slug = $1`, slug)
+}
+
+func (pdbc *PostgresDbController) GetBlogPosts(page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	// Matches MongoDB, which rejects negative $skip values and non-positive
+	// $limit values
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	return pdbc.getBlogPostsWhere(page, pagination, includeUnpublished, `TRUE`)
+}
+
+// getBlogPostsWhere returns a page of the visible blog posts that match the
+// where condition, newest first. The limit and offset are added after the
+// where arguments, so the condition can use $1 and up.
+func (pdbc *PostgresDbController) getBlogPostsWhere(page int, pagination int, includeUnpublished bool, where string, whereArgs ...interface{}) ([]*dbController.BlogDocument, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	// Posts added at the same time keep their insertion order
+	query := fmt.Sprintf(
+		`%s WHERE %s AND (%s) ORDER BY p.date_added DESC, p.seq ASC LIMIT $%d OFFSET $%d`,
+		blogPostSelect, visibilityCondition(includeUnpublished), where, len(whereArgs)+1, len(whereArgs)+2,
+	)
+
+	args := append(whereArgs, pagination, (page-1)*pagination)
+
+	return queryBlogPosts(backCtx, pdbc.DB, query, args...)
+}
+
+func (pdbc *PostgresDbController) EditBlogPost(doc *dbController.EditBlogDocument) error {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	print("Editing Blog Post\n")
+
+	if !isValidId(doc.Id) {
+		return dbController.NewInvalidInputError("Invalid User ID")
+	}
+
+	columns := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	setValue := func(column string, value interface{}) {
+		args = append(args, value)
+		columns = append(columns, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if doc.Title != nil {
+		setValue("title", *doc.Title)
+	}
+
+	if doc.Slug != nil {
+		setValue("slug", *doc.Slug)
+	}
+
+	if doc.Body != nil {
+		setValue("body", *doc.Body)
+	}
+
+	if doc.BodyHtml != nil {
+		setValue("body_html", *doc.BodyHtml)
+	}
+
+	if doc.Summary != nil {
+		setValue("excerpt", doc.Summary.Excerpt)
+		setValue("custom_excerpt", doc.Summary.CustomExcerpt)
+		setValue("word_count", doc.Summary.WordCount)
+		setValue("reading_time", doc.Summary.ReadingTimeMinutes)
+	}
+
+	if doc.Tags != nil {
+		setValue("tags", pq.Array(*doc.Tags))
+	}
+
+	if doc.AuthorId != nil {
+		setValue("author_id", *doc.AuthorId)
+	}
+
+	if doc.DateAdded != nil {
+		setValue("date_added", toTimestamp(*doc.DateAdded))
+	}
+
+	if doc.UpdateAuthorId != nil {
+		setValue("update_author_id", *doc.UpdateAuthorId)
+	}
+
+	if doc.DateUpdated != nil {
+		setValue("date_updated", toTimestamp(*doc.DateUpdated))
+	}
+
+	if doc.Status != nil {
+		setValue("status", *doc.Status)
+	}
+
+	if doc.PublishAt != nil {
+		setValue("publish_at", toTimestamp(*doc.PublishAt))
+	}
+
+	tx, txErr := pdbc.DB.BeginTx(backCtx, nil)
+
+	if txErr != nil {
+		return dbController.NewDBError(txErr.Error())
+	}
+	defer tx.Rollback()
+
+	// Locking the row keeps concurrent edits from saving the same revision
+	previous, previousErr := queryBlogPosts(backCtx, tx, blogPostSelect+` WHERE p.id = $1 AND p.deleted_at IS NULL FOR UPDATE OF p`, doc.Id)
+
+	if previousErr != nil {
+		return previousErr
+	}
+
+	if len(previous) == 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts")
+	}
+
+	if doc.Slug != nil && *doc.Slug != previous[0].Slug {
+		if slugErr := checkOldSlug(backCtx, tx, *doc.Slug, doc.Id); slugErr != nil {
+			return slugErr
+		}
+	}
+
+	if len(columns) > 0 {
+		args = append(args, doc.Id)
+		query := fmt.Sprintf(
+			`UPDATE %s SET %s WHERE id = $%d`,
+			BLOG_TABLE,
+			strings.Join(columns, ", "),
+			len(args),
+		)
+
+		_, updateErr := tx.ExecContext(backCtx, query, args...)
+
+		if updateErr != nil {
+			err := updateErr.Error()
+			print("Edit blog error. Error: " + err + "\n")
+
+			if isDuplicateError(updateErr) {
+				msg := "Duplicate blog post."
+				if strings.Contains(err, "slug") {
+					msg = msg + " Blog Post with slug '" + *doc.Slug + "' already exists."
+				}
+
+				return dbController.NewDuplicateEntryError(msg)
+			}
+
+			return dbController.NewDBError(err)
+		}
+	}
+
+	if doc.Slug != nil && *doc.Slug != previous[0].Slug {
+		dateChanged := time.Now()
+		if doc.DateUpdated != nil {
+			dateChanged = *doc.DateUpdated
+		}
+
+		if slugErr := saveOldSlug(backCtx, tx, doc.Id, previous[0].Slug, *doc.Slug, dateChanged); slugErr != nil {
+			return dbController.NewDBError(slugErr.Error())
+		}
+	}
+
+	if revisionErr := saveRevision(backCtx, tx, doc.Id, previous[0]); revisionErr != nil {
+		return dbController.NewDBError(revisionErr.Error())
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return dbController.NewDBError(commitErr.Error())
+	}
+
+	return nil
+}
+
+func (pdbc *PostgresDbController) DeleteBlogPost(doc *dbController.DeleteBlogDocument) error {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	print("Deleting Blog Post\n")
+
+	if !isValidId(doc.Id) {
+		return dbController.NewInvalidInputError("Invalid User ID")
+	}
+
+	delResult, delErr := pdbc.DB.ExecContext(
+		backCtx,
+		`UPDATE `+BLOG_TABLE+` SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL`,
+		toTimestamp(time.Now()), doc.DeletedBy, doc.Id,
+	)
+
+	if delErr != nil {
+		return dbController.NewDBError(delErr.Error())
+	}
+
+	deletedCount, countErr := delResult.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if deletedCount == 0 {
+		return dbController.NewInvalidInputError("invalid id. no blog posts deleted")
+	}
+
+	return nil
+}
+
+func (pdbc *PostgresDbController) AddUserInformation(info *user.UserInformation) error {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	_, upsertErr := pdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+USER_TABLE+` (uid, name, email, active, role) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (uid) DO UPDATE SET
+			name = excluded.name,
+			email = excluded.email,
+			active = excluded.active,
+			role = excluded.role`,
+		info.Uid, info.Name, info.Email, info.Active, info.Role.String(),
+	)
+
+	if upsertErr != nil {
+		if isDuplicateError(upsertErr) {
+			return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + info.Email + "' already exists.")
+		}
+
+		return dbController.NewDBError(upsertErr.Error())
+	}
+
+	return nil
+}
+
+// trimLogs deletes the oldest log rows once there are more than MAX_LOG_ROWS
+func (pdbc *PostgresDbController) trimLogs(backCtx context.Context) error {
+	_, deleteErr := pdbc.DB.ExecContext(
+		backCtx,
+		`DELETE FROM `+LOGGING_TABLE+` WHERE id <= (SELECT MAX(id) FROM `+LOGGING_TABLE+`) - $1`,
+		MAX_LOG_ROWS,
+	)
+
+	return deleteErr
+}
+
+func (pdbc *PostgresDbController) AddRequestLog(log *logging.RequestLogData) error {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	_, insertErr := pdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+LOGGING_TABLE+` (timestamp, type, client_ip, method, path, protocol, status_code, latency, user_agent, error_message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		toTimestamp(log.Timestamp),
+		log.Type,
+		log.ClientIP,
+		log.Method,
+		log.Path,
+		log.Protocol,
+		log.StatusCode,
+		int64(log.Latency),
+		log.UserAgent,
+		log.ErrorMessage,
+	)
+
+	if insertErr != nil {
+		return dbController.NewDBError(insertErr.Error())
+	}
+
+	if trimErr := pdbc.trimLogs(backCtx); trimErr != nil {
+		return dbController.NewDBError(trimErr.Error())
+	}
+
+	return nil
+}
+
+func (pdbc *PostgresDbController) AddInfoLog(log *logging.InfoLogData) error {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	_, insertErr := pdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+LOGGING_TABLE+` (timestamp, type, message) VALUES ($1, $2, $3)`,
+		toTimestamp(log.Timestamp),
+		log.Type,
+		log.Message,
+	)
+
+	if insertErr != nil {
+		return dbController.NewDBError(insertErr.Error())
+	}
+
+	if trimErr := pdbc.trimLogs(backCtx); trimErr != nil {
+		return dbController.NewDBError(trimErr.Error())
+	}
+
+	return nil
+}
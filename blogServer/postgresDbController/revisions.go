@@ -0,0 +1,170 @@
+package postgresDbController
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const blogRevisionSelect = `SELECT
+		post_id,
+		revision,
+		title,
+		slug,
+		body,
+		tags,
+		author_id,
+		date_added,
+		update_author_id,
+		date_updated,
+		status,
+		publish_at,
+		changed_fields
+	FROM ` + BLOG_REVISIONS_TABLE
+
+func insertRevision(backCtx context.Context, tx *sql.Tx, rev *dbController.BlogRevision) error {
+	tags := rev.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	_, insertErr := tx.ExecContext(
+		backCtx,
+		`INSERT INTO `+BLOG_REVISIONS_TABLE+` (post_id, revision, title, slug, body, tags, author_id, date_added, update_author_id, date_updated, status, publish_at, changed_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		rev.PostId,
+		rev.Revision,
+		rev.Title,
+		rev.Slug,
+		rev.Body,
+		pq.Array(tags),
+		rev.AuthorId,
+		toTimestamp(rev.DateAdded),
+		rev.UpdateAuthorId,
+		toTimestamp(rev.DateUpdated),
+		rev.Status,
+		toTimestamp(rev.PublishAt),
+		pq.Array(rev.ChangedFields),
+	)
+
+	return insertErr
+}
+
+// saveRevision saves the current state of the blog post as its next revision.
+// previous is the state of the post before an edit, or nil for a new post.
+// Posts added before revisions were saved get their previous state saved as
+// the first revision.
+func saveRevision(backCtx context.Context, tx *sql.Tx, postId string, previous *dbController.BlogDocument) error {
+	var latest int
+
+	latestErr := tx.QueryRowContext(
+		backCtx,
+		`SELECT COALESCE(MAX(revision), 0) FROM `+BLOG_REVISIONS_TABLE+` WHERE post_id = $1`,
+		postId,
+	).Scan(&latest)
+
+	if latestErr != nil {
+		return latestErr
+	}
+
+	if latest == 0 && previous != nil {
+		if insertErr := insertRevision(backCtx, tx, dbController.NewBlogRevision(previous, 1, nil)); insertErr != nil {
+			return insertErr
+		}
+
+		latest = 1
+	}
+
+	posts, postsErr := queryBlogPosts(backCtx, tx, blogPostSelect+` WHERE p.id = $1`, postId)
+
+	if postsErr != nil {
+		return postsErr
+	}
+
+	if len(posts) == 0 {
+		return dbController.NewNoResultsError("")
+	}
+
+	return insertRevision(backCtx, tx, dbController.NewBlogRevision(posts[0], latest+1, previous))
+}
+
+func (pdbc *PostgresDbController) queryRevisions(query string, args ...interface{}) ([]*dbController.BlogRevision, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := pdbc.DB.QueryContext(backCtx, query, args...)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	revisions := make([]*dbController.BlogRevision, 0)
+
+	for rows.Next() {
+		var rev dbController.BlogRevision
+		var dateAdded, dateUpdated, publishAt time.Time
+
+		scanErr := rows.Scan(
+			&rev.PostId,
+			&rev.Revision,
+			&rev.Title,
+			&rev.Slug,
+			&rev.Body,
+			pq.Array(&rev.Tags),
+			&rev.AuthorId,
+			&dateAdded,
+			&rev.UpdateAuthorId,
+			&dateUpdated,
+			&rev.Status,
+			&publishAt,
+			pq.Array(&rev.ChangedFields),
+		)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		rev.DateAdded = time.Unix(dateAdded.Unix(), 0)
+		rev.DateUpdated = time.Unix(dateUpdated.Unix(), 0)
+		rev.PublishAt = time.Unix(publishAt.Unix(), 0)
+
+		revisions = append(revisions, &rev)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return revisions, nil
+}
+
+func (pdbc *PostgresDbController) GetBlogPostRevisions(postId string) ([]*dbController.BlogRevision, error) {
+	if !isValidId(postId) {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	return pdbc.queryRevisions(blogRevisionSelect+` WHERE post_id = $1 ORDER BY revision DESC`, postId)
+}
+
+func (pdbc *PostgresDbController) GetBlogPostRevision(postId string, revision int) (*dbController.BlogRevision, error) {
+	if !isValidId(postId) {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	revisions, revisionsErr := pdbc.queryRevisions(blogRevisionSelect+` WHERE post_id = $1 AND revision = $2`, postId, revision)
+
+	if revisionsErr != nil {
+		return nil, revisionsErr
+	}
+
+	if len(revisions) < 1 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return revisions[0], nil
+}
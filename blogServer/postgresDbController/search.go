@@ -0,0 +1,128 @@
+package postgresDbController
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// searchTsQuery joins the terms into a tsquery that matches any of them. Search
+// terms only contain letters and numbers, so they don't need escaping.
+func searchTsQuery(terms []string) string {
+	return strings.Join(terms, " | ")
+}
+
+// SearchBlogPosts ranks the posts with the search_vector column that is kept
+// up to date by a trigger. Titles are weighted above tags, which are weighted
+// above the body.
+func (pdbc *PostgresDbController) SearchBlogPosts(query string, page int, pagination int, includeUnpublished bool) ([]*dbController.SearchResult, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	tsQuery := searchTsQuery(terms)
+
+	rows, queryErr := pdbc.DB.QueryContext(
+		backCtx,
+		`SELECT p.id, ts_rank(p.search_vector, q.query)
+		FROM `+BLOG_TABLE+` p, to_tsquery('english', $1) q(query)
+		WHERE p.search_vector @@ q.query AND `+visibilityCondition(includeUnpublished)+`
+		ORDER BY 2 DESC, p.date_added DESC, p.seq ASC
+		LIMIT $2 OFFSET $3`,
+		tsQuery, pagination, (page-1)*pagination,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	scores := make(map[string]float64)
+
+	for rows.Next() {
+		var id string
+		var score float64
+
+		if scanErr := rows.Scan(&id, &score); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		ids = append(ids, id)
+		scores[id] = score
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + rowsErr.Error())
+	}
+
+	results := make([]*dbController.SearchResult, 0)
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	posts, postsErr := queryBlogPosts(backCtx, pdbc.DB, blogPostSelect+` WHERE p.id = ANY($1)`, pq.Array(ids))
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	postsById := make(map[string]*dbController.BlogDocument)
+	for _, post := range posts {
+		postsById[post.Id] = post
+	}
+
+	// The posts are loaded in any order, so they're put back in the order of
+	// the ranking.
+	for _, id := range ids {
+		post, ok := postsById[id]
+
+		if !ok {
+			continue
+		}
+
+		results = append(results, &dbController.SearchResult{
+			Post:    post,
+			Score:   scores[id],
+			Snippet: dbController.MakeSearchSnippet(post.Body, terms),
+		})
+	}
+
+	return results, nil
+}
+
+func (pdbc *PostgresDbController) CountSearchResults(query string, includeUnpublished bool) (int, error) {
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return 0, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	var count int
+
+	queryErr := pdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*)
+		FROM `+BLOG_TABLE+` p, to_tsquery('english', $1) q(query)
+		WHERE p.search_vector @@ q.query AND `+visibilityCondition(includeUnpublished),
+		searchTsQuery(terms),
+	).Scan(&count)
+
+	if queryErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return count, nil
+}
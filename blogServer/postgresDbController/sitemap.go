@@ -0,0 +1,53 @@
+package postgresDbController
+
+import (
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (pdbc *PostgresDbController) GetSitemapEntries(page int, pagination int) ([]*dbController.SitemapEntry, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := pdbc.DB.QueryContext(
+		backCtx,
+		`SELECT p.slug, p.date_added, p.date_updated
+		FROM `+BLOG_TABLE+` p
+		WHERE `+visibilityCondition(false)+`
+		ORDER BY p.date_added DESC, p.seq ASC
+		LIMIT $1 OFFSET $2`,
+		pagination, (page-1)*pagination,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	entries := make([]*dbController.SitemapEntry, 0)
+
+	for rows.Next() {
+		var entry dbController.SitemapEntry
+		var dateAdded, dateUpdated time.Time
+
+		if scanErr := rows.Scan(&entry.Slug, &dateAdded, &dateUpdated); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		entry.DateAdded = time.Unix(dateAdded.Unix(), 0)
+		entry.DateUpdated = time.Unix(dateUpdated.Unix(), 0)
+
+		entries = append(entries, &entry)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return entries, nil
+}
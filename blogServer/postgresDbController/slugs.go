@@ -0,0 +1,87 @@
+package postgresDbController
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// checkOldSlug returns a DuplicateEntryError if a post other than postId used
+// to have the slug. New posts pass an empty postId.
+func checkOldSlug(backCtx context.Context, tx *sql.Tx, slug string, postId string) error {
+	var count int
+
+	queryErr := tx.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM `+BLOG_SLUGS_TABLE+` WHERE slug = $1 AND post_id != $2`,
+		slug, postId,
+	).Scan(&count)
+
+	if queryErr != nil {
+		return dbController.NewDBError(queryErr.Error())
+	}
+
+	if count > 0 {
+		msg := "Duplicate blog post. Slug '" + slug + "' was used by another blog post."
+		return dbController.NewDuplicateEntryError(msg)
+	}
+
+	return nil
+}
+
+// saveOldSlug keeps the slug a post had before an edit changed it. A post
+// going back to one of its old slugs doesn't keep it as an old slug.
+func saveOldSlug(backCtx context.Context, tx *sql.Tx, postId string, oldSlug string, newSlug string, dateChanged time.Time) error {
+	_, deleteErr := tx.ExecContext(
+		backCtx,
+		`DELETE FROM `+BLOG_SLUGS_TABLE+` WHERE slug = $1 AND post_id = $2`,
+		newSlug, postId,
+	)
+
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	_, insertErr := tx.ExecContext(
+		backCtx,
+		`INSERT INTO `+BLOG_SLUGS_TABLE+` (slug, post_id, date_changed) VALUES ($1, $2, $3)
+		ON CONFLICT (slug) DO UPDATE SET post_id = EXCLUDED.post_id, date_changed = EXCLUDED.date_changed`,
+		oldSlug, postId, dateChanged,
+	)
+
+	return insertErr
+}
+
+func (pdbc *PostgresDbController) GetBlogPostByOldSlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return pdbc.getBlogPostWhere(
+		includeUnpublished,
+		`p.id = (SELECT post_id FROM `+BLOG_SLUGS_TABLE+` WHERE slug = $1)`,
+		slug,
+	)
+}
+
+func (pdbc *PostgresDbController) IsSlugAvailable(slug string, id string) (bool, error) {
+	if len(id) > 0 && !isValidId(id) {
+		return false, dbController.NewInvalidInputError("invalid id")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	var taken bool
+
+	queryErr := pdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT EXISTS (SELECT 1 FROM `+BLOG_TABLE+` WHERE slug = $1 AND id != $2)
+			OR EXISTS (SELECT 1 FROM `+BLOG_SLUGS_TABLE+` WHERE slug = $1 AND post_id != $2)`,
+		slug, id,
+	).Scan(&taken)
+
+	if queryErr != nil {
+		return false, dbController.NewDBError(queryErr.Error())
+	}
+
+	return !taken, nil
+}
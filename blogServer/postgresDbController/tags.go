@@ -0,0 +1,54 @@
+package postgresDbController
+
+import (
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (pdbc *PostgresDbController) GetBlogTags(includeUnpublished bool) ([]*dbController.TagCount, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	// Tags are sorted by their bytes, the same way the other backends sort
+	// them.
+	rows, queryErr := pdbc.DB.QueryContext(
+		backCtx,
+		`SELECT t.tag, COUNT(DISTINCT p.id)
+		FROM `+BLOG_TABLE+` p, unnest(p.tags) AS t(tag)
+		WHERE `+visibilityCondition(includeUnpublished)+`
+		GROUP BY t.tag
+		ORDER BY 2 DESC, t.tag COLLATE "C" ASC`,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	tags := make([]*dbController.TagCount, 0)
+
+	for rows.Next() {
+		var tag dbController.TagCount
+
+		if scanErr := rows.Scan(&tag.Tag, &tag.Count); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return tags, nil
+}
+
+func (pdbc *PostgresDbController) GetBlogPostsByTag(tag string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	where, args := filterCondition(&dbController.BlogPostFilter{Tag: tag}, 1)
+
+	return pdbc.getBlogPostsWhere(page, pagination, includeUnpublished, where, args...)
+}
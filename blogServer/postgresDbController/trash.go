@@ -0,0 +1,106 @@
+package postgresDbController
+
+import (
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (pdbc *PostgresDbController) GetTrashedBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	query := blogPostSelect + ` WHERE p.deleted_at IS NOT NULL ORDER BY p.deleted_at DESC, p.seq ASC LIMIT $1 OFFSET $2`
+
+	return queryBlogPosts(backCtx, pdbc.DB, query, pagination, (page-1)*pagination)
+}
+
+func (pdbc *PostgresDbController) CountTrashedBlogPosts() (int, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	var count int
+
+	queryErr := pdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM `+BLOG_TABLE+` WHERE deleted_at IS NOT NULL`,
+	).Scan(&count)
+
+	if queryErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return count, nil
+}
+
+// updateTrashedBlogPost runs a statement that takes the post id as $1 and only
+// changes posts in the trash.
+func (pdbc *PostgresDbController) updateTrashedBlogPost(statement string, id string) error {
+	if !isValidId(id) {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	result, execErr := pdbc.DB.ExecContext(backCtx, statement, id)
+
+	if execErr != nil {
+		return dbController.NewDBError(execErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count == 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts in the trash")
+	}
+
+	return nil
+}
+
+func (pdbc *PostgresDbController) RestoreBlogPost(id string) error {
+	return pdbc.updateTrashedBlogPost(
+		`UPDATE `+BLOG_TABLE+` SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL`,
+		id,
+	)
+}
+
+// PurgeBlogPost deletes a post in the trash. The revisions are deleted by
+// their foreign key.
+func (pdbc *PostgresDbController) PurgeBlogPost(id string) error {
+	return pdbc.updateTrashedBlogPost(
+		`DELETE FROM `+BLOG_TABLE+` WHERE id = $1 AND deleted_at IS NOT NULL`,
+		id,
+	)
+}
+
+func (pdbc *PostgresDbController) PurgeTrashedBlogPosts(deletedBefore time.Time) (int, error) {
+	backCtx, cancel := pdbc.getContext()
+	defer cancel()
+
+	result, execErr := pdbc.DB.ExecContext(
+		backCtx,
+		`DELETE FROM `+BLOG_TABLE+` WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		toTimestamp(deletedBefore),
+	)
+
+	if execErr != nil {
+		return 0, dbController.NewDBError(execErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return 0, dbController.NewDBError(countErr.Error())
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,104 @@
+package blogServer
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+
+	"methompson.com/blog-microservice/blogServer/constants"
+)
+
+// A pathTemplate is the path of a page on the public site. The placeholder is
+// replaced with the slug, tag or uid the page is about.
+type pathTemplate struct {
+	EnvName     string
+	Default     string
+	Placeholder string
+}
+
+var POST_PATH = pathTemplate{
+	EnvName:     constants.POST_PATH_TEMPLATE,
+	Default:     "/blog/post/{slug}",
+	Placeholder: "{slug}",
+}
+
+var TAG_PATH = pathTemplate{
+	EnvName:     constants.TAG_PATH_TEMPLATE,
+	Default:     "/blog/tag/{tag}",
+	Placeholder: "{tag}",
+}
+
+var AUTHOR_PATH = pathTemplate{
+	EnvName:     constants.AUTHOR_PATH_TEMPLATE,
+	Default:     "/blog/author/{uid}",
+	Placeholder: "{uid}",
+}
+
+func (pt *pathTemplate) template() string {
+	if template := os.Getenv(pt.EnvName); len(template) > 0 {
+		return template
+	}
+
+	return pt.Default
+}
+
+func (pt *pathTemplate) check() error {
+	template := pt.template()
+
+	if !strings.HasPrefix(template, "/") || !strings.Contains(template, pt.Placeholder) {
+		return errors.New(pt.EnvName + " must be a path containing " + pt.Placeholder)
+	}
+
+	return nil
+}
+
+// Path returns the path of the page about the value
+func (pt *pathTemplate) Path(value string) string {
+	return strings.ReplaceAll(pt.template(), pt.Placeholder, url.PathEscape(value))
+}
+
+// checkPublicUrlVariables checks PUBLIC_BASE_URL and the path templates when
+// the server starts. PUBLIC_BASE_URL is required, because the Host header of a
+// request can't be trusted to build links that are cached and shared.
+func checkPublicUrlVariables() error {
+	baseUrl := os.Getenv(constants.PUBLIC_BASE_URL)
+
+	if len(baseUrl) == 0 {
+		return errors.New("PUBLIC_BASE_URL environment variable is required")
+	}
+
+	parsed, parseErr := url.Parse(baseUrl)
+
+	if parseErr != nil || len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+		return errors.New("PUBLIC_BASE_URL must be an absolute url")
+	}
+
+	for _, pt := range []*pathTemplate{&POST_PATH, &TAG_PATH, &AUTHOR_PATH} {
+		if checkErr := pt.check(); checkErr != nil {
+			return checkErr
+		}
+	}
+
+	return nil
+}
+
+// PublicUrl returns the absolute public url of a path
+func (srv *BlogServer) PublicUrl(path string) string {
+	return strings.TrimSuffix(os.Getenv(constants.PUBLIC_BASE_URL), "/") + path
+}
+
+// PostUrl returns the absolute public url of the post with the slug
+func (srv *BlogServer) PostUrl(slug string) string {
+	return srv.PublicUrl(POST_PATH.Path(slug))
+}
+
+// TagUrl returns the absolute public url of the page of posts with the tag
+func (srv *BlogServer) TagUrl(tag string) string {
+	return srv.PublicUrl(TAG_PATH.Path(tag))
+}
+
+// AuthorUrl returns the absolute public url of the page of the author
+func (srv *BlogServer) AuthorUrl(uid string) string {
+	return srv.PublicUrl(AUTHOR_PATH.Path(uid))
+}
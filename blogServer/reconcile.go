@@ -0,0 +1,99 @@
+package blogServer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"methompson.com/blog-microservice/blogServer/claims"
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// RECONCILE_PAGINATION is the number of users read from the database at a time
+const RECONCILE_PAGINATION = 100
+
+// getAllUsers returns every user in the database
+func getAllUsers(dbc dbController.DatabaseController) ([]*user.UserInformation, error) {
+	users := make([]*user.UserInformation, 0)
+
+	for page := 1; ; page++ {
+		pageUsers, getErr := dbc.GetUsers(page, RECONCILE_PAGINATION)
+
+		if getErr != nil {
+			return nil, getErr
+		}
+
+		users = append(users, pageUsers...)
+
+		if len(pageUsers) < RECONCILE_PAGINATION {
+			return users, nil
+		}
+	}
+}
+
+// DoReconcileClaims compares the users in the database with the custom claims
+// of the Firebase users and prints the differences. When fixPtr points to
+// true, the claims are changed to match the database.
+func DoReconcileClaims(fixPtr *bool) {
+	fmt.Println("Reconciling Firebase Claims")
+
+	dbc, dbcErr := makeAndInitDatabase()
+
+	if dbcErr != nil {
+		log.Fatal("Error Initializing Database: ", dbcErr.Error())
+	}
+
+	users, usersErr := getAllUsers(dbc)
+
+	if usersErr != nil {
+		log.Fatal("Error getting users: ", usersErr.Error())
+	}
+
+	ctx := context.Background()
+
+	app, appErr := makeFirebaseApp()
+
+	if appErr != nil {
+		log.Fatal(appErr.Error())
+	}
+
+	client, clientErr := app.Auth(ctx)
+
+	if clientErr != nil {
+		log.Fatal(clientErr.Error())
+	}
+
+	drifts, reconcileErr := claims.NewSyncer(claims.FirebaseClient{Client: client}).Reconcile(ctx, users, *fixPtr)
+
+	if reconcileErr != nil {
+		log.Fatal("Error reconciling claims: ", reconcileErr.Error())
+	}
+
+	failed := 0
+
+	for _, drift := range drifts {
+		fmt.Printf("%s: %s\n", drift.Uid, drift.Problem)
+		fmt.Printf("  database: %v\n", drift.Expected)
+		fmt.Printf("  firebase: %v\n", drift.Actual)
+
+		if drift.Fixed {
+			fmt.Println("  fixed")
+		}
+
+		if drift.FixError != nil {
+			fmt.Println("  not fixed:", drift.FixError.Error())
+			failed++
+		}
+	}
+
+	fmt.Printf("Checked %d users. Found %d differences.\n", len(users), len(drifts))
+
+	if !*fixPtr && len(drifts) > 0 {
+		fmt.Println("Run again with -reconcileFix to fix them.")
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d differences could not be fixed", failed)
+	}
+}
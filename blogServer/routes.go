@@ -4,39 +4,1409 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"methompson.com/blog-microservice/blogServer/authenticator"
+	"methompson.com/blog-microservice/blogServer/claims"
 	"methompson.com/blog-microservice/blogServer/dbController"
 )
 
 func (srv *BlogServer) SetRoutes() {
-	srv.GinEngine.GET("/blog", srv.GetBlogPostsByFirstPage)
+	srv.GinEngine.GET("/blog", srv.GetBlogPostsByCursor)
 	srv.GinEngine.GET("/blog/page/:page", srv.GetBlogPostsByPage)
+	srv.GinEngine.GET("/blog/search", srv.SearchBlogPosts)
+	srv.GinEngine.GET("/blog/slug-available", srv.GetSlugAvailability)
+	srv.GinEngine.GET("/blog/tags", srv.GetBlogTags)
+	srv.GinEngine.GET("/blog/tag/:tag", srv.GetBlogPostsByTagFirstPage)
+	srv.GinEngine.GET("/blog/tag/:tag/page/:page", srv.GetBlogPostsByTagPage)
+	srv.GinEngine.GET("/blog/author/:uid", srv.GetBlogPostsByAuthorFirstPage)
+	srv.GinEngine.GET("/blog/author/:uid/page/:page", srv.GetBlogPostsByAuthorPage)
+	srv.GinEngine.GET("/blog/archive", srv.GetBlogArchive)
+	srv.GinEngine.GET("/blog/archive/:year", srv.GetBlogPostsByArchiveDate)
+	srv.GinEngine.GET("/blog/archive/:year/:month", srv.GetBlogPostsByArchiveDate)
 	srv.GinEngine.GET("/blog/id/:id", srv.GetBlogPostById)
 	srv.GinEngine.GET("/blog/post/:slug", srv.GetBlogPostBySlug)
+	srv.GinEngine.GET("/blog/id/:id/revisions", srv.GetBlogPostRevisions)
+	srv.GinEngine.GET("/blog/id/:id/revisions/:revision", srv.GetBlogPostRevision)
+	srv.GinEngine.GET("/blog/id/:id/diff", srv.GetBlogPostDiff)
+	srv.GinEngine.GET("/trash", srv.GetTrashedBlogPostsByFirstPage)
+	srv.GinEngine.GET("/trash/page/:page", srv.GetTrashedBlogPostsByPage)
+	srv.GinEngine.GET("/users", srv.GetUsersByFirstPage)
+	srv.GinEngine.GET("/users/page/:page", srv.GetUsersByPage)
+	srv.GinEngine.GET("/user/:uid", srv.GetUser)
+	srv.GinEngine.GET("/api-keys", srv.GetApiKeys)
+	srv.GinEngine.GET("/sitemap.xml", srv.GetSitemap)
+	srv.GinEngine.GET("/sitemap/:file", srv.GetSitemapPage)
+
+	for _, format := range FEED_FORMATS {
+		srv.GinEngine.GET("/feed."+format, srv.GetFeed(format))
+		srv.GinEngine.GET("/blog/tag/:tag/feed."+format, srv.GetTagFeed(format))
+		srv.GinEngine.GET("/blog/author/:uid/feed."+format, srv.GetAuthorFeed(format))
+	}
 
 	srv.GinEngine.POST("/add-blog-post", srv.PostAddBlogPost)
 	srv.GinEngine.POST("/edit-blog-post", srv.PostEditBlogPost)
 	srv.GinEngine.POST("/delete-blog-post", srv.PostDeleteBlogPost)
+	srv.GinEngine.POST("/restore-blog-revision", srv.PostRestoreBlogRevision)
+	srv.GinEngine.POST("/restore-trashed-blog-post", srv.PostRestoreTrashedBlogPost)
+	srv.GinEngine.POST("/purge-blog-post", srv.PostPurgeBlogPost)
+	srv.GinEngine.POST("/add-user", srv.PostAddUser)
+	srv.GinEngine.POST("/edit-user", srv.PostEditUser)
+	srv.GinEngine.POST("/deactivate-user", srv.PostDeactivateUser)
+	srv.GinEngine.POST("/delete-user", srv.PostDeleteUser)
+	srv.GinEngine.POST("/add-api-key", srv.PostAddApiKey)
+	srv.GinEngine.POST("/revoke-api-key", srv.PostRevokeApiKey)
+}
+
+func (srv *BlogServer) GetBlogPostsByPage(ctx *gin.Context) {
+	page := ctx.Param("page")
+
+	// Not sure this will ever happen
+	if len(page) == 0 {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid page number",
+			},
+		)
+
+		return
+	}
+
+	pageNum, pageNumErr := strconv.Atoi(page)
+
+	if pageNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid page number",
+			},
+		)
+
+		return
+	}
+
+	srv.GetBlogPosts(ctx, pageNum)
+}
+
+// GetBlogPostsByCursor returns the page of blog posts next to the cursor query
+// parameter, or the first page if there isn't one. The next and prev cursors
+// in the response are used to get the pages on either side.
+func (srv *BlogServer) GetBlogPostsByCursor(ctx *gin.Context) {
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	fields, fieldsErr := srv.getFieldsQuery(ctx)
+
+	if fieldsErr != nil {
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	page, getPostsErr := srv.BlogController.GetBlogPostsByCursor(ctx.Query("cursor"), paginationNum, srv.CanViewUnpublished(ctx))
+
+	if getPostsErr != nil {
+		switch getPostsErr.(type) {
+		case InputError, dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": getPostsErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving blog posts"},
+			)
+		}
+
+		return
+	}
+
+	// Version 1 clients expect the bare array /blog returned before cursors
+	if version == API_VERSION_1 {
+		output := make([]map[string]interface{}, 0)
+
+		for _, val := range page.Posts {
+			output = append(output, *val.GetMap())
+		}
+
+		selectPostFields(output, fields)
+
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
+
+	envelope := NewCursorPageEnvelope(page, GetPagination(paginationNum), ctx.Request.URL.RequestURI(), queryParamUrl(ctx, "cursor"))
+	selectPostFields(envelope.Data, fields)
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+func (srv *BlogServer) GetBlogPosts(ctx *gin.Context, page int) {
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	fields, fieldsErr := srv.getFieldsQuery(ctx)
+
+	if fieldsErr != nil {
+		return
+	}
+
+	pagination := ctx.Query("pagination")
+
+	paginationNum, paginationNumErr := strconv.Atoi(pagination)
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	includeUnpublished := srv.CanViewUnpublished(ctx)
+
+	posts, getPostsErr := srv.BlogController.GetBlogPosts(page, paginationNum, includeUnpublished)
+
+	if getPostsErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range posts {
+		output = append(output, *val.GetMap())
+	}
+
+	selectPostFields(output, fields)
+
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
+
+	total, totalErr := srv.BlogController.CountBlogPosts(nil, includeUnpublished)
+
+	if totalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, page, GetPagination(paginationNum), total, pathPageUrl(ctx, "/blog"))
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+func (srv *BlogServer) GetBlogTags(ctx *gin.Context) {
+	tags, getTagsErr := srv.BlogController.GetBlogTags(srv.CanViewUnpublished(ctx))
+
+	if getTagsErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving tags",
+			},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range tags {
+		output = append(output, *val.GetMap())
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		output,
+	)
+}
+
+func (srv *BlogServer) GetBlogPostsByTagPage(ctx *gin.Context) {
+	pageNum, pageNumErr := strconv.Atoi(ctx.Param("page"))
+
+	if pageNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid page number",
+			},
+		)
+
+		return
+	}
+
+	srv.GetBlogPostsByTag(ctx, pageNum)
+}
+
+func (srv *BlogServer) GetBlogPostsByTagFirstPage(ctx *gin.Context) {
+	srv.GetBlogPostsByTag(ctx, 1)
+}
+
+func (srv *BlogServer) GetBlogPostsByTag(ctx *gin.Context, page int) {
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	fields, fieldsErr := srv.getFieldsQuery(ctx)
+
+	if fieldsErr != nil {
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	tag := ctx.Param("tag")
+
+	includeUnpublished := srv.CanViewUnpublished(ctx)
+
+	posts, getPostsErr := srv.BlogController.GetBlogPostsByTag(tag, page, paginationNum, includeUnpublished)
+
+	if getPostsErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range posts {
+		output = append(output, *val.GetMap())
+	}
+
+	selectPostFields(output, fields)
+
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
+
+	total, totalErr := srv.BlogController.CountBlogPosts(&dbController.BlogPostFilter{Tag: tag}, includeUnpublished)
+
+	if totalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, page, GetPagination(paginationNum), total, pathPageUrl(ctx, "/blog/tag/"+url.PathEscape(tag)))
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+func (srv *BlogServer) GetBlogPostsByAuthorPage(ctx *gin.Context) {
+	pageNum, pageNumErr := strconv.Atoi(ctx.Param("page"))
+
+	if pageNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid page number",
+			},
+		)
+
+		return
+	}
+
+	srv.GetBlogPostsByAuthor(ctx, pageNum)
+}
+
+func (srv *BlogServer) GetBlogPostsByAuthorFirstPage(ctx *gin.Context) {
+	srv.GetBlogPostsByAuthor(ctx, 1)
+}
+
+// GetBlogPostsByAuthor returns the public profile of the author along with a
+// page of their blog posts.
+func (srv *BlogServer) GetBlogPostsByAuthor(ctx *gin.Context, page int) {
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	fields, fieldsErr := srv.getFieldsQuery(ctx)
+
+	if fieldsErr != nil {
+		return
+	}
+
+	uid := ctx.Param("uid")
+
+	author, getAuthorErr := srv.BlogController.GetBlogAuthor(uid)
+
+	if getAuthorErr != nil {
+		switch getAuthorErr.(type) {
+		case dbController.NoResultsError:
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				gin.H{"error": "author does not exist"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving author"},
+			)
+		}
+
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	includeUnpublished := srv.CanViewUnpublished(ctx)
+
+	posts, getPostsErr := srv.BlogController.GetBlogPostsByAuthor(uid, page, paginationNum, includeUnpublished)
+
+	if getPostsErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range posts {
+		output = append(output, *val.GetMap())
+	}
+
+	selectPostFields(output, fields)
+
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			gin.H{
+				"author": author.GetMap(),
+				"posts":  output,
+			},
+		)
+		return
+	}
+
+	total, totalErr := srv.BlogController.CountBlogPosts(&dbController.BlogPostFilter{AuthorId: uid}, includeUnpublished)
+
+	if totalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, page, GetPagination(paginationNum), total, pathPageUrl(ctx, "/blog/author/"+url.PathEscape(uid)))
+	envelope.Meta = map[string]interface{}{"author": author.GetMap()}
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+// getPageQuery returns the page number from the page query parameter, or the
+// first page if there isn't one. The request is aborted if the page isn't a
+// number.
+func (srv *BlogServer) getPageQuery(ctx *gin.Context) (int, error) {
+	page := ctx.Query("page")
+
+	if len(page) == 0 {
+		return 1, nil
+	}
+
+	pageNum, pageNumErr := strconv.Atoi(page)
+
+	if pageNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid page number",
+			},
+		)
+	}
+
+	return pageNum, pageNumErr
+}
+
+func (srv *BlogServer) GetBlogArchive(ctx *gin.Context) {
+	archive, getArchiveErr := srv.BlogController.GetBlogArchive(srv.CanViewUnpublished(ctx))
+
+	if getArchiveErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving archive",
+			},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range archive {
+		output = append(output, *val.GetMap())
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		output,
+	)
+}
+
+// GetBlogPostsByArchiveDate returns the blog posts added in the year, or in the
+// month if the route has one. The page number comes from the page query
+// parameter.
+func (srv *BlogServer) GetBlogPostsByArchiveDate(ctx *gin.Context) {
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	fields, fieldsErr := srv.getFieldsQuery(ctx)
+
+	if fieldsErr != nil {
+		return
+	}
+
+	yearNum, yearNumErr := strconv.Atoi(ctx.Param("year"))
+
+	if yearNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid year",
+			},
+		)
+
+		return
+	}
+
+	monthNum := 0
+	if month := ctx.Param("month"); len(month) > 0 {
+		var monthNumErr error
+		monthNum, monthNumErr = strconv.Atoi(month)
+
+		// Month 0 means the whole year to the BlogController
+		if monthNumErr != nil || monthNum == 0 {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{
+					"error": "invalid month",
+				},
+			)
+
+			return
+		}
+	}
+
+	pageNum, pageNumErr := srv.getPageQuery(ctx)
+
+	if pageNumErr != nil {
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	includeUnpublished := srv.CanViewUnpublished(ctx)
+
+	posts, getPostsErr := srv.BlogController.GetBlogPostsByArchiveDate(yearNum, monthNum, pageNum, paginationNum, includeUnpublished)
+
+	if getPostsErr != nil {
+		switch getPostsErr.(type) {
+		case InputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": getPostsErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving blog posts"},
+			)
+		}
+
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range posts {
+		output = append(output, *val.GetMap())
+	}
+
+	selectPostFields(output, fields)
+
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
+
+	// The year and month were checked when getting the posts
+	filter, _ := ArchiveDateFilter(yearNum, monthNum)
+
+	total, totalErr := srv.BlogController.CountBlogPosts(filter, includeUnpublished)
+
+	if totalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, pageNum, GetPagination(paginationNum), total, queryPageUrl(ctx))
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+// SearchBlogPosts takes the search query from q and the page number from page.
+// The page defaults to the first page.
+func (srv *BlogServer) SearchBlogPosts(ctx *gin.Context) {
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	fields, fieldsErr := srv.getFieldsQuery(ctx)
+
+	if fieldsErr != nil {
+		return
+	}
+
+	query := ctx.Query("q")
+
+	pageNum, pageNumErr := srv.getPageQuery(ctx)
+
+	if pageNumErr != nil {
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	includeUnpublished := srv.CanViewUnpublished(ctx)
+
+	results, searchErr := srv.BlogController.SearchBlogPosts(query, pageNum, paginationNum, includeUnpublished)
+
+	if searchErr != nil {
+		switch searchErr.(type) {
+		case InputError, dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": searchErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error searching blog posts"},
+			)
+		}
+
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range results {
+		output = append(output, *val.GetMap())
+	}
+
+	selectPostFields(output, fields)
+
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
+
+	total, totalErr := srv.BlogController.CountSearchResults(query, includeUnpublished)
+
+	if totalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error searching blog posts",
+			},
+		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, pageNum, GetPagination(paginationNum), total, queryPageUrl(ctx))
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+func (srv *BlogServer) GetBlogPostById(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	// Not sure this will ever happen
+	if len(id) == 0 {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid id",
+			},
+		)
+
+		return
+	}
+
+	getBlog, getBlogErr := srv.BlogController.GetBlogPostById(id, srv.CanViewUnpublished(ctx))
+
+	if getBlogErr != nil {
+		switch getBlogErr.(type) {
+		case dbController.NoResultsError:
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				gin.H{"error": "page does not exist"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": getBlogErr.Error()},
+			)
+		}
+
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		getBlog.GetMap(),
+	)
+}
+
+func (srv *BlogServer) GetBlogPostBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	// Not sure this will ever happen
+	if len(slug) == 0 {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid slug",
+			},
+		)
+
+		return
+	}
+
+	includeUnpublished := srv.CanViewUnpublished(ctx)
+
+	getBlog, getBlogErr := srv.BlogController.GetBlogPostBySlug(slug, includeUnpublished)
+
+	if _, ok := getBlogErr.(dbController.NoResultsError); ok {
+		if srv.redirectOldSlug(ctx, slug, includeUnpublished) {
+			return
+		}
+	}
+
+	if getBlogErr != nil {
+		switch getBlogErr.(type) {
+		case dbController.NoResultsError:
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				gin.H{"error": "page does not exist"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": getBlogErr.Error()},
+			)
+		}
+
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		getBlog.GetMap(),
+	)
+}
+
+// redirectOldSlug sends requests for a slug that a blog post used to have to
+// the slug the post has now, with a 301. The body has the new path in
+// redirectTo, for clients that don't follow redirects. It returns false if no
+// post used to have the slug, and true once the request has been answered.
+func (srv *BlogServer) redirectOldSlug(ctx *gin.Context, slug string, includeUnpublished bool) bool {
+	post, postErr := srv.BlogController.GetBlogPostByOldSlug(slug, includeUnpublished)
+
+	if _, ok := postErr.(dbController.NoResultsError); ok {
+		return false
+	}
+
+	if postErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": postErr.Error()},
+		)
+
+		return true
+	}
+
+	redirectTo := "/blog/post/" + url.PathEscape(post.Slug)
+	if len(ctx.Request.URL.RawQuery) > 0 {
+		redirectTo += "?" + ctx.Request.URL.RawQuery
+	}
+
+	ctx.Header("Location", redirectTo)
+	ctx.AbortWithStatusJSON(
+		http.StatusMovedPermanently,
+		gin.H{
+			"redirectTo": redirectTo,
+			"slug":       post.Slug,
+		},
+	)
+
+	return true
+}
+
+// GetSlugAvailability lets editors check a slug before saving a post. The id
+// query parameter is the post being edited, if any.
+func (srv *BlogServer) GetSlugAvailability(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_WRITE)
+
+	if authErr != nil {
+		return
+	}
+
+	slug := ctx.Query("slug")
+
+	if len(slug) == 0 {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "slug is required"},
+		)
+		return
+	}
+
+	availability, availabilityErr := srv.BlogController.CheckSlug(slug, ctx.Query("id"))
+
+	if availabilityErr != nil {
+		switch availabilityErr.(type) {
+		case dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": availabilityErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error checking slug"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, *availability.GetMap())
+}
+
+func (srv *BlogServer) PostAddBlogPost(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_WRITE)
+
+	if authErr != nil {
+		return
+	}
+
+	// Extract the body
+	var body AddBlogBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	// Posts added with an API key always belong to the owner of the key
+	if srv.usedApiKey(ctx) {
+		body.AuthorId = srv.getAuthUid(ctx)
+	}
+
+	id, slug, addBlogErr := srv.BlogController.AddBlogPost(body, srv.getAuthUid(ctx))
+
+	if addBlogErr != nil {
+		switch addBlogErr.(type) {
+		case dbController.DuplicateEntryError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Slug Already Exists"},
+			)
+		case InputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": addBlogErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error adding blog"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		gin.H{
+			"id":   id,
+			"slug": slug,
+		},
+	)
+}
+
+func (srv *BlogServer) PostEditBlogPost(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_WRITE)
+
+	if authErr != nil {
+		return
+	}
+
+	var body EditBlogBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	// API keys act as their owner and can't give posts to other authors
+	if srv.usedApiKey(ctx) {
+		body.AuthorId = nil
+	}
+
+	editBlogErr := srv.BlogController.EditBlogPost(body, srv.getAuthUid(ctx))
+
+	if editBlogErr != nil {
+		switch editBlogErr.(type) {
+		case dbController.DuplicateEntryError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Slug Already Exists"},
+			)
+		case InputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": editBlogErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error adding blog"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+func (srv *BlogServer) PostDeleteBlogPost(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_DELETE)
+
+	if authErr != nil {
+		return
+	}
+
+	var body DeleteBlogBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	deleteBlogErr := srv.BlogController.DeleteBlogPost(body, srv.getAuthUid(ctx))
+
+	if deleteBlogErr != nil {
+		switch deleteBlogErr.(type) {
+		case dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "invalid id. blog does not exist. no blog post deleted"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error deleting blog"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// Revisions can contain unpublished content, so only editors can see them
+func (srv *BlogServer) GetBlogPostRevisions(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_READ)
+
+	if authErr != nil {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	revisions, revisionsErr := srv.BlogController.GetBlogPostRevisions(id)
+
+	if revisionsErr != nil {
+		switch revisionsErr.(type) {
+		case dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": revisionsErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error retrieving revisions"},
+			)
+		}
+
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range revisions {
+		output = append(output, *val.GetMap())
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		output,
+	)
+}
+
+func (srv *BlogServer) GetBlogPostRevision(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_READ)
+
+	if authErr != nil {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	revisionNum, revisionNumErr := strconv.Atoi(ctx.Param("revision"))
+
+	if revisionNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "invalid revision number"},
+		)
+
+		return
+	}
+
+	revision, revisionErr := srv.BlogController.GetBlogPostRevision(id, revisionNum)
+
+	if revisionErr != nil {
+		switch revisionErr.(type) {
+		case dbController.NoResultsError:
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				gin.H{"error": "revision does not exist"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": revisionErr.Error()},
+			)
+		}
+
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		revision.GetMap(),
+	)
+}
+
+// GetBlogPostDiff returns the fields that changed between the from and to
+// revisions passed in the query.
+func (srv *BlogServer) GetBlogPostDiff(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_READ)
+
+	if authErr != nil {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	from, fromErr := strconv.Atoi(ctx.Query("from"))
+	to, toErr := strconv.Atoi(ctx.Query("to"))
+
+	if fromErr != nil || toErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "invalid revision numbers"},
+		)
+
+		return
+	}
+
+	diffs, diffErr := srv.BlogController.DiffBlogPostRevisions(id, from, to)
+
+	if diffErr != nil {
+		switch diffErr.(type) {
+		case dbController.NoResultsError:
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				gin.H{"error": "revision does not exist"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": diffErr.Error()},
+			)
+		}
+
+		return
+	}
+
+	changes := make([]map[string]interface{}, 0)
+
+	for _, val := range diffs {
+		changes = append(changes, *val.GetMap())
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		gin.H{
+			"from":    from,
+			"to":      to,
+			"changes": changes,
+		},
+	)
+}
+
+func (srv *BlogServer) PostRestoreBlogRevision(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_WRITE)
+
+	if authErr != nil {
+		return
+	}
+
+	var body RestoreBlogBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	restoreErr := srv.BlogController.RestoreBlogPostRevision(body, srv.getAuthUid(ctx))
+
+	if restoreErr != nil {
+		switch restoreErr.(type) {
+		case dbController.NoResultsError:
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				gin.H{"error": "revision does not exist"},
+			)
+		case dbController.DuplicateEntryError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Slug Already Exists"},
+			)
+		case dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": restoreErr.Error()},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error restoring blog"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+func (srv *BlogServer) GetTrashedBlogPostsByPage(ctx *gin.Context) {
+	pageNum, pageNumErr := strconv.Atoi(ctx.Param("page"))
+
+	if pageNumErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "invalid page number",
+			},
+		)
+
+		return
+	}
+
+	srv.GetTrashedBlogPosts(ctx, pageNum)
+}
+
+func (srv *BlogServer) GetTrashedBlogPostsByFirstPage(ctx *gin.Context) {
+	srv.GetTrashedBlogPosts(ctx, 1)
+}
+
+func (srv *BlogServer) GetTrashedBlogPosts(ctx *gin.Context, page int) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_READ)
+
+	if authErr != nil {
+		return
+	}
+
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
+	if paginationNumErr != nil {
+		paginationNum = -1
+	}
+
+	posts, getPostsErr := srv.BlogController.GetTrashedBlogPosts(page, paginationNum)
+
+	if getPostsErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range posts {
+		output = append(output, *val.GetMap())
+	}
+
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
+
+	total, totalErr := srv.BlogController.CountTrashedBlogPosts()
+
+	if totalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{
+				"error": "error retrieving blog posts",
+			},
+		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, page, GetPagination(paginationNum), total, pathPageUrl(ctx, "/trash"))
+
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+func (srv *BlogServer) PostRestoreTrashedBlogPost(ctx *gin.Context) {
+	authErr := srv.standardAuthHandler(ctx, SCOPE_POSTS_DELETE)
+
+	if authErr != nil {
+		return
+	}
+
+	var body TrashedBlogBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	restoreErr := srv.BlogController.RestoreBlogPost(body)
+
+	if restoreErr != nil {
+		switch restoreErr.(type) {
+		case dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "invalid id. blog is not in the trash. no blog post restored"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error restoring blog"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// PostPurgeBlogPost permanently deletes a blog post in the trash. Only admins
+// can purge posts.
+func (srv *BlogServer) PostPurgeBlogPost(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
+
+	if authErr != nil {
+		return
+	}
+
+	var body TrashedBlogBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	purgeErr := srv.BlogController.PurgeBlogPost(body)
+
+	if purgeErr != nil {
+		switch purgeErr.(type) {
+		case dbController.InvalidInputError:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "invalid id. blog is not in the trash. no blog post purged"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				gin.H{"error": "error purging blog"},
+			)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
 }
 
-func (srv *BlogServer) GetBlogPostsByPage(ctx *gin.Context) {
-	page := ctx.Param("page")
+// The user routes are only for admins. They manage the users stored in the
+// database, which is where the roles of the blog come from.
 
-	// Not sure this will ever happen
-	if len(page) == 0 {
+// abortWithUserError responds to a failed change to a user. action is what
+// the route was doing, e.g. "adding user".
+func abortWithUserError(ctx *gin.Context, err error, action string) {
+	switch err.(type) {
+	case InputError, dbController.DuplicateEntryError:
 		ctx.AbortWithStatusJSON(
 			http.StatusBadRequest,
-			gin.H{
-				"error": "invalid page number",
-			},
+			gin.H{"error": err.Error()},
+		)
+	case dbController.InvalidInputError:
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "invalid uid. user does not exist"},
+		)
+	case claims.SyncError:
+		// The database was changed, so retrying won't help. The claims are
+		// fixed with the -reconcileClaims command.
+		ctx.AbortWithStatusJSON(
+			http.StatusBadGateway,
+			gin.H{"error": "user saved, but the Firebase claims were not updated"},
+		)
+	default:
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "error " + action},
 		)
-
-		return
 	}
+}
 
-	pageNum, pageNumErr := strconv.Atoi(page)
+func (srv *BlogServer) GetUsersByPage(ctx *gin.Context) {
+	pageNum, pageNumErr := strconv.Atoi(ctx.Param("page"))
 
 	if pageNumErr != nil {
 		ctx.AbortWithStatusJSON(
@@ -49,28 +1419,38 @@ func (srv *BlogServer) GetBlogPostsByPage(ctx *gin.Context) {
 		return
 	}
 
-	srv.GetBlogPosts(ctx, pageNum)
+	srv.GetUsers(ctx, pageNum)
 }
 
-func (srv *BlogServer) GetBlogPostsByFirstPage(ctx *gin.Context) {
-	srv.GetBlogPosts(ctx, 1)
+func (srv *BlogServer) GetUsersByFirstPage(ctx *gin.Context) {
+	srv.GetUsers(ctx, 1)
 }
 
-func (srv *BlogServer) GetBlogPosts(ctx *gin.Context, page int) {
-	pagination := ctx.Query("pagination")
+func (srv *BlogServer) GetUsers(ctx *gin.Context, page int) {
+	authErr := srv.adminAuthHandler(ctx)
 
-	paginationNum, paginationNumErr := strconv.Atoi(pagination)
+	if authErr != nil {
+		return
+	}
+
+	version, versionErr := srv.getApiVersion(ctx)
+
+	if versionErr != nil {
+		return
+	}
+
+	paginationNum, paginationNumErr := strconv.Atoi(ctx.Query("pagination"))
 	if paginationNumErr != nil {
 		paginationNum = -1
 	}
 
-	posts, getPostsErr := srv.BlogController.GetBlogPosts(page, paginationNum)
+	users, getUsersErr := srv.BlogController.GetUsers(page, paginationNum)
 
-	if getPostsErr != nil {
+	if getUsersErr != nil {
 		ctx.AbortWithStatusJSON(
 			http.StatusBadRequest,
 			gin.H{
-				"error": "error retrieving blog posts",
+				"error": "error retrieving users",
 			},
 		)
 		return
@@ -78,105 +1458,157 @@ func (srv *BlogServer) GetBlogPosts(ctx *gin.Context, page int) {
 
 	output := make([]map[string]interface{}, 0)
 
-	for _, val := range posts {
-		output = append(output, *val.GetMap())
+	for _, val := range users {
+		output = append(output, val.Json())
 	}
 
-	ctx.JSON(
-		http.StatusOK,
-		output,
-	)
-}
+	if version == API_VERSION_1 {
+		ctx.JSON(
+			http.StatusOK,
+			output,
+		)
+		return
+	}
 
-func (srv *BlogServer) GetBlogPostById(ctx *gin.Context) {
-	id := ctx.Param("id")
+	total, totalErr := srv.BlogController.CountUsers()
 
-	// Not sure this will ever happen
-	if len(id) == 0 {
+	if totalErr != nil {
 		ctx.AbortWithStatusJSON(
 			http.StatusBadRequest,
 			gin.H{
-				"error": "invalid id",
+				"error": "error retrieving users",
 			},
 		)
+		return
+	}
+
+	envelope := NewPageEnvelope(output, page, GetPagination(paginationNum), total, pathPageUrl(ctx, "/users"))
 
+	ctx.JSON(
+		http.StatusOK,
+		envelope.GetMap(),
+	)
+}
+
+func (srv *BlogServer) GetUser(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
+
+	if authErr != nil {
 		return
 	}
 
-	getBlog, getBlogErr := srv.BlogController.GetBlogPostById(id)
+	info, getUserErr := srv.BlogController.GetUserData(ctx.Param("uid"))
 
-	if getBlogErr != nil {
-		switch getBlogErr.(type) {
+	if getUserErr != nil {
+		switch getUserErr.(type) {
 		case dbController.NoResultsError:
 			ctx.AbortWithStatusJSON(
 				http.StatusNotFound,
-				gin.H{"error": "page does not exist"},
+				gin.H{"error": "user does not exist"},
 			)
 		default:
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
-				gin.H{"error": getBlogErr.Error()},
+				gin.H{"error": "error retrieving user"},
 			)
 		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info.Json())
+}
+
+func (srv *BlogServer) PostAddUser(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
 
+	if authErr != nil {
 		return
 	}
 
-	ctx.JSON(
-		http.StatusOK,
-		getBlog.GetMap(),
-	)
+	var body AddUserBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	info, addUserErr := srv.BlogController.AddUserData(body)
+
+	if addUserErr != nil {
+		abortWithUserError(ctx, addUserErr, "adding user")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info.Json())
 }
 
-func (srv *BlogServer) GetBlogPostBySlug(ctx *gin.Context) {
-	slug := ctx.Param("slug")
+func (srv *BlogServer) PostEditUser(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
 
-	// Not sure this will ever happen
-	if len(slug) == 0 {
+	if authErr != nil {
+		return
+	}
+
+	var body EditUserBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
 		ctx.AbortWithStatusJSON(
 			http.StatusBadRequest,
-			gin.H{
-				"error": "invalid slug",
-			},
+			gin.H{"error": "missing required values"},
 		)
+		return
+	}
 
+	info, editUserErr := srv.BlogController.EditUserData(body, srv.getAuthUid(ctx))
+
+	if editUserErr != nil {
+		abortWithUserError(ctx, editUserErr, "editing user")
 		return
 	}
 
-	getBlog, getBlogErr := srv.BlogController.GetBlogPostBySlug(slug)
+	ctx.JSON(http.StatusOK, info.Json())
+}
 
-	if getBlogErr != nil {
-		switch getBlogErr.(type) {
-		case dbController.NoResultsError:
-			ctx.AbortWithStatusJSON(
-				http.StatusNotFound,
-				gin.H{"error": "page does not exist"},
-			)
-		default:
-			ctx.AbortWithStatusJSON(
-				http.StatusBadRequest,
-				gin.H{"error": getBlogErr.Error()},
-			)
-		}
+// PostDeactivateUser keeps the user, so that their name still shows on their
+// blog posts, but takes away their access.
+func (srv *BlogServer) PostDeactivateUser(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
 
+	if authErr != nil {
 		return
 	}
 
-	ctx.JSON(
-		http.StatusOK,
-		getBlog.GetMap(),
-	)
+	var body UserBody
+
+	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "missing required values"},
+		)
+		return
+	}
+
+	info, deactivateErr := srv.BlogController.DeactivateUserData(body, srv.getAuthUid(ctx))
+
+	if deactivateErr != nil {
+		abortWithUserError(ctx, deactivateErr, "deactivating user")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info.Json())
 }
 
-func (srv *BlogServer) PostAddBlogPost(ctx *gin.Context) {
-	authErr := srv.standardAuthHandler(ctx)
+func (srv *BlogServer) PostDeleteUser(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
 
 	if authErr != nil {
 		return
 	}
 
-	// Extract the body
-	var body AddBlogBody
+	var body UserBody
 
 	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
 		ctx.AbortWithStatusJSON(
@@ -186,41 +1618,55 @@ func (srv *BlogServer) PostAddBlogPost(ctx *gin.Context) {
 		return
 	}
 
-	id, slug, addBlogErr := srv.BlogController.AddBlogPost(body)
+	deleteErr := srv.BlogController.DeleteUserData(body, srv.getAuthUid(ctx))
 
-	if addBlogErr != nil {
-		switch addBlogErr.(type) {
-		case dbController.DuplicateEntryError:
-			ctx.AbortWithStatusJSON(
-				http.StatusBadRequest,
-				gin.H{"error": "Slug Already Exists"},
-			)
-		default:
-			ctx.AbortWithStatusJSON(
-				http.StatusBadRequest,
-				gin.H{"error": "error adding blog"},
-			)
-		}
+	if deleteErr != nil {
+		abortWithUserError(ctx, deleteErr, "deleting user")
 		return
 	}
 
-	ctx.JSON(
-		http.StatusOK,
-		gin.H{
-			"id":   id,
-			"slug": slug,
-		},
-	)
+	ctx.JSON(http.StatusOK, gin.H{})
 }
 
-func (srv *BlogServer) PostEditBlogPost(ctx *gin.Context) {
-	authErr := srv.standardAuthHandler(ctx)
+// The API key routes are only for admins, who have to use a token. API keys
+// can't manage other API keys.
+
+func (srv *BlogServer) GetApiKeys(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
 
 	if authErr != nil {
 		return
 	}
 
-	var body EditBlogBody
+	keys, getKeysErr := srv.BlogController.GetApiKeys()
+
+	if getKeysErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "error retrieving API keys"},
+		)
+		return
+	}
+
+	output := make([]map[string]interface{}, 0)
+
+	for _, val := range keys {
+		output = append(output, *val.GetMap())
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// PostAddApiKey returns the new key along with its information. The key can't
+// be retrieved again.
+func (srv *BlogServer) PostAddApiKey(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
+
+	if authErr != nil {
+		return
+	}
+
+	var body AddApiKeyBody
 
 	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
 		ctx.AbortWithStatusJSON(
@@ -230,35 +1676,38 @@ func (srv *BlogServer) PostEditBlogPost(ctx *gin.Context) {
 		return
 	}
 
-	editBlogErr := srv.BlogController.EditBlogPost(body)
+	key, doc, addErr := srv.BlogController.AddApiKey(body, srv.getAuthUid(ctx))
 
-	if editBlogErr != nil {
-		switch editBlogErr.(type) {
-		case dbController.DuplicateEntryError:
+	if addErr != nil {
+		switch addErr.(type) {
+		case InputError:
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
-				gin.H{"error": "Slug Already Exists"},
+				gin.H{"error": addErr.Error()},
 			)
 		default:
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
-				gin.H{"error": "error adding blog"},
+				gin.H{"error": "error adding API key"},
 			)
 		}
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{})
+	output := *doc.GetMap()
+	output["key"] = key
+
+	ctx.JSON(http.StatusOK, output)
 }
 
-func (srv *BlogServer) PostDeleteBlogPost(ctx *gin.Context) {
-	authErr := srv.standardAuthHandler(ctx)
+func (srv *BlogServer) PostRevokeApiKey(ctx *gin.Context) {
+	authErr := srv.adminAuthHandler(ctx)
 
 	if authErr != nil {
 		return
 	}
 
-	var body DeleteBlogBody
+	var body ApiKeyBody
 
 	if bindJsonErr := ctx.ShouldBindJSON(&body); bindJsonErr != nil {
 		ctx.AbortWithStatusJSON(
@@ -268,19 +1717,19 @@ func (srv *BlogServer) PostDeleteBlogPost(ctx *gin.Context) {
 		return
 	}
 
-	deleteBlogErr := srv.BlogController.DeleteBlogPost(body)
+	revokeErr := srv.BlogController.RevokeApiKey(body)
 
-	if deleteBlogErr != nil {
-		switch deleteBlogErr.(type) {
+	if revokeErr != nil {
+		switch revokeErr.(type) {
 		case dbController.InvalidInputError:
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
-				gin.H{"error": "invalid id. blog does not exist. no blog post deleted"},
+				gin.H{"error": "invalid id. API key does not exist"},
 			)
 		default:
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
-				gin.H{"error": "error deleting blog"},
+				gin.H{"error": "error revoking API key"},
 			)
 		}
 		return
@@ -289,8 +1738,67 @@ func (srv *BlogServer) PostDeleteBlogPost(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{})
 }
 
-func (srv *BlogServer) standardAuthHandler(ctx *gin.Context) error {
-	_, role, getTokenErr := srv.GetTokenAndRoleFromHeader(ctx)
+// AUTH_TOKEN_KEY is the key the auth handlers store the Identity of the
+// verified token under in the gin context.
+const AUTH_TOKEN_KEY = "authToken"
+
+// getAuthUid returns the uid of the user verified by the auth handler, or an
+// empty string if the request wasn't authenticated.
+func (srv *BlogServer) getAuthUid(ctx *gin.Context) string {
+	value, exists := ctx.Get(AUTH_TOKEN_KEY)
+
+	if !exists {
+		return ""
+	}
+
+	identity, ok := value.(*authenticator.Identity)
+
+	if !ok {
+		return ""
+	}
+
+	return identity.Subject
+}
+
+// usedApiKey returns whether the request was authenticated with an API key
+func (srv *BlogServer) usedApiKey(ctx *gin.Context) bool {
+	value, exists := ctx.Get(AUTH_TOKEN_KEY)
+
+	if !exists {
+		return false
+	}
+
+	identity, ok := value.(*authenticator.Identity)
+
+	if !ok {
+		return false
+	}
+
+	_, hasApiKeyId := identity.Claims[API_KEY_ID_CLAIM]
+
+	return hasApiKeyId
+}
+
+// standardAuthHandler lets editors and admins through, along with API keys
+// that have the scope.
+func (srv *BlogServer) standardAuthHandler(ctx *gin.Context, scope string) error {
+	if key, isApiKey := getApiKeyFromHeader(ctx); isApiKey {
+		return srv.apiKeyAuthHandler(ctx, key, scope)
+	}
+
+	return srv.roleAuthHandler(ctx, srv.CanEditBlog)
+}
+
+// adminAuthHandler only lets admins through
+func (srv *BlogServer) adminAuthHandler(ctx *gin.Context) error {
+	return srv.roleAuthHandler(ctx, srv.IsAdmin)
+}
+
+// roleAuthHandler aborts the request unless it has a valid token for a role
+// that isAllowed accepts, from an active user with that role in the database.
+// The Identity of the token is stored in the context for later use.
+func (srv *BlogServer) roleAuthHandler(ctx *gin.Context, isAllowed func(role string) bool) error {
+	identity, role, getTokenErr := srv.GetTokenAndRoleFromHeader(ctx)
 
 	// No Token Error
 	if getTokenErr != nil {
@@ -303,7 +1811,7 @@ func (srv *BlogServer) standardAuthHandler(ctx *gin.Context) error {
 	}
 
 	// Role Error
-	if !srv.CanEditBlog(role) {
+	if !isAllowed(role) {
 		ctx.AbortWithStatusJSON(
 			http.StatusUnauthorized,
 			gin.H{"error": "not authorized"},
@@ -311,5 +1819,86 @@ func (srv *BlogServer) standardAuthHandler(ctx *gin.Context) error {
 		return errors.New("not authorized")
 	}
 
+	// User Error. The token keeps its role until it expires, even if the user
+	// was changed since.
+	_, userErr := srv.BlogController.CheckAuthorizedUser(identity.Subject, role)
+
+	if userErr != nil {
+		switch userErr.(type) {
+		case DeactivatedUserError:
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				gin.H{"error": "account deactivated"},
+			)
+		case UnauthorizedError:
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				gin.H{"error": "not authorized"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "error checking user"},
+			)
+		}
+		return userErr
+	}
+
+	ctx.Set(AUTH_TOKEN_KEY, identity)
+
+	return nil
+}
+
+// getApiKeyFromHeader returns the API key in the Authorization header, if the
+// header has one. The key can be sent with or without the Bearer scheme.
+func getApiKeyFromHeader(ctx *gin.Context) (string, bool) {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+
+	return token, IsApiKey(token)
+}
+
+// apiKeyAuthHandler aborts the request unless the API key can be used for the
+// scope. The request gets the Identity of the owner of the key, so that the
+// changes it makes are attributed to them.
+func (srv *BlogServer) apiKeyAuthHandler(ctx *gin.Context, key string, scope string) error {
+	owner, apiKey, keyErr := srv.BlogController.CheckApiKey(key, scope)
+
+	if keyErr != nil {
+		switch keyErr.(type) {
+		case InvalidApiKeyError:
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				gin.H{"error": "invalid API key"},
+			)
+		case MissingScopeError:
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				gin.H{"error": keyErr.Error()},
+			)
+		case DeactivatedUserError:
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				gin.H{"error": "account deactivated"},
+			)
+		case UnauthorizedError:
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				gin.H{"error": "not authorized"},
+			)
+		default:
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "error checking API key"},
+			)
+		}
+		return keyErr
+	}
+
+	ctx.Set(AUTH_TOKEN_KEY, &authenticator.Identity{
+		Subject: owner.Uid,
+		Role:    owner.Role.String(),
+		Claims:  map[string]interface{}{API_KEY_ID_CLAIM: apiKey.Id},
+	})
+
 	return nil
 }
@@ -0,0 +1,458 @@
+package blogServer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+
+	"methompson.com/blog-microservice/blogServer/authenticator"
+	"methompson.com/blog-microservice/blogServer/constants"
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/memoryDbController"
+)
+
+// TEST_HMAC_SECRET signs the tokens of the test server
+const TEST_HMAC_SECRET = "test-secret"
+
+// makeTestServer returns a server with every route, backed by an empty memory
+// database. Tokens are verified with TEST_HMAC_SECRET.
+func makeTestServer(t *testing.T) *BlogServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var dbc dbController.DatabaseController = memoryDbController.MakeMemoryDbController()
+
+	if initErr := dbc.InitDatabase(); initErr != nil {
+		t.Fatalf("InitDatabase: %v", initErr)
+	}
+
+	auth := authenticator.NewLocalAuthenticator("", "")
+
+	if keyErr := auth.AddHmacKey("", []byte(TEST_HMAC_SECRET)); keyErr != nil {
+		t.Fatalf("AddHmacKey: %v", keyErr)
+	}
+
+	srv := &BlogServer{
+		Authenticator:  auth,
+		BlogController: InitController(&dbc),
+		GinEngine:      gin.New(),
+	}
+	srv.SetRoutes()
+
+	return srv
+}
+
+// makeTestToken returns a token for the user that the test server accepts
+func makeTestToken(t *testing.T, uid string, role string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"sub":  uid,
+		"role": role,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+
+	token, signErr := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(TEST_HMAC_SECRET))
+
+	if signErr != nil {
+		t.Fatalf("SignedString: %v", signErr)
+	}
+
+	return token
+}
+
+// addTestUser adds a user to the database of the test server
+func addTestUser(t *testing.T, srv *BlogServer, uid string, role string) {
+	t.Helper()
+
+	body := AddUserBody{Uid: uid, Name: uid, Email: uid + "@example.com", Role: role}
+
+	if _, addErr := srv.BlogController.AddUserData(body); addErr != nil {
+		t.Fatalf("AddUserData: %v", addErr)
+	}
+}
+
+// serveTestRequest sends the request to the test server. An empty
+// authorization leaves out the Authorization header.
+func serveTestRequest(srv *BlogServer, method string, path string, authorization string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+
+	if len(authorization) > 0 {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.GinEngine.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestBlogPostsDefaultToBareArray(t *testing.T) {
+	srv := makeTestServer(t)
+
+	addBody := AddBlogBody{Title: "Title", Slug: "title", Body: "body", AuthorId: "author", DateAdded: 1000}
+
+	if _, _, addErr := srv.BlogController.AddBlogPost(addBody, "author"); addErr != nil {
+		t.Fatalf("AddBlogPost: %v", addErr)
+	}
+
+	for _, path := range []string{"/blog", "/blog/page/1"} {
+		rec := serveTestRequest(srv, http.MethodGet, path, "", "")
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, rec.Code)
+		}
+
+		var posts []map[string]interface{}
+
+		if jsonErr := json.Unmarshal(rec.Body.Bytes(), &posts); jsonErr != nil {
+			t.Fatalf("%s: body isn't a JSON array: %s", path, rec.Body.String())
+		}
+
+		if len(posts) != 1 || posts[0]["slug"] != "title" {
+			t.Errorf("%s: posts %v", path, posts)
+		}
+	}
+
+	for _, path := range []string{"/blog?v=2", "/blog/page/1?v=2"} {
+		rec := serveTestRequest(srv, http.MethodGet, path, "", "")
+
+		var envelope map[string]interface{}
+
+		if jsonErr := json.Unmarshal(rec.Body.Bytes(), &envelope); jsonErr != nil || envelope["data"] == nil {
+			t.Errorf("%s: body isn't an envelope: %s", path, rec.Body.String())
+		}
+	}
+}
+
+// latestTestRevision returns the newest revision of the blog post
+func latestTestRevision(t *testing.T, srv *BlogServer, postId string) *dbController.BlogRevision {
+	t.Helper()
+
+	revisions, revisionsErr := srv.BlogController.GetBlogPostRevisions(postId)
+
+	if revisionsErr != nil || len(revisions) == 0 {
+		t.Fatalf("GetBlogPostRevisions: %v %v", revisions, revisionsErr)
+	}
+
+	latest := revisions[0]
+	for _, revision := range revisions {
+		if revision.Revision > latest.Revision {
+			latest = revision
+		}
+	}
+
+	return latest
+}
+
+func TestRevisionsUseTheAuthenticatedEditor(t *testing.T) {
+	srv := makeTestServer(t)
+	addTestUser(t, srv, "editor", "editor")
+	addTestUser(t, srv, "admin", "admin")
+
+	addBody := AddBlogBody{Title: "Title", Slug: "title", Body: "body", AuthorId: "author", DateAdded: 1000}
+	postId, _, addErr := srv.BlogController.AddBlogPost(addBody, "author")
+
+	if addErr != nil {
+		t.Fatalf("AddBlogPost: %v", addErr)
+	}
+
+	editBody := `{"id":"` + postId + `","title":"New Title","updateAuthorId":"forged"}`
+	rec := serveTestRequest(srv, http.MethodPost, "/edit-blog-post", makeTestToken(t, "editor", "editor"), editBody)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("edit: status %d %s", rec.Code, rec.Body.String())
+	}
+
+	if revision := latestTestRevision(t, srv, postId); revision.UpdateAuthorId != "editor" {
+		t.Errorf("edit revision UpdateAuthorId = %s, want editor", revision.UpdateAuthorId)
+	}
+
+	restoreBody := `{"id":"` + postId + `","revision":1,"updateAuthorId":"forged"}`
+	rec = serveTestRequest(srv, http.MethodPost, "/restore-blog-revision", makeTestToken(t, "admin", "admin"), restoreBody)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore: status %d %s", rec.Code, rec.Body.String())
+	}
+
+	if revision := latestTestRevision(t, srv, postId); revision.UpdateAuthorId != "admin" {
+		t.Errorf("restore revision UpdateAuthorId = %s, want admin", revision.UpdateAuthorId)
+	}
+
+	post, postErr := srv.BlogController.GetBlogPostById(postId, true)
+
+	if postErr != nil || post.UpdateAuthorId != "admin" || post.Title != "Title" {
+		t.Errorf("post %+v %v", post, postErr)
+	}
+}
+
+func TestApiKeyChangesBelongToTheOwner(t *testing.T) {
+	srv := makeTestServer(t)
+	addTestUser(t, srv, "owner", "editor")
+	addTestUser(t, srv, "admin", "admin")
+
+	key, _, keyErr := srv.BlogController.AddApiKey(AddApiKeyBody{Name: "ci", OwnerId: "owner", Scopes: []string{SCOPE_POSTS_WRITE}}, "admin")
+
+	if keyErr != nil {
+		t.Fatalf("AddApiKey: %v", keyErr)
+	}
+
+	addBody := `{"title":"Title","slug":"title","body":"body","authorId":"forged","dateAdded":1000}`
+	rec := serveTestRequest(srv, http.MethodPost, "/add-blog-post", key, addBody)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("add: status %d %s", rec.Code, rec.Body.String())
+	}
+
+	var added map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &added)
+	postId, _ := added["id"].(string)
+
+	post, postErr := srv.BlogController.GetBlogPostById(postId, true)
+
+	if postErr != nil || post.AuthorId != "owner" || post.UpdateAuthorId != "owner" {
+		t.Fatalf("post %+v %v", post, postErr)
+	}
+
+	editBody := `{"id":"` + postId + `","title":"New Title","authorId":"forged"}`
+	rec = serveTestRequest(srv, http.MethodPost, "/edit-blog-post", "Bearer "+key, editBody)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("edit: status %d %s", rec.Code, rec.Body.String())
+	}
+
+	if revision := latestTestRevision(t, srv, postId); revision.UpdateAuthorId != "owner" || revision.AuthorId != "owner" {
+		t.Errorf("edit revision %+v, want owner as the author and editor", revision)
+	}
+}
+
+// addTestApiKey adds an API key owned by the user and returns the key
+func addTestApiKey(t *testing.T, srv *BlogServer, ownerId string, scopes ...string) (string, *dbController.ApiKeyDocument) {
+	t.Helper()
+
+	key, doc, keyErr := srv.BlogController.AddApiKey(AddApiKeyBody{Name: "test", OwnerId: ownerId, Scopes: scopes}, ownerId)
+
+	if keyErr != nil {
+		t.Fatalf("AddApiKey: %v", keyErr)
+	}
+
+	return key, doc
+}
+
+// getTestApiKey reads the stored API key again
+func getTestApiKey(t *testing.T, srv *BlogServer, key string) *dbController.ApiKeyDocument {
+	t.Helper()
+
+	doc, getErr := (*srv.BlogController.DBController).GetApiKeyByHash(hashApiKey(key))
+
+	if getErr != nil {
+		t.Fatalf("GetApiKeyByHash: %v", getErr)
+	}
+
+	return doc
+}
+
+func TestApiKeyAuth(t *testing.T) {
+	srv := makeTestServer(t)
+	addTestUser(t, srv, "owner", "editor")
+
+	addBody := AddBlogBody{Title: "Title", Slug: "title", Body: "body", DateAdded: 1000}
+	postId, _, addErr := srv.BlogController.AddBlogPost(addBody, "owner")
+
+	if addErr != nil {
+		t.Fatalf("AddBlogPost: %v", addErr)
+	}
+
+	readKey, _ := addTestApiKey(t, srv, "owner", SCOPE_POSTS_READ)
+	writeKey, _ := addTestApiKey(t, srv, "owner", SCOPE_POSTS_WRITE)
+	revokedKey, revokedDoc := addTestApiKey(t, srv, "owner", SCOPE_POSTS_WRITE, SCOPE_POSTS_DELETE)
+
+	if revokeErr := srv.BlogController.RevokeApiKey(ApiKeyBody{Id: revokedDoc.Id}); revokeErr != nil {
+		t.Fatalf("RevokeApiKey: %v", revokeErr)
+	}
+
+	expiredKey := API_KEY_PREFIX + "expired"
+	expiresAt := time.Now().Add(-time.Hour)
+	expiredDoc := dbController.AddApiKeyDocument{
+		Name:      "expired",
+		OwnerId:   "owner",
+		Prefix:    expiredKey[:API_KEY_DISPLAY_LENGTH],
+		Hash:      hashApiKey(expiredKey),
+		Scopes:    []string{SCOPE_POSTS_WRITE, SCOPE_POSTS_DELETE},
+		DateAdded: expiresAt.Add(-time.Hour),
+		ExpiresAt: &expiresAt,
+	}
+
+	if _, expiredErr := (*srv.BlogController.DBController).AddApiKey(&expiredDoc); expiredErr != nil {
+		t.Fatalf("AddApiKey: %v", expiredErr)
+	}
+
+	deleteBody := `{"id":"` + postId + `"}`
+
+	tests := []struct {
+		name       string
+		key        string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"unknown key", API_KEY_PREFIX + "unknown", http.MethodPost, "/delete-blog-post", deleteBody, http.StatusUnauthorized},
+		{"revoked key", revokedKey, http.MethodPost, "/delete-blog-post", deleteBody, http.StatusUnauthorized},
+		{"expired key", expiredKey, http.MethodPost, "/delete-blog-post", deleteBody, http.StatusUnauthorized},
+		{"missing scope", writeKey, http.MethodPost, "/delete-blog-post", deleteBody, http.StatusForbidden},
+		{"read key can't write", readKey, http.MethodPost, "/edit-blog-post", `{"id":"` + postId + `","title":"T"}`, http.StatusForbidden},
+		{"admin routes reject keys", writeKey, http.MethodGet, "/api-keys", "", http.StatusUnauthorized},
+		{"scope allows the route", writeKey, http.MethodPost, "/edit-blog-post", `{"id":"` + postId + `","title":"T"}`, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		rec := serveTestRequest(srv, test.method, test.path, test.key, test.body)
+
+		if rec.Code != test.wantStatus {
+			t.Errorf("%s: status %d, want %d %s", test.name, rec.Code, test.wantStatus, rec.Body.String())
+		}
+	}
+
+	// Requests that change posts record when the key was used
+	if doc := getTestApiKey(t, srv, writeKey); doc.LastUsed == nil {
+		t.Errorf("write key lastUsed wasn't saved")
+	}
+
+	if _, deactivateErr := srv.BlogController.DeactivateUserData(UserBody{Uid: "owner"}, "admin"); deactivateErr != nil {
+		t.Fatalf("DeactivateUserData: %v", deactivateErr)
+	}
+
+	rec := serveTestRequest(srv, http.MethodPost, "/edit-blog-post", writeKey, `{"id":"`+postId+`","title":"T2"}`)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("deactivated owner: status %d, want 403", rec.Code)
+	}
+}
+
+func TestApiKeyCanViewUnpublished(t *testing.T) {
+	srv := makeTestServer(t)
+	addTestUser(t, srv, "owner", "editor")
+
+	draft := dbController.BLOG_STATUS_DRAFT
+	addBody := AddBlogBody{Title: "Title", Slug: "title", Body: "body", DateAdded: 1000, Status: &draft}
+	postId, _, addErr := srv.BlogController.AddBlogPost(addBody, "owner")
+
+	if addErr != nil {
+		t.Fatalf("AddBlogPost: %v", addErr)
+	}
+
+	readKey, _ := addTestApiKey(t, srv, "owner", SCOPE_POSTS_READ)
+	writeKey, _ := addTestApiKey(t, srv, "owner", SCOPE_POSTS_WRITE)
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{"no key", "", http.StatusNotFound},
+		{"read key", readKey, http.StatusOK},
+		{"read key with bearer", "Bearer " + readKey, http.StatusOK},
+		{"key without posts:read", writeKey, http.StatusNotFound},
+		{"unknown key", API_KEY_PREFIX + "unknown", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		rec := serveTestRequest(srv, http.MethodGet, "/blog/id/"+postId, test.key, "")
+
+		if rec.Code != test.wantStatus {
+			t.Errorf("%s: status %d, want %d", test.name, rec.Code, test.wantStatus)
+		}
+	}
+
+	// Public reads don't write to the database
+	for _, key := range []string{readKey, writeKey} {
+		if doc := getTestApiKey(t, srv, key); doc.LastUsed != nil {
+			t.Errorf("public read saved lastUsed %v", doc.LastUsed)
+		}
+	}
+}
+
+func TestDeactivatedUserIsForbidden(t *testing.T) {
+	srv := makeTestServer(t)
+	srv.BlogController.UserCache = NewUserCache(50 * time.Millisecond)
+	addTestUser(t, srv, "editor", "editor")
+
+	token := makeTestToken(t, "editor", "editor")
+
+	if rec := serveTestRequest(srv, http.MethodGet, "/trash", token, ""); rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	active := false
+	doc := dbController.EditUserDocument{Uid: "editor", Active: &active}
+
+	if editErr := (*srv.BlogController.DBController).EditUserInformation(&doc); editErr != nil {
+		t.Fatalf("EditUserInformation: %v", editErr)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if rec := serveTestRequest(srv, http.MethodGet, "/trash", token, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestPublicLinksIgnoreTheHostHeader(t *testing.T) {
+	os.Setenv(constants.PUBLIC_BASE_URL, "https://blog.example.com/")
+	defer os.Unsetenv(constants.PUBLIC_BASE_URL)
+
+	srv := makeTestServer(t)
+
+	addBody := AddBlogBody{Title: "Title", Slug: "title", Body: "body", AuthorId: "author", DateAdded: 1000}
+
+	if _, _, addErr := srv.BlogController.AddBlogPost(addBody, "author"); addErr != nil {
+		t.Fatalf("AddBlogPost: %v", addErr)
+	}
+
+	for _, path := range []string{"/sitemap.xml", "/feed.atom", "/feed.json"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "attacker.example.com"
+
+		rec := httptest.NewRecorder()
+		srv.GinEngine.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, rec.Code)
+		}
+
+		if strings.Contains(rec.Body.String(), "attacker.example.com") {
+			t.Errorf("%s: links use the Host header: %s", path, rec.Body.String())
+		}
+
+		if !strings.Contains(rec.Body.String(), "https://blog.example.com/blog/post/title") {
+			t.Errorf("%s: missing the post link: %s", path, rec.Body.String())
+		}
+	}
+}
+
+func TestPublicBaseUrlIsRequired(t *testing.T) {
+	defer os.Unsetenv(constants.PUBLIC_BASE_URL)
+
+	values := map[string]bool{
+		"":                         false,
+		"blog.example.com":         false,
+		"/blog":                    false,
+		"https://blog.example.com": true,
+	}
+
+	for value, valid := range values {
+		os.Setenv(constants.PUBLIC_BASE_URL, value)
+
+		if checkErr := checkPublicUrlVariables(); (checkErr == nil) != valid {
+			t.Errorf("%q: error %v", value, checkErr)
+		}
+	}
+}
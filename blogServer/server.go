@@ -10,27 +10,31 @@ import (
 	"time"
 
 	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/auth"
 	"github.com/gin-gonic/gin"
 	"google.golang.org/api/option"
 
+	"methompson.com/blog-microservice/blogServer/authenticator"
+	"methompson.com/blog-microservice/blogServer/claims"
 	"methompson.com/blog-microservice/blogServer/constants"
 	"methompson.com/blog-microservice/blogServer/dbController"
 	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/memoryDbController"
 	"methompson.com/blog-microservice/blogServer/mongoDbController"
+	"methompson.com/blog-microservice/blogServer/postgresDbController"
+	"methompson.com/blog-microservice/blogServer/sqliteDbController"
 )
 
 func MakeAndStartServer() {
 	envErr := checkEnvVariables()
 
 	if envErr != nil {
-		log.Fatal("Error with environment variables")
+		log.Fatal("Error with environment variables: ", envErr.Error())
 	}
 
 	blogServer, srvErr := makeServer()
 
 	if srvErr != nil {
-		log.Fatal("Error making server")
+		log.Fatal("Error making server: ", srvErr.Error())
 	}
 
 	// We run this after creating a server, but before setting routes. Any
@@ -51,6 +55,10 @@ func MakeAndStartServer() {
 
 	blogServer.SetRoutes()
 
+	if trashErr := startTrashRetentionJob(blogServer); trashErr != nil {
+		log.Fatal("Error starting trash retention job: ", trashErr.Error())
+	}
+
 	blogServer.StartServer()
 }
 
@@ -112,49 +120,97 @@ func addRecovery(bs *BlogServer) {
 	}))
 }
 
-func makeAndInitDatabase() (*mongoDbController.MongoDbController, error) {
-	mdbController, mdbControllerErr := mongoDbController.MakeMongoDbController(constants.BLOG_DB_NAME)
+// makeDatabaseController constructs the DatabaseController selected by the
+// DB_TYPE environment variable. MongoDB is used when DB_TYPE isn't set.
+func makeDatabaseController() (dbController.DatabaseController, error) {
+	dbType := os.Getenv(constants.DB_TYPE)
 
-	if mdbControllerErr != nil {
-		// log.Fatal(mdbControllerErr.Error())
-		return nil, mdbControllerErr
+	switch dbType {
+	case "", constants.DB_TYPE_MONGODB:
+		mdbController, mdbControllerErr := mongoDbController.MakeMongoDbController(constants.BLOG_DB_NAME)
+
+		if mdbControllerErr != nil {
+			return nil, mdbControllerErr
+		}
+
+		return mdbController, nil
+	case constants.DB_TYPE_MEMORY:
+		return memoryDbController.MakeMemoryDbController(), nil
+	case constants.DB_TYPE_SQLITE:
+		sdbController, sdbControllerErr := sqliteDbController.MakeSqliteDbController()
+
+		if sdbControllerErr != nil {
+			return nil, sdbControllerErr
+		}
+
+		return sdbController, nil
+	case constants.DB_TYPE_POSTGRES:
+		pdbController, pdbControllerErr := postgresDbController.MakePostgresDbController()
+
+		if pdbControllerErr != nil {
+			return nil, pdbControllerErr
+		}
+
+		return pdbController, nil
 	}
 
-	initDbErr := mdbController.InitDatabase()
+	return nil, errors.New("unknown database type: " + dbType)
+}
+
+func makeAndInitDatabase() (dbController.DatabaseController, error) {
+	dbc, dbcErr := makeDatabaseController()
+
+	if dbcErr != nil {
+		// log.Fatal(dbcErr.Error())
+		return nil, dbcErr
+	}
+
+	initDbErr := dbc.InitDatabase()
 
 	if initDbErr != nil {
 		// log.Fatal("Error Initializing Database: ", initDbErr.Error())
 		return nil, initDbErr
 	}
 
-	return mdbController, nil
+	return dbc, nil
 }
 
 func makeServer() (*BlogServer, error) {
-	mdbController, mdbControllerErr := makeAndInitDatabase()
+	passedController, dbcErr := makeAndInitDatabase()
 
-	if mdbControllerErr != nil {
-		log.Fatal("Error Initializing Database: ", mdbControllerErr.Error())
+	if dbcErr != nil {
+		log.Fatal("Error Initializing Database: ", dbcErr.Error())
 	}
 
-	app, err := makeFirebaseApp()
+	tokenAuthenticator, app, authErr := makeAuthenticator()
 
-	if err != nil {
-		return nil, errors.New("error making firebase app")
+	if authErr != nil {
+		return nil, authErr
 	}
 
 	engine := makeGinEngine()
 
-	// First we assign the pointer-to MongoDbController of mongoDbController to
-	// a variable of type DatabaseController. Then we get the pointer-to
-	// DatabaseController and assign that to cont. We can use pointer-to
-	// DatabaseController to run InitController to initialize the BlogController.
-	var passedController dbController.DatabaseController = mdbController
+	// We get the pointer-to DatabaseController and pass that to InitController
+	// to initialize the BlogController.
 	ptrToCont := &passedController
 
+	controller := InitController(ptrToCont)
+
+	// Claims can only be synced when the users sign in with Firebase
+	if app != nil {
+		authClient, clientErr := app.Auth(context.Background())
+
+		if clientErr != nil {
+			return nil, errors.New("error making firebase auth client")
+		}
+
+		controller.ClaimsSyncer = claims.NewSyncer(claims.FirebaseClient{Client: authClient})
+	}
+
 	srv := BlogServer{
 		FirebaseApp:    app,
-		BlogController: InitController(ptrToCont),
+		Authenticator:  tokenAuthenticator,
+		BlogController: controller,
 		GinEngine:      engine,
 	}
 
@@ -234,13 +290,33 @@ func addCorsMiddleware(bs *BlogServer) {
 	)
 }
 
-// TODO check environment variables
+// checkEnvVariables checks PUBLIC_BASE_URL and the optional environment
+// variables, so that a missing or bad value stops the server when it starts
+// instead of failing requests later.
 func checkEnvVariables() error {
+	if _, versionErr := getDefaultApiVersion(); versionErr != nil {
+		return versionErr
+	}
+
+	if urlErr := checkPublicUrlVariables(); urlErr != nil {
+		return urlErr
+	}
+
+	if _, slugErr := getSlugMode(); slugErr != nil {
+		return slugErr
+	}
+
+	if _, providerErr := getAuthProvider(); providerErr != nil {
+		return providerErr
+	}
+
 	return nil
 }
 
+// FirebaseApp is nil unless AUTH_PROVIDER is firebase
 type BlogServer struct {
 	FirebaseApp    *firebase.App
+	Authenticator  authenticator.Authenticator
 	BlogController BlogController
 	GinEngine      *gin.Engine
 }
@@ -249,26 +325,18 @@ func (srv *BlogServer) StartServer() {
 	srv.GinEngine.Run()
 }
 
-func (srv *BlogServer) ValidateIdToken(header AuthorizationHeader) (*auth.Token, error) {
-	ctx := context.Background()
-	client, clientErr := srv.FirebaseApp.Auth(ctx)
-
-	if clientErr != nil {
-		fmt.Println(clientErr)
-		return nil, clientErr
-	}
-
-	token, tokenErr := client.VerifyIDToken(ctx, header.Token)
+func (srv *BlogServer) ValidateIdToken(header AuthorizationHeader) (*authenticator.Identity, error) {
+	identity, tokenErr := srv.Authenticator.VerifyToken(context.Background(), header.Token)
 
 	if tokenErr != nil {
 		fmt.Println(tokenErr)
 		return nil, tokenErr
 	}
 
-	return token, nil
+	return identity, nil
 }
 
-func (srv *BlogServer) GetAuthorizationHeader(ctx *gin.Context) (*auth.Token, error) {
+func (srv *BlogServer) GetAuthorizationHeader(ctx *gin.Context) (*authenticator.Identity, error) {
 	var header AuthorizationHeader
 
 	// No Token Error
@@ -278,45 +346,71 @@ func (srv *BlogServer) GetAuthorizationHeader(ctx *gin.Context) (*auth.Token, er
 		return nil, headerErr
 	}
 
-	token, tokenErr := srv.ValidateIdToken(header)
+	identity, tokenErr := srv.ValidateIdToken(header)
 
 	if tokenErr != nil {
 		return nil, tokenErr
 	}
 
-	return token, nil
+	return identity, nil
 }
 
-func (srv *BlogServer) GetRoleFromToken(token *auth.Token) (string, error) {
-	roleInt := token.Claims["role"]
-
-	role, ok := roleInt.(string)
-
-	if !ok {
-		fmt.Println(role)
+func (srv *BlogServer) GetRoleFromIdentity(identity *authenticator.Identity) (string, error) {
+	if len(identity.Role) == 0 {
 		return "", errors.New("role is not a string")
 	}
 
-	return role, nil
+	return identity.Role, nil
 }
 
-func (srv *BlogServer) GetTokenAndRoleFromHeader(ctx *gin.Context) (*auth.Token, string, error) {
-	token, tokenErr := srv.GetAuthorizationHeader(ctx)
+func (srv *BlogServer) GetTokenAndRoleFromHeader(ctx *gin.Context) (*authenticator.Identity, string, error) {
+	identity, tokenErr := srv.GetAuthorizationHeader(ctx)
 
 	// No Token Error
 	if tokenErr != nil {
 		return nil, "", tokenErr
 	}
 
-	role, roleErr := srv.GetRoleFromToken(token)
+	role, roleErr := srv.GetRoleFromIdentity(identity)
 
 	if roleErr != nil {
 		return nil, "", roleErr
 	}
 
-	return token, role, nil
+	return identity, role, nil
 }
 
 func (srv *BlogServer) CanEditBlog(role string) bool {
 	return role == constants.USER_ADMIN || role == constants.USER_EDITOR
 }
+
+func (srv *BlogServer) IsAdmin(role string) bool {
+	return role == constants.USER_ADMIN
+}
+
+// CanViewUnpublished checks the optional Authorization header of a public
+// request. Active editors can see drafts and scheduled posts, everyone else
+// only sees published posts, unless they use an API key with the posts:read
+// scope. Unlike standardAuthHandler, an invalid token doesn't abort the
+// request, and reading doesn't update the lastUsed time of an API key.
+func (srv *BlogServer) CanViewUnpublished(ctx *gin.Context) bool {
+	if len(ctx.GetHeader("Authorization")) == 0 {
+		return false
+	}
+
+	if key, isApiKey := getApiKeyFromHeader(ctx); isApiKey {
+		_, _, keyErr := srv.BlogController.VerifyApiKey(key, SCOPE_POSTS_READ)
+
+		return keyErr == nil
+	}
+
+	identity, role, tokenErr := srv.GetTokenAndRoleFromHeader(ctx)
+
+	if tokenErr != nil || !srv.CanEditBlog(role) {
+		return false
+	}
+
+	_, userErr := srv.BlogController.CheckAuthorizedUser(identity.Subject, role)
+
+	return userErr == nil
+}
@@ -0,0 +1,231 @@
+package blogServer
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SITEMAP_MAX_URLS is the most urls a sitemap can list. Sites with more urls
+// are split into several sitemaps listed by a sitemap index.
+const SITEMAP_MAX_URLS = 50000
+
+const SITEMAP_XMLNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapUrl struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapUrlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Urls    []sitemapUrl `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapUrl `xml:"sitemap"`
+}
+
+// A sitemapPlan is what's needed to split the urls between sitemaps. Posts
+// come first, followed by the tag and author pages.
+type sitemapPlan struct {
+	PostCount int
+	PageUrls  []sitemapUrl
+}
+
+func (sp *sitemapPlan) SitemapCount() int {
+	total := sp.PostCount + len(sp.PageUrls)
+
+	if total == 0 {
+		return 1
+	}
+
+	return (total + SITEMAP_MAX_URLS - 1) / SITEMAP_MAX_URLS
+}
+
+func (srv *BlogServer) makeSitemapPlan() (*sitemapPlan, error) {
+	postCount, countErr := srv.BlogController.CountBlogPosts(nil, false)
+
+	if countErr != nil {
+		return nil, countErr
+	}
+
+	tags, tagsErr := srv.BlogController.GetBlogTags(false)
+
+	if tagsErr != nil {
+		return nil, tagsErr
+	}
+
+	authors, authorsErr := srv.BlogController.GetBlogAuthors(false)
+
+	if authorsErr != nil {
+		return nil, authorsErr
+	}
+
+	plan := sitemapPlan{
+		PostCount: postCount,
+		PageUrls:  make([]sitemapUrl, 0),
+	}
+
+	for _, tag := range tags {
+		plan.PageUrls = append(plan.PageUrls, sitemapUrl{Loc: srv.TagUrl(tag.Tag)})
+	}
+
+	for _, author := range authors {
+		plan.PageUrls = append(plan.PageUrls, sitemapUrl{Loc: srv.AuthorUrl(author.UID)})
+	}
+
+	return &plan, nil
+}
+
+// getSitemapUrls returns the urls listed by a sitemap, numbered from 1. Each
+// sitemap gets a page of posts, so the posts are paged with SITEMAP_MAX_URLS.
+// Only the slugs and dates of the posts are read.
+func (srv *BlogServer) getSitemapUrls(plan *sitemapPlan, sitemap int) ([]sitemapUrl, error) {
+	urls := make([]sitemapUrl, 0)
+
+	start := (sitemap - 1) * SITEMAP_MAX_URLS
+	end := sitemap * SITEMAP_MAX_URLS
+
+	if start < plan.PostCount {
+		entries, getEntriesErr := srv.BlogController.GetSitemapEntries(sitemap, SITEMAP_MAX_URLS)
+
+		if getEntriesErr != nil {
+			return nil, getEntriesErr
+		}
+
+		for _, entry := range entries {
+			urls = append(urls, sitemapUrl{
+				Loc:     srv.PostUrl(entry.Slug),
+				LastMod: entry.LastModified().UTC().Format(time.RFC3339),
+			})
+		}
+	}
+
+	pageStart := start - plan.PostCount
+	if pageStart < 0 {
+		pageStart = 0
+	}
+
+	pageEnd := end - plan.PostCount
+	if pageEnd > len(plan.PageUrls) {
+		pageEnd = len(plan.PageUrls)
+	}
+
+	if pageStart < pageEnd {
+		urls = append(urls, plan.PageUrls[pageStart:pageEnd]...)
+	}
+
+	return urls, nil
+}
+
+func (srv *BlogServer) writeSitemap(ctx *gin.Context, plan *sitemapPlan, sitemap int) {
+	urls, urlsErr := srv.getSitemapUrls(plan, sitemap)
+
+	if urlsErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "error retrieving blog posts"},
+		)
+		return
+	}
+
+	body, marshalErr := marshalXml(sitemapUrlSet{Xmlns: SITEMAP_XMLNS, Urls: urls})
+
+	if marshalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "error rendering sitemap"},
+		)
+		return
+	}
+
+	writeConditional(ctx, body, "application/xml; charset=utf-8", time.Time{})
+}
+
+// GetSitemap returns the sitemap of the public site. Once there are too many
+// urls for one sitemap, it returns a sitemap index instead.
+func (srv *BlogServer) GetSitemap(ctx *gin.Context) {
+	plan, planErr := srv.makeSitemapPlan()
+
+	if planErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "error retrieving blog posts"},
+		)
+		return
+	}
+
+	sitemapCount := plan.SitemapCount()
+
+	if sitemapCount == 1 {
+		srv.writeSitemap(ctx, plan, 1)
+		return
+	}
+
+	index := sitemapIndex{
+		Xmlns:    SITEMAP_XMLNS,
+		Sitemaps: make([]sitemapUrl, 0),
+	}
+
+	for i := 1; i <= sitemapCount; i++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapUrl{
+			Loc: srv.PublicUrl("/sitemap/" + strconv.Itoa(i) + ".xml"),
+		})
+	}
+
+	body, marshalErr := marshalXml(index)
+
+	if marshalErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "error rendering sitemap"},
+		)
+		return
+	}
+
+	writeConditional(ctx, body, "application/xml; charset=utf-8", time.Time{})
+}
+
+// GetSitemapPage returns one of the sitemaps listed by the sitemap index. The
+// file param is the number of the sitemap followed by .xml.
+func (srv *BlogServer) GetSitemapPage(ctx *gin.Context) {
+	file := ctx.Param("file")
+
+	sitemap, sitemapErr := strconv.Atoi(strings.TrimSuffix(file, ".xml"))
+
+	if !strings.HasSuffix(file, ".xml") || sitemapErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusNotFound,
+			gin.H{"error": "sitemap does not exist"},
+		)
+		return
+	}
+
+	plan, planErr := srv.makeSitemapPlan()
+
+	if planErr != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			gin.H{"error": "error retrieving blog posts"},
+		)
+		return
+	}
+
+	if sitemap < 1 || sitemap > plan.SitemapCount() {
+		ctx.AbortWithStatusJSON(
+			http.StatusNotFound,
+			gin.H{"error": "sitemap does not exist"},
+		)
+		return
+	}
+
+	srv.writeSitemap(ctx, plan, sitemap)
+}
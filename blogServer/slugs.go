@@ -0,0 +1,181 @@
+package blogServer
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/constants"
+)
+
+// The SLUG_MODE decides what happens when a new slug is already taken. With
+// error, the post isn't saved. With number or date, a suffix is added to the
+// slug until it's unique, e.g. my-post-2 or my-post-2021-09-14.
+const (
+	SLUG_MODE_ERROR  = "error"
+	SLUG_MODE_NUMBER = "number"
+	SLUG_MODE_DATE   = "date"
+)
+
+const DEFAULT_SLUG_MODE = SLUG_MODE_ERROR
+
+// MAX_SLUG_SUFFIX is the highest number added to a slug before giving up on
+// finding one that isn't taken.
+const MAX_SLUG_SUFFIX = 100
+
+// SLUG_SUGGESTIONS is the number of alternatives suggested for a slug that
+// can't be used.
+const SLUG_SUGGESTIONS = 3
+
+const SLUG_DATE_FORMAT = "2006-01-02"
+
+func getSlugMode() (string, error) {
+	mode := os.Getenv(constants.SLUG_MODE)
+
+	switch mode {
+	case "":
+		return DEFAULT_SLUG_MODE, nil
+	case SLUG_MODE_ERROR, SLUG_MODE_NUMBER, SLUG_MODE_DATE:
+		return mode, nil
+	}
+
+	return "", errors.New("SLUG_MODE must be error, number or date")
+}
+
+// slugCandidates returns the slugs tried, in order, to find a unique slug
+// from the base slug.
+func slugCandidates(base string, mode string, date time.Time) []string {
+	candidates := []string{base}
+
+	if mode == SLUG_MODE_DATE {
+		base = base + "-" + date.UTC().Format(SLUG_DATE_FORMAT)
+		candidates = append(candidates, base)
+	}
+
+	for i := 2; i <= MAX_SLUG_SUFFIX; i++ {
+		candidates = append(candidates, base+"-"+strconv.Itoa(i))
+	}
+
+	return candidates
+}
+
+// SlugAvailability is the answer to whether a slug can be used for a post
+type SlugAvailability struct {
+	Slug        string
+	Valid       bool
+	Available   bool
+	Suggestions []string
+}
+
+func (sa *SlugAvailability) GetMap() *map[string]interface{} {
+	m := make(map[string]interface{})
+
+	m["slug"] = sa.Slug
+	m["valid"] = sa.Valid
+	m["available"] = sa.Available
+	m["suggestions"] = sa.Suggestions
+
+	return &m
+}
+
+// resolveSlug returns the slug a post is saved with. Invalid slugs are
+// replaced with one made from the title. Taken slugs are made unique when the
+// SLUG_MODE allows it, and left for the database to reject otherwise. id is
+// empty for new posts.
+func (bc *BlogController) resolveSlug(candidate string, title *string, id string, date time.Time) (string, error) {
+	slug := candidate
+
+	if !bc.isValidSlug(slug) {
+		if title == nil {
+			return "", NewInputError("invalid slug and no title")
+		}
+
+		slug = bc.slugify(*title)
+	}
+
+	if !bc.isValidSlug(slug) {
+		return "", NewInputError("invalid slug")
+	}
+
+	mode, modeErr := getSlugMode()
+
+	if modeErr != nil {
+		return "", modeErr
+	}
+
+	if mode == SLUG_MODE_ERROR {
+		return slug, nil
+	}
+
+	for _, s := range slugCandidates(slug, mode, date) {
+		available, availableErr := (*bc.DBController).IsSlugAvailable(s, id)
+
+		if availableErr != nil {
+			return "", availableErr
+		}
+
+		if available {
+			return s, nil
+		}
+	}
+
+	return "", NewInputError("no unique slug found for " + slug)
+}
+
+// CheckSlug returns whether the slug can be given to the post with the id, or
+// to a new post if the id is empty. Slugs that can't be used come with
+// suggestions that can.
+func (bc *BlogController) CheckSlug(candidate string, id string) (*SlugAvailability, error) {
+	availability := SlugAvailability{
+		Slug:        candidate,
+		Valid:       bc.isValidSlug(candidate),
+		Suggestions: make([]string, 0),
+	}
+
+	if availability.Valid {
+		available, availableErr := (*bc.DBController).IsSlugAvailable(candidate, id)
+
+		if availableErr != nil {
+			return nil, availableErr
+		}
+
+		availability.Available = available
+	}
+
+	if availability.Available {
+		return &availability, nil
+	}
+
+	base := bc.slugify(candidate)
+
+	if !bc.isValidSlug(base) {
+		return &availability, nil
+	}
+
+	// Numbered slugs come first, since they're the shortest
+	candidates := slugCandidates(base, SLUG_MODE_NUMBER, time.Now())
+	candidates = append(candidates, slugCandidates(base, SLUG_MODE_DATE, time.Now())[1])
+
+	for _, s := range candidates {
+		if s == candidate {
+			continue
+		}
+
+		available, availableErr := (*bc.DBController).IsSlugAvailable(s, id)
+
+		if availableErr != nil {
+			return nil, availableErr
+		}
+
+		if available {
+			availability.Suggestions = append(availability.Suggestions, s)
+		}
+
+		if len(availability.Suggestions) == SLUG_SUGGESTIONS {
+			break
+		}
+	}
+
+	return &availability, nil
+}
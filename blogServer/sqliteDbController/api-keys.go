@@ -0,0 +1,185 @@
+package sqliteDbController
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+const apiKeySelect = `SELECT id, name, ownerId, prefix, hash, scopes, dateAdded, expiresAt, lastUsed, revokedAt FROM ` + API_KEY_TABLE
+
+// nullTime returns the time of a nullable Unix timestamp column
+func nullTime(value sql.NullInt64) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	t := time.Unix(value.Int64, 0)
+	return &t
+}
+
+func scanApiKey(row rowScanner) (*dbController.ApiKeyDocument, error) {
+	var key dbController.ApiKeyDocument
+	var scopesJson string
+	var dateAdded int64
+	var expiresAt, lastUsed, revokedAt sql.NullInt64
+
+	scanErr := row.Scan(
+		&key.Id,
+		&key.Name,
+		&key.OwnerId,
+		&key.Prefix,
+		&key.Hash,
+		&scopesJson,
+		&dateAdded,
+		&expiresAt,
+		&lastUsed,
+		&revokedAt,
+	)
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	if jsonErr := json.Unmarshal([]byte(scopesJson), &key.Scopes); jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	key.DateAdded = time.Unix(dateAdded, 0)
+	key.ExpiresAt = nullTime(expiresAt)
+	key.LastUsed = nullTime(lastUsed)
+	key.RevokedAt = nullTime(revokedAt)
+
+	return &key, nil
+}
+
+func (sdbc *SqliteDbController) AddApiKey(doc *dbController.AddApiKeyDocument) (string, error) {
+	scopes := doc.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	scopesJson, scopesErr := json.Marshal(scopes)
+
+	if scopesErr != nil {
+		return "", dbController.NewDBError(scopesErr.Error())
+	}
+
+	var expiresAt *int64
+	if doc.ExpiresAt != nil {
+		unix := doc.ExpiresAt.Unix()
+		expiresAt = &unix
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	id := primitive.NewObjectID().Hex()
+
+	_, insertErr := sdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+API_KEY_TABLE+` (id, name, ownerId, prefix, hash, scopes, dateAdded, expiresAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, doc.Name, doc.OwnerId, doc.Prefix, doc.Hash, string(scopesJson), doc.DateAdded.Unix(), expiresAt,
+	)
+
+	if insertErr != nil {
+		if isDuplicateError(insertErr) {
+			return "", dbController.NewDuplicateEntryError("Duplicate API key")
+		}
+
+		return "", dbController.NewDBError(insertErr.Error())
+	}
+
+	return id, nil
+}
+
+func (sdbc *SqliteDbController) GetApiKeyByHash(hash string) (*dbController.ApiKeyDocument, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	key, queryErr := scanApiKey(sdbc.DB.QueryRowContext(backCtx, apiKeySelect+` WHERE hash = ?`, hash))
+
+	if queryErr == sql.ErrNoRows {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return key, nil
+}
+
+func (sdbc *SqliteDbController) GetApiKeys() ([]*dbController.ApiKeyDocument, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := sdbc.DB.QueryContext(backCtx, apiKeySelect+` ORDER BY dateAdded DESC, id DESC`)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	keys := make([]*dbController.ApiKeyDocument, 0)
+
+	for rows.Next() {
+		key, scanErr := scanApiKey(rows)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		keys = append(keys, key)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return keys, nil
+}
+
+// updateApiKey runs an update of the API key with the id, which is the last
+// argument.
+func (sdbc *SqliteDbController) updateApiKey(statement string, args ...interface{}) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	result, updateErr := sdbc.DB.ExecContext(backCtx, statement, args...)
+
+	if updateErr != nil {
+		return dbController.NewDBError(updateErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count == 0 {
+		return dbController.NewInvalidInputError("id did not match any API keys")
+	}
+
+	return nil
+}
+
+func (sdbc *SqliteDbController) RevokeApiKey(id string, revokedAt time.Time) error {
+	return sdbc.updateApiKey(
+		`UPDATE `+API_KEY_TABLE+` SET revokedAt = COALESCE(revokedAt, ?) WHERE id = ?`,
+		revokedAt.Unix(), id,
+	)
+}
+
+func (sdbc *SqliteDbController) SetApiKeyLastUsed(id string, lastUsed time.Time) error {
+	return sdbc.updateApiKey(
+		`UPDATE `+API_KEY_TABLE+` SET lastUsed = ? WHERE id = ?`,
+		lastUsed.Unix(), id,
+	)
+}
@@ -0,0 +1,60 @@
+package sqliteDbController
+
+import (
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (sdbc *SqliteDbController) GetBlogArchive(includeUnpublished bool) ([]*dbController.ArchiveCount, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(includeUnpublished)
+
+	rows, queryErr := sdbc.DB.QueryContext(
+		backCtx,
+		`SELECT
+			CAST(strftime('%Y', p.dateAdded, 'unixepoch') AS INTEGER) AS year,
+			CAST(strftime('%m', p.dateAdded, 'unixepoch') AS INTEGER) AS month,
+			COUNT(*)
+		FROM `+BLOG_TABLE+` p
+		WHERE `+visibility+`
+		GROUP BY year, month
+		ORDER BY year DESC, month DESC`,
+		args...,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	archive := make([]*dbController.ArchiveCount, 0)
+
+	for rows.Next() {
+		var count dbController.ArchiveCount
+
+		if scanErr := rows.Scan(&count.Year, &count.Month, &count.Count); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		archive = append(archive, &count)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return archive, nil
+}
+
+func (sdbc *SqliteDbController) GetBlogPostsByDateRange(start time.Time, end time.Time, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	where, args := filterCondition(&dbController.BlogPostFilter{DateAddedStart: &start, DateAddedEnd: &end})
+
+	return sdbc.getBlogPostsWhere(page, pagination, includeUnpublished, where, args...)
+}
@@ -0,0 +1,82 @@
+package sqliteDbController
+
+import (
+	"database/sql"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (sdbc *SqliteDbController) GetBlogAuthor(uid string) (*dbController.BlogAuthor, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	var author dbController.BlogAuthor
+
+	queryErr := sdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT uid, name FROM `+USER_TABLE+` WHERE uid = ?`,
+		uid,
+	).Scan(&author.UID, &author.Name)
+
+	if queryErr == sql.ErrNoRows {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return &author, nil
+}
+
+func (sdbc *SqliteDbController) GetBlogPostsByAuthor(uid string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	where, args := filterCondition(&dbController.BlogPostFilter{AuthorId: uid})
+
+	return sdbc.getBlogPostsWhere(page, pagination, includeUnpublished, where, args...)
+}
+
+func (sdbc *SqliteDbController) GetBlogAuthors(includeUnpublished bool) ([]*dbController.BlogAuthor, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(includeUnpublished)
+
+	rows, queryErr := sdbc.DB.QueryContext(
+		backCtx,
+		`SELECT u.uid, u.name
+		FROM `+USER_TABLE+` u
+		WHERE EXISTS (
+			SELECT 1 FROM `+BLOG_TABLE+` p
+			WHERE p.authorId = u.uid AND `+visibility+`
+		)
+		ORDER BY u.uid ASC`,
+		args...,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	authors := make([]*dbController.BlogAuthor, 0)
+
+	for rows.Next() {
+		var author dbController.BlogAuthor
+
+		if scanErr := rows.Scan(&author.UID, &author.Name); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		authors = append(authors, &author)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return authors, nil
+}
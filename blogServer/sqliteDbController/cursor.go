@@ -0,0 +1,72 @@
+package sqliteDbController
+
+import (
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (sdbc *SqliteDbController) CountBlogPosts(filter *dbController.BlogPostFilter, includeUnpublished bool) (int, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(includeUnpublished)
+	where, whereArgs := filterCondition(filter)
+
+	var count int
+
+	queryErr := sdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM `+BLOG_TABLE+` p WHERE `+visibility+` AND `+where,
+		append(args, whereArgs...)...,
+	).Scan(&count)
+
+	if queryErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return count, nil
+}
+
+func (sdbc *SqliteDbController) GetBlogPostsByCursor(cursor *dbController.BlogCursor, limit int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if limit < 1 {
+		return nil, dbController.NewDBError("invalid limit")
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	where, args := visibilityCondition(includeUnpublished)
+	order := `p.dateAdded DESC, p.id ASC`
+
+	if cursor != nil {
+		if !isValidId(cursor.Id) {
+			return nil, dbController.NewInvalidInputError("invalid cursor")
+		}
+
+		// The posts before the cursor are read in reverse order, so that the
+		// limit keeps the posts closest to the cursor.
+		if cursor.Previous {
+			where += ` AND (p.dateAdded > ? OR (p.dateAdded = ? AND p.id < ?))`
+			order = `p.dateAdded ASC, p.id DESC`
+		} else {
+			where += ` AND (p.dateAdded < ? OR (p.dateAdded = ? AND p.id > ?))`
+		}
+
+		dateAdded := cursor.DateAdded.Unix()
+		args = append(args, dateAdded, dateAdded, cursor.Id)
+	}
+
+	query := blogPostSelect + ` WHERE ` + where + ` ORDER BY ` + order + ` LIMIT ?`
+	args = append(args, limit)
+
+	posts, postsErr := queryBlogPosts(backCtx, sdbc.DB, query, args...)
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	if cursor != nil && cursor.Previous {
+		dbController.ReverseBlogDocuments(posts)
+	}
+
+	return posts, nil
+}
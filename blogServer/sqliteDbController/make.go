@@ -0,0 +1,58 @@
+package sqliteDbController
+
+import (
+	"database/sql"
+	"os"
+
+	// The pure Go driver doesn't need cgo, so the Linux cross builds and the
+	// alpine image can use SQLite too.
+	_ "modernc.org/sqlite"
+
+	"methompson.com/blog-microservice/blogServer/constants"
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func checkEnvVariables() error {
+	sqliteDbPath := os.Getenv(constants.SQLITE_DB_PATH)
+	if len(sqliteDbPath) == 0 {
+		msg := "SQLITE_DB_PATH environment variable is required"
+		return NewEnvironmentVariableError(msg)
+	}
+
+	return nil
+}
+
+// The MakeSqliteDbController opens the SQLite database file found at the
+// SQLITE_DB_PATH environment variable.
+func MakeSqliteDbController() (*SqliteDbController, error) {
+	envErr := checkEnvVariables()
+
+	if envErr != nil {
+		return nil, envErr
+	}
+
+	return OpenSqliteDbController(os.Getenv(constants.SQLITE_DB_PATH))
+}
+
+// OpenSqliteDbController opens the SQLite database at dbPath and wraps it up in
+// a SqliteDbController object. The file is created if it doesn't exist. Passing
+// ":memory:" creates a database that only lives as long as the controller.
+func OpenSqliteDbController(dbPath string) (*SqliteDbController, error) {
+	db, openErr := sql.Open("sqlite", "file:"+dbPath+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+
+	if openErr != nil {
+		return nil, dbController.NewDBError("Error opening database: " + openErr.Error())
+	}
+
+	// SQLite only allows one writer at a time. A single connection avoids
+	// locking errors and keeps in-memory databases from being split across
+	// several connections.
+	db.SetMaxOpenConns(1)
+
+	if pingErr := db.Ping(); pingErr != nil {
+		db.Close()
+		return nil, dbController.NewDBError("Error opening database: " + pingErr.Error())
+	}
+
+	return &SqliteDbController{db}, nil
+}
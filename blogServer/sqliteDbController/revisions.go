@@ -0,0 +1,192 @@
+package sqliteDbController
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// The tags and changed fields of a revision are stored as JSON arrays, since
+// revisions are only ever read as a whole.
+const blogRevisionSelect = `SELECT
+		postId,
+		revision,
+		title,
+		slug,
+		body,
+		tags,
+		authorId,
+		dateAdded,
+		updateAuthorId,
+		dateUpdated,
+		status,
+		publishAt,
+		changedFields
+	FROM ` + BLOG_REVISIONS_TABLE
+
+func insertRevision(backCtx context.Context, tx *sql.Tx, rev *dbController.BlogRevision) error {
+	tags := rev.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	tagsJson, tagsErr := json.Marshal(tags)
+
+	if tagsErr != nil {
+		return tagsErr
+	}
+
+	changedJson, changedErr := json.Marshal(rev.ChangedFields)
+
+	if changedErr != nil {
+		return changedErr
+	}
+
+	_, insertErr := tx.ExecContext(
+		backCtx,
+		`INSERT INTO `+BLOG_REVISIONS_TABLE+` (postId, revision, title, slug, body, tags, authorId, dateAdded, updateAuthorId, dateUpdated, status, publishAt, changedFields)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rev.PostId,
+		rev.Revision,
+		rev.Title,
+		rev.Slug,
+		rev.Body,
+		string(tagsJson),
+		rev.AuthorId,
+		rev.DateAdded.Unix(),
+		rev.UpdateAuthorId,
+		rev.DateUpdated.Unix(),
+		rev.Status,
+		rev.PublishAt.Unix(),
+		string(changedJson),
+	)
+
+	return insertErr
+}
+
+// saveRevision saves the current state of the blog post as its next revision.
+// previous is the state of the post before an edit, or nil for a new post.
+// Posts added before revisions were saved get their previous state saved as
+// the first revision.
+func saveRevision(backCtx context.Context, tx *sql.Tx, postId string, previous *dbController.BlogDocument) error {
+	var latest int
+
+	latestErr := tx.QueryRowContext(
+		backCtx,
+		`SELECT COALESCE(MAX(revision), 0) FROM `+BLOG_REVISIONS_TABLE+` WHERE postId = ?`,
+		postId,
+	).Scan(&latest)
+
+	if latestErr != nil {
+		return latestErr
+	}
+
+	if latest == 0 && previous != nil {
+		if insertErr := insertRevision(backCtx, tx, dbController.NewBlogRevision(previous, 1, nil)); insertErr != nil {
+			return insertErr
+		}
+
+		latest = 1
+	}
+
+	posts, postsErr := queryBlogPosts(backCtx, tx, blogPostSelect+` WHERE p.id = ?`, postId)
+
+	if postsErr != nil {
+		return postsErr
+	}
+
+	if len(posts) == 0 {
+		return dbController.NewNoResultsError("")
+	}
+
+	return insertRevision(backCtx, tx, dbController.NewBlogRevision(posts[0], latest+1, previous))
+}
+
+func (sdbc *SqliteDbController) queryRevisions(query string, args ...interface{}) ([]*dbController.BlogRevision, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := sdbc.DB.QueryContext(backCtx, query, args...)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	revisions := make([]*dbController.BlogRevision, 0)
+
+	for rows.Next() {
+		var rev dbController.BlogRevision
+		var tagsJson, changedJson string
+		var dateAdded, dateUpdated, publishAt int64
+
+		scanErr := rows.Scan(
+			&rev.PostId,
+			&rev.Revision,
+			&rev.Title,
+			&rev.Slug,
+			&rev.Body,
+			&tagsJson,
+			&rev.AuthorId,
+			&dateAdded,
+			&rev.UpdateAuthorId,
+			&dateUpdated,
+			&rev.Status,
+			&publishAt,
+			&changedJson,
+		)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		if jsonErr := json.Unmarshal([]byte(tagsJson), &rev.Tags); jsonErr != nil {
+			return nil, dbController.NewDBError("error parsing tags: " + jsonErr.Error())
+		}
+
+		if jsonErr := json.Unmarshal([]byte(changedJson), &rev.ChangedFields); jsonErr != nil {
+			return nil, dbController.NewDBError("error parsing changed fields: " + jsonErr.Error())
+		}
+
+		rev.DateAdded = time.Unix(dateAdded, 0)
+		rev.DateUpdated = time.Unix(dateUpdated, 0)
+		rev.PublishAt = time.Unix(publishAt, 0)
+
+		revisions = append(revisions, &rev)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return revisions, nil
+}
+
+func (sdbc *SqliteDbController) GetBlogPostRevisions(postId string) ([]*dbController.BlogRevision, error) {
+	if !isValidId(postId) {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	return sdbc.queryRevisions(blogRevisionSelect+` WHERE postId = ? ORDER BY revision DESC`, postId)
+}
+
+func (sdbc *SqliteDbController) GetBlogPostRevision(postId string, revision int) (*dbController.BlogRevision, error) {
+	if !isValidId(postId) {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	revisions, revisionsErr := sdbc.queryRevisions(blogRevisionSelect+` WHERE postId = ? AND revision = ?`, postId, revision)
+
+	if revisionsErr != nil {
+		return nil, revisionsErr
+	}
+
+	if len(revisions) < 1 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return revisions[0], nil
+}
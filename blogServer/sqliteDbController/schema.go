@@ -0,0 +1,235 @@
+package sqliteDbController
+
+import (
+	"context"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// The schema mirrors the $jsonSchema validators and indexes that the
+// MongoDbController creates. Dates are stored as Unix timestamps in seconds.
+var tableStatements = []string{
+	`CREATE TABLE IF NOT EXISTS ` + BLOG_TABLE + ` (
+		id             TEXT    NOT NULL PRIMARY KEY,
+		title          TEXT    NOT NULL CHECK (typeof(title) = 'text'),
+		slug           TEXT    NOT NULL CHECK (typeof(slug) = 'text'),
+		body           TEXT             CHECK (body IS NULL OR typeof(body) = 'text'),
+		bodyHtml       TEXT    NOT NULL CHECK (typeof(bodyHtml) = 'text'),
+		excerpt        TEXT    NOT NULL CHECK (typeof(excerpt) = 'text'),
+		customExcerpt  INTEGER NOT NULL CHECK (customExcerpt IN (0, 1)),
+		wordCount      INTEGER NOT NULL CHECK (typeof(wordCount) = 'integer'),
+		readingTime    INTEGER NOT NULL CHECK (typeof(readingTime) = 'integer'),
+		authorId       TEXT    NOT NULL CHECK (typeof(authorId) = 'text'),
+		dateAdded      INTEGER NOT NULL CHECK (typeof(dateAdded) = 'integer'),
+		updateAuthorId TEXT    NOT NULL CHECK (typeof(updateAuthorId) = 'text'),
+		dateUpdated    INTEGER NOT NULL CHECK (typeof(dateUpdated) = 'integer'),
+		status         TEXT    NOT NULL CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+		publishAt      INTEGER NOT NULL CHECK (typeof(publishAt) = 'integer'),
+		deletedAt      INTEGER          CHECK (deletedAt IS NULL OR typeof(deletedAt) = 'integer'),
+		deletedBy      TEXT             CHECK (deletedBy IS NULL OR typeof(deletedBy) = 'text')
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + BLOG_TAGS_TABLE + ` (
+		postId   TEXT    NOT NULL REFERENCES ` + BLOG_TABLE + ` (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		tag      TEXT    NOT NULL CHECK (typeof(tag) = 'text'),
+		PRIMARY KEY (postId, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + BLOG_REVISIONS_TABLE + ` (
+		postId         TEXT    NOT NULL REFERENCES ` + BLOG_TABLE + ` (id) ON DELETE CASCADE,
+		revision       INTEGER NOT NULL CHECK (typeof(revision) = 'integer'),
+		title          TEXT    NOT NULL CHECK (typeof(title) = 'text'),
+		slug           TEXT    NOT NULL CHECK (typeof(slug) = 'text'),
+		body           TEXT    NOT NULL CHECK (typeof(body) = 'text'),
+		tags           TEXT    NOT NULL CHECK (json_valid(tags)),
+		authorId       TEXT    NOT NULL CHECK (typeof(authorId) = 'text'),
+		dateAdded      INTEGER NOT NULL CHECK (typeof(dateAdded) = 'integer'),
+		updateAuthorId TEXT    NOT NULL CHECK (typeof(updateAuthorId) = 'text'),
+		dateUpdated    INTEGER NOT NULL CHECK (typeof(dateUpdated) = 'integer'),
+		status         TEXT    NOT NULL CHECK (typeof(status) = 'text'),
+		publishAt      INTEGER NOT NULL CHECK (typeof(publishAt) = 'integer'),
+		changedFields  TEXT    NOT NULL CHECK (json_valid(changedFields)),
+		PRIMARY KEY (postId, revision)
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + BLOG_SLUGS_TABLE + ` (
+		slug        TEXT    NOT NULL PRIMARY KEY CHECK (typeof(slug) = 'text'),
+		postId      TEXT    NOT NULL REFERENCES ` + BLOG_TABLE + ` (id) ON DELETE CASCADE,
+		dateChanged INTEGER NOT NULL CHECK (typeof(dateChanged) = 'integer')
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + USER_TABLE + ` (
+		uid    TEXT    NOT NULL CHECK (typeof(uid) = 'text'),
+		name   TEXT    NOT NULL CHECK (typeof(name) = 'text'),
+		email  TEXT    NOT NULL CHECK (typeof(email) = 'text'),
+		active INTEGER NOT NULL CHECK (active IN (0, 1)),
+		role   TEXT    NOT NULL CHECK (typeof(role) = 'text')
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + API_KEY_TABLE + ` (
+		id        TEXT    NOT NULL PRIMARY KEY,
+		name      TEXT    NOT NULL CHECK (typeof(name) = 'text'),
+		ownerId   TEXT    NOT NULL CHECK (typeof(ownerId) = 'text'),
+		prefix    TEXT    NOT NULL CHECK (typeof(prefix) = 'text'),
+		hash      TEXT    NOT NULL CHECK (typeof(hash) = 'text'),
+		scopes    TEXT    NOT NULL CHECK (json_valid(scopes)),
+		dateAdded INTEGER NOT NULL CHECK (typeof(dateAdded) = 'integer'),
+		expiresAt INTEGER          CHECK (expiresAt IS NULL OR typeof(expiresAt) = 'integer'),
+		lastUsed  INTEGER          CHECK (lastUsed IS NULL OR typeof(lastUsed) = 'integer'),
+		revokedAt INTEGER          CHECK (revokedAt IS NULL OR typeof(revokedAt) = 'integer')
+	)`,
+	`CREATE TABLE IF NOT EXISTS ` + LOGGING_TABLE + ` (
+		id           INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		timestamp    INTEGER NOT NULL CHECK (typeof(timestamp) = 'integer'),
+		type         TEXT    NOT NULL CHECK (typeof(type) = 'text'),
+		clientIP     TEXT,
+		method       TEXT,
+		path         TEXT,
+		protocol     TEXT,
+		statusCode   INTEGER,
+		latency      INTEGER,
+		userAgent    TEXT,
+		errorMessage TEXT,
+		message      TEXT
+	)`,
+}
+
+// A columnUpgrade adds a column to a table that was created before the column
+// was part of tableStatements. The Backfill statement, if any, runs right after
+// the column is added. SQLite only allows NOT NULL columns to be added with a
+// default value, so the Definition may differ from the one in tableStatements.
+type columnUpgrade struct {
+	Table      string
+	Column     string
+	Definition string
+	Backfill   string
+}
+
+var columnUpgrades = []columnUpgrade{
+	{
+		Table:      BLOG_TABLE,
+		Column:     "status",
+		Definition: `TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('draft', 'scheduled', 'published', 'archived'))`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "publishAt",
+		Definition: `INTEGER NOT NULL DEFAULT 0 CHECK (typeof(publishAt) = 'integer')`,
+		Backfill:   `UPDATE ` + BLOG_TABLE + ` SET publishAt = dateAdded`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "deletedAt",
+		Definition: `INTEGER CHECK (deletedAt IS NULL OR typeof(deletedAt) = 'integer')`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "deletedBy",
+		Definition: `TEXT CHECK (deletedBy IS NULL OR typeof(deletedBy) = 'text')`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "bodyHtml",
+		Definition: `TEXT NOT NULL DEFAULT '' CHECK (typeof(bodyHtml) = 'text')`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "excerpt",
+		Definition: `TEXT NOT NULL DEFAULT '' CHECK (typeof(excerpt) = 'text')`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "customExcerpt",
+		Definition: `INTEGER NOT NULL DEFAULT 0 CHECK (customExcerpt IN (0, 1))`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "wordCount",
+		Definition: `INTEGER NOT NULL DEFAULT 0 CHECK (typeof(wordCount) = 'integer')`,
+	},
+	{
+		Table:      BLOG_TABLE,
+		Column:     "readingTime",
+		Definition: `INTEGER NOT NULL DEFAULT 0 CHECK (typeof(readingTime) = 'integer')`,
+	},
+}
+
+// The indexes are created after the column upgrades, since they may use the
+// upgraded columns.
+var indexStatements = []string{
+	`CREATE UNIQUE INDEX IF NOT EXISTS blogPosts_slug ON ` + BLOG_TABLE + ` (slug)`,
+	`CREATE INDEX IF NOT EXISTS blogPosts_status_publishAt ON ` + BLOG_TABLE + ` (status, publishAt)`,
+	`CREATE INDEX IF NOT EXISTS blogPosts_dateAdded_id ON ` + BLOG_TABLE + ` (dateAdded, id)`,
+	`CREATE INDEX IF NOT EXISTS blogPosts_deletedAt ON ` + BLOG_TABLE + ` (deletedAt)`,
+	`CREATE INDEX IF NOT EXISTS blogPosts_authorId ON ` + BLOG_TABLE + ` (authorId, dateAdded)`,
+	`CREATE INDEX IF NOT EXISTS blogPostTags_tag ON ` + BLOG_TAGS_TABLE + ` (tag, postId)`,
+	`CREATE INDEX IF NOT EXISTS blogPostSlugs_postId ON ` + BLOG_SLUGS_TABLE + ` (postId)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS users_uid ON ` + USER_TABLE + ` (uid)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS users_email ON ` + USER_TABLE + ` (email)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS apiKeys_hash ON ` + API_KEY_TABLE + ` (hash)`,
+}
+
+// hasColumn returns whether the table already has the column
+func (sdbc *SqliteDbController) hasColumn(backCtx context.Context, table string, column string) (bool, error) {
+	var count int
+
+	queryErr := sdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`,
+		table, column,
+	).Scan(&count)
+
+	return count > 0, queryErr
+}
+
+func (sdbc *SqliteDbController) upgradeColumn(backCtx context.Context, upgrade columnUpgrade) error {
+	exists, existsErr := sdbc.hasColumn(backCtx, upgrade.Table, upgrade.Column)
+
+	if existsErr != nil || exists {
+		return existsErr
+	}
+
+	tx, txErr := sdbc.DB.BeginTx(backCtx, nil)
+
+	if txErr != nil {
+		return txErr
+	}
+	defer tx.Rollback()
+
+	_, alterErr := tx.ExecContext(backCtx, `ALTER TABLE `+upgrade.Table+` ADD COLUMN `+upgrade.Column+` `+upgrade.Definition)
+
+	if alterErr != nil {
+		return alterErr
+	}
+
+	if len(upgrade.Backfill) > 0 {
+		if _, backfillErr := tx.ExecContext(backCtx, upgrade.Backfill); backfillErr != nil {
+			return backfillErr
+		}
+	}
+
+	return tx.Commit()
+}
+
+// InitDatabase creates any missing tables, adds any columns that are missing
+// from tables made by an older version of the schema, then creates the indexes.
+func (sdbc *SqliteDbController) InitDatabase() error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	for _, statement := range tableStatements {
+		if _, execErr := sdbc.DB.ExecContext(backCtx, statement); execErr != nil {
+			return dbController.NewDBError(execErr.Error())
+		}
+	}
+
+	for _, upgrade := range columnUpgrades {
+		if upgradeErr := sdbc.upgradeColumn(backCtx, upgrade); upgradeErr != nil {
+			return dbController.NewDBError("error adding column " + upgrade.Column + ": " + upgradeErr.Error())
+		}
+	}
+
+	for _, statement := range indexStatements {
+		if _, execErr := sdbc.DB.ExecContext(backCtx, statement); execErr != nil {
+			return dbController.NewDBError(execErr.Error())
+		}
+	}
+
+	return nil
+}
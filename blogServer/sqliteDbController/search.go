@@ -0,0 +1,89 @@
+package sqliteDbController
+
+import (
+	"strings"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// findSearchResults finds the candidate posts with LIKE and scores them with
+// dbController.ScoreBlogPost. The results are in no particular order. A full
+// table scan is fine for the size of blog the SQLite backend is meant for.
+func (sdbc *SqliteDbController) findSearchResults(terms []string, includeUnpublished bool) ([]*dbController.SearchResult, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(includeUnpublished)
+
+	// Search terms only contain letters and numbers, so they never contain
+	// LIKE wildcards. LIKE only ignores the case of ASCII letters.
+	matches := make([]string, 0)
+	for _, term := range terms {
+		pattern := "%" + term + "%"
+
+		matches = append(matches, `p.title LIKE ? OR p.body LIKE ? OR EXISTS (
+			SELECT 1 FROM `+BLOG_TAGS_TABLE+` t WHERE t.postId = p.id AND t.tag LIKE ?
+		)`)
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	statement := blogPostSelect + ` WHERE ` + visibility + ` AND (` + strings.Join(matches, ` OR `) + `) ORDER BY p.dateAdded DESC, p.rowid ASC`
+
+	posts, queryErr := queryBlogPosts(backCtx, sdbc.DB, statement, args...)
+
+	if queryErr != nil {
+		return nil, queryErr
+	}
+
+	results := make([]*dbController.SearchResult, 0)
+	for _, post := range posts {
+		score := dbController.ScoreBlogPost(post, terms)
+
+		if score > 0 {
+			results = append(results, &dbController.SearchResult{Post: post, Score: score})
+		}
+	}
+
+	return results, nil
+}
+
+func (sdbc *SqliteDbController) SearchBlogPosts(query string, page int, pagination int, includeUnpublished bool) ([]*dbController.SearchResult, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	results, findErr := sdbc.findSearchResults(terms, includeUnpublished)
+
+	if findErr != nil {
+		return nil, findErr
+	}
+
+	dbController.SortSearchResults(results)
+	results = dbController.PageSearchResults(results, page, pagination)
+
+	for _, result := range results {
+		result.Snippet = dbController.MakeSearchSnippet(result.Post.Body, terms)
+	}
+
+	return results, nil
+}
+
+func (sdbc *SqliteDbController) CountSearchResults(query string, includeUnpublished bool) (int, error) {
+	terms := dbController.SearchTerms(query)
+	if len(terms) == 0 {
+		return 0, dbController.NewInvalidInputError("search query has no words")
+	}
+
+	results, findErr := sdbc.findSearchResults(terms, includeUnpublished)
+
+	if findErr != nil {
+		return 0, findErr
+	}
+
+	return len(results), nil
+}
@@ -0,0 +1,56 @@
+package sqliteDbController
+
+import (
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (sdbc *SqliteDbController) GetSitemapEntries(page int, pagination int) ([]*dbController.SitemapEntry, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(false)
+	args = append(args, pagination, (page-1)*pagination)
+
+	rows, queryErr := sdbc.DB.QueryContext(
+		backCtx,
+		`SELECT p.slug, p.dateAdded, p.dateUpdated
+		FROM `+BLOG_TABLE+` p
+		WHERE `+visibility+`
+		ORDER BY p.dateAdded DESC, p.rowid ASC
+		LIMIT ? OFFSET ?`,
+		args...,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	entries := make([]*dbController.SitemapEntry, 0)
+
+	for rows.Next() {
+		var entry dbController.SitemapEntry
+		var dateAdded, dateUpdated int64
+
+		if scanErr := rows.Scan(&entry.Slug, &dateAdded, &dateUpdated); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		entry.DateAdded = time.Unix(dateAdded, 0)
+		entry.DateUpdated = time.Unix(dateUpdated, 0)
+
+		entries = append(entries, &entry)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,85 @@
+package sqliteDbController
+
+import (
+	"context"
+	"database/sql"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+// checkOldSlug returns a DuplicateEntryError if a post other than postId used
+// to have the slug. New posts pass an empty postId.
+func checkOldSlug(backCtx context.Context, tx *sql.Tx, slug string, postId string) error {
+	var count int
+
+	queryErr := tx.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM `+BLOG_SLUGS_TABLE+` WHERE slug = ? AND postId != ?`,
+		slug, postId,
+	).Scan(&count)
+
+	if queryErr != nil {
+		return dbController.NewDBError(queryErr.Error())
+	}
+
+	if count > 0 {
+		msg := "Duplicate blog post. Slug '" + slug + "' was used by another blog post."
+		return dbController.NewDuplicateEntryError(msg)
+	}
+
+	return nil
+}
+
+// saveOldSlug keeps the slug a post had before an edit changed it. A post
+// going back to one of its old slugs doesn't keep it as an old slug.
+func saveOldSlug(backCtx context.Context, tx *sql.Tx, postId string, oldSlug string, newSlug string, dateChanged int64) error {
+	_, deleteErr := tx.ExecContext(
+		backCtx,
+		`DELETE FROM `+BLOG_SLUGS_TABLE+` WHERE slug = ? AND postId = ?`,
+		newSlug, postId,
+	)
+
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	_, insertErr := tx.ExecContext(
+		backCtx,
+		`INSERT OR REPLACE INTO `+BLOG_SLUGS_TABLE+` (slug, postId, dateChanged) VALUES (?, ?, ?)`,
+		oldSlug, postId, dateChanged,
+	)
+
+	return insertErr
+}
+
+func (sdbc *SqliteDbController) GetBlogPostByOldSlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return sdbc.getBlogPostWhere(
+		includeUnpublished,
+		`p.id = (SELECT postId FROM `+BLOG_SLUGS_TABLE+` WHERE slug = ?)`,
+		slug,
+	)
+}
+
+func (sdbc *SqliteDbController) IsSlugAvailable(slug string, id string) (bool, error) {
+	if len(id) > 0 && !isValidId(id) {
+		return false, dbController.NewInvalidInputError("invalid id")
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	var taken bool
+
+	queryErr := sdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT EXISTS (SELECT 1 FROM `+BLOG_TABLE+` WHERE slug = ? AND id != ?)
+			OR EXISTS (SELECT 1 FROM `+BLOG_SLUGS_TABLE+` WHERE slug = ? AND postId != ?)`,
+		slug, id, slug, id,
+	).Scan(&taken)
+
+	if queryErr != nil {
+		return false, dbController.NewDBError(queryErr.Error())
+	}
+
+	return !taken, nil
+}
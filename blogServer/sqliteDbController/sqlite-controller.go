@@ -0,0 +1,668 @@
+package sqliteDbController
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/logging"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+const BLOG_TABLE = "blogPosts"
+const BLOG_TAGS_TABLE = "blogPostTags"
+const BLOG_REVISIONS_TABLE = "blogRevisions"
+const BLOG_SLUGS_TABLE = "blogPostSlugs"
+const LOGGING_TABLE = "logging"
+const USER_TABLE = "users"
+const API_KEY_TABLE = "apiKeys"
+
+// MAX_LOG_ROWS keeps the logging table from growing forever, in the same way
+// the MongoDbController uses a capped collection.
+const MAX_LOG_ROWS = 10000
+
+// blogPostSelect joins the users table twice to get the names of the author
+// and the update author, the same way GetAggregationStages does with $lookup.
+const blogPostSelect = `SELECT
+		p.id,
+		p.title,
+		p.slug,
+		COALESCE(p.body, ''),
+		p.bodyHtml,
+		p.excerpt,
+		p.customExcerpt,
+		p.wordCount,
+		p.readingTime,
+		p.authorId,
+		p.dateAdded,
+		p.updateAuthorId,
+		p.dateUpdated,
+		p.status,
+		p.publishAt,
+		p.deletedAt,
+		COALESCE(p.deletedBy, ''),
+		COALESCE(a.name, ''),
+		COALESCE(u.name, '')
+	FROM ` + BLOG_TABLE + ` p
+	LEFT JOIN ` + USER_TABLE + ` a ON a.uid = p.authorId
+	LEFT JOIN ` + USER_TABLE + ` u ON u.uid = p.updateAuthorId`
+
+type SqliteDbController struct {
+	DB *sql.DB
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// getContext is a convenience function that returns a context with the same
+// timeout the MongoDbController uses for its operations.
+func (sdbc *SqliteDbController) getContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 5*time.Second)
+}
+
+func isDuplicateError(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func isValidId(id string) bool {
+	_, idErr := primitive.ObjectIDFromHex(id)
+	return idErr == nil
+}
+
+// visibilityCondition returns a WHERE condition and its arguments that limit
+// blog posts to the ones the public can see. Posts in the trash are always
+// left out.
+func visibilityCondition(includeUnpublished bool) (string, []interface{}) {
+	if includeUnpublished {
+		return `p.deletedAt IS NULL`, []interface{}{}
+	}
+
+	condition := `p.deletedAt IS NULL AND p.status IN (?, ?) AND p.publishAt <= ?`
+	args := []interface{}{
+		dbController.BLOG_STATUS_PUBLISHED,
+		dbController.BLOG_STATUS_SCHEDULED,
+		time.Now().Unix(),
+	}
+
+	return condition, args
+}
+
+// filterCondition returns a WHERE condition and its arguments that limit blog
+// posts to the ones that match the filter.
+func filterCondition(filter *dbController.BlogPostFilter) (string, []interface{}) {
+	conditions := []string{`1 = 1`}
+	args := []interface{}{}
+
+	if filter == nil {
+		return conditions[0], args
+	}
+
+	if len(filter.Tag) > 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM `+BLOG_TAGS_TABLE+` t WHERE t.postId = p.id AND t.tag = ?)`)
+		args = append(args, filter.Tag)
+	}
+
+	if len(filter.AuthorId) > 0 {
+		conditions = append(conditions, `p.authorId = ?`)
+		args = append(args, filter.AuthorId)
+	}
+
+	if filter.DateAddedStart != nil {
+		conditions = append(conditions, `p.dateAdded >= ?`)
+		args = append(args, filter.DateAddedStart.Unix())
+	}
+
+	if filter.DateAddedEnd != nil {
+		conditions = append(conditions, `p.dateAdded < ?`)
+		args = append(args, filter.DateAddedEnd.Unix())
+	}
+
+	return strings.Join(conditions, ` AND `), args
+}
+
+// insertTags writes the tags of a blog post in order
+func insertTags(backCtx context.Context, tx *sql.Tx, postId string, tags []string) error {
+	for i, tag := range tags {
+		_, insertErr := tx.ExecContext(
+			backCtx,
+			`INSERT INTO `+BLOG_TAGS_TABLE+` (postId, position, tag) VALUES (?, ?, ?)`,
+			postId, i, tag,
+		)
+
+		if insertErr != nil {
+			return insertErr
+		}
+	}
+
+	return nil
+}
+
+// loadTags fills in the Tags of every post with a single query
+func loadTags(backCtx context.Context, q queryer, posts []*dbController.BlogDocument) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postsById := make(map[string]*dbController.BlogDocument)
+	placeholders := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	for _, post := range posts {
+		postsById[post.Id] = post
+		placeholders = append(placeholders, "?")
+		args = append(args, post.Id)
+	}
+
+	rows, queryErr := q.QueryContext(
+		backCtx,
+		`SELECT postId, tag FROM `+BLOG_TAGS_TABLE+`
+		WHERE postId IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY postId, position`,
+		args...,
+	)
+
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postId, tag string
+
+		if scanErr := rows.Scan(&postId, &tag); scanErr != nil {
+			return scanErr
+		}
+
+		post := postsById[postId]
+		post.Tags = append(post.Tags, tag)
+	}
+
+	return rows.Err()
+}
+
+// queryBlogPosts runs a query built on blogPostSelect and returns the posts
+// with their tags. Within a transaction, q must be the *sql.Tx, since the
+// database only has a single connection.
+func queryBlogPosts(backCtx context.Context, q queryer, query string, args ...interface{}) ([]*dbController.BlogDocument, error) {
+	rows, queryErr := q.QueryContext(backCtx, query, args...)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	var posts []*dbController.BlogDocument = []*dbController.BlogDocument{}
+
+	for rows.Next() {
+		var post dbController.BlogDocument
+		var dateAdded, dateUpdated, publishAt int64
+		var deletedAt sql.NullInt64
+
+		scanErr := rows.Scan(
+			&post.Id,
+			&post.Title,
+			&post.Slug,
+			&post.Body,
+			&post.BodyHtml,
+			&post.Summary.Excerpt,
+			&post.Summary.CustomExcerpt,
+			&post.Summary.WordCount,
+			&post.Summary.ReadingTimeMinutes,
+			&post.AuthorId,
+			&dateAdded,
+			&post.UpdateAuthorId,
+			&dateUpdated,
+			&post.Status,
+			&publishAt,
+			&deletedAt,
+			&post.DeletedBy,
+			&post.Author,
+			&post.UpdateAuthor,
+		)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		post.DateAdded = time.Unix(dateAdded, 0)
+		post.DateUpdated = time.Unix(dateUpdated, 0)
+		post.PublishAt = time.Unix(publishAt, 0)
+
+		if deletedAt.Valid {
+			t := time.Unix(deletedAt.Int64, 0)
+			post.DeletedAt = &t
+		}
+
+		posts = append(posts, &post)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	// The rows need to be closed before the tags can be read, since the
+	// database only has a single connection.
+	rows.Close()
+
+	if tagsErr := loadTags(backCtx, q, posts); tagsErr != nil {
+		return nil, dbController.NewDBError("error getting tags: " + tagsErr.Error())
+	}
+
+	return posts, nil
+}
+
+func (sdbc *SqliteDbController) getBlogPostWhere(includeUnpublished bool, where string, args ...interface{}) (*dbController.BlogDocument, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, visibilityArgs := visibilityCondition(includeUnpublished)
+	query := blogPostSelect + ` WHERE ` + where + ` AND ` + visibility + ` LIMIT 1`
+
+	posts, postsErr := queryBlogPosts(backCtx, sdbc.DB, query, append(args, visibilityArgs...)...)
+
+	if postsErr != nil {
+		return nil, postsErr
+	}
+
+	if len(posts) < 1 {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	return posts[0], nil
+}
+
+func (sdbc *SqliteDbController) AddBlogPost(doc *dbController.AddBlogDocument) (string, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	id := primitive.NewObjectID().Hex()
+	dateAdded := doc.DateAdded.Unix()
+
+	updateAuthorId := doc.AuthorId
+	if doc.UpdateAuthorId != nil {
+		updateAuthorId = *doc.UpdateAuthorId
+	}
+
+	dateUpdated := dateAdded
+	if doc.DateUpdated != nil {
+		dateUpdated = (*doc.DateUpdated).Unix()
+	}
+
+	status := dbController.BLOG_STATUS_PUBLISHED
+	if doc.Status != nil {
+		status = *doc.Status
+	}
+
+	publishAt := dateAdded
+	if doc.PublishAt != nil {
+		publishAt = (*doc.PublishAt).Unix()
+	}
+
+	tx, txErr := sdbc.DB.BeginTx(backCtx, nil)
+
+	if txErr != nil {
+		return "", dbController.NewDBError(txErr.Error())
+	}
+	defer tx.Rollback()
+
+	if slugErr := checkOldSlug(backCtx, tx, doc.Slug, ""); slugErr != nil {
+		return "", slugErr
+	}
+
+	_, insertErr := tx.ExecContext(
+		backCtx,
+		`INSERT INTO `+BLOG_TABLE+` (id, title, slug, body, bodyHtml, excerpt, customExcerpt, wordCount, readingTime, authorId, dateAdded, updateAuthorId, dateUpdated, status, publishAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, doc.Title, doc.Slug, doc.Body, doc.BodyHtml,
+		doc.Summary.Excerpt, doc.Summary.CustomExcerpt, doc.Summary.WordCount, doc.Summary.ReadingTimeMinutes,
+		doc.AuthorId, dateAdded, updateAuthorId, dateUpdated, status, publishAt,
+	)
+
+	if insertErr != nil {
+		err := insertErr.Error()
+		print("Add blog error. Error: " + err + "\n")
+
+		if isDuplicateError(insertErr) {
+			msg := "Duplicate blog post."
+			if strings.Contains(err, "slug") {
+				msg = msg + " Blog Post with slug '" + doc.Slug + "' already exists."
+			}
+
+			return "", dbController.NewDuplicateEntryError(msg)
+		}
+
+		return "", dbController.NewDBError(err)
+	}
+
+	if doc.Tags != nil {
+		if tagsErr := insertTags(backCtx, tx, id, *doc.Tags); tagsErr != nil {
+			return "", dbController.NewDBError(tagsErr.Error())
+		}
+	}
+
+	if revisionErr := saveRevision(backCtx, tx, id, nil); revisionErr != nil {
+		return "", dbController.NewDBError(revisionErr.Error())
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return "", dbController.NewDBError(commitErr.Error())
+	}
+
+	return id, nil
+}
+
+func (sdbc *SqliteDbController) GetBlogPostById(id string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	if !isValidId(id) {
+		return nil, dbController.NewInvalidInputError("invalid id")
+	}
+
+	return sdbc.getBlogPostWhere(includeUnpublished, `p.id = ?`, id)
+}
+
+func (sdbc *SqliteDbController) GetBlogPostBySlug(slug string, includeUnpublished bool) (*dbController.BlogDocument, error) {
+	return sdbc.getBlogPostWhere(includeUnpublished, `p.slug = ?`, slug)
+}
+
+func (sdbc *SqliteDbController) GetBlogPosts(page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	// Matches MongoDB, which rejects negative $skip values and non-positive
+	// $limit values
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	return sdbc.getBlogPostsWhere(page, pagination, includeUnpublished, `1 = 1`)
+}
+
+// getBlogPostsWhere returns a page of the visible blog posts that match the
+// where condition, newest first.
+func (sdbc *SqliteDbController) getBlogPostsWhere(page int, pagination int, includeUnpublished bool, where string, whereArgs ...interface{}) ([]*dbController.BlogDocument, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(includeUnpublished)
+
+	// Posts added at the same time keep their insertion order
+	query := blogPostSelect + ` WHERE ` + visibility + ` AND (` + where + `) ORDER BY p.dateAdded DESC, p.rowid ASC LIMIT ? OFFSET ?`
+	args = append(args, whereArgs...)
+	args = append(args, pagination, (page-1)*pagination)
+
+	return queryBlogPosts(backCtx, sdbc.DB, query, args...)
+}
+
+func (sdbc *SqliteDbController) EditBlogPost(doc *dbController.EditBlogDocument) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	print("Editing Blog Post\n")
+
+	if !isValidId(doc.Id) {
+		return dbController.NewInvalidInputError("Invalid User ID")
+	}
+
+	columns := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	setValue := func(column string, value interface{}) {
+		columns = append(columns, column+" = ?")
+		args = append(args, value)
+	}
+
+	if doc.Title != nil {
+		setValue("title", *doc.Title)
+	}
+
+	if doc.Slug != nil {
+		setValue("slug", *doc.Slug)
+	}
+
+	if doc.Body != nil {
+		setValue("body", *doc.Body)
+	}
+
+	if doc.BodyHtml != nil {
+		setValue("bodyHtml", *doc.BodyHtml)
+	}
+
+	if doc.Summary != nil {
+		setValue("excerpt", doc.Summary.Excerpt)
+		setValue("customExcerpt", doc.Summary.CustomExcerpt)
+		setValue("wordCount", doc.Summary.WordCount)
+		setValue("readingTime", doc.Summary.ReadingTimeMinutes)
+	}
+
+	if doc.AuthorId != nil {
+		setValue("authorId", *doc.AuthorId)
+	}
+
+	if doc.DateAdded != nil {
+		setValue("dateAdded", (*doc.DateAdded).Unix())
+	}
+
+	if doc.UpdateAuthorId != nil {
+		setValue("updateAuthorId", *doc.UpdateAuthorId)
+	}
+
+	if doc.DateUpdated != nil {
+		setValue("dateUpdated", (*doc.DateUpdated).Unix())
+	}
+
+	if doc.Status != nil {
+		setValue("status", *doc.Status)
+	}
+
+	if doc.PublishAt != nil {
+		setValue("publishAt", (*doc.PublishAt).Unix())
+	}
+
+	tx, txErr := sdbc.DB.BeginTx(backCtx, nil)
+
+	if txErr != nil {
+		return dbController.NewDBError(txErr.Error())
+	}
+	defer tx.Rollback()
+
+	previous, previousErr := queryBlogPosts(backCtx, tx, blogPostSelect+` WHERE p.id = ? AND p.deletedAt IS NULL`, doc.Id)
+
+	if previousErr != nil {
+		return previousErr
+	}
+
+	if len(previous) == 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts")
+	}
+
+	if doc.Slug != nil && *doc.Slug != previous[0].Slug {
+		if slugErr := checkOldSlug(backCtx, tx, *doc.Slug, doc.Id); slugErr != nil {
+			return slugErr
+		}
+	}
+
+	if len(columns) > 0 {
+		args = append(args, doc.Id)
+
+		_, updateErr := tx.ExecContext(
+			backCtx,
+			`UPDATE `+BLOG_TABLE+` SET `+strings.Join(columns, ", ")+` WHERE id = ?`,
+			args...,
+		)
+
+		if updateErr != nil {
+			err := updateErr.Error()
+			print("Edit blog error. Error: " + err + "\n")
+
+			if isDuplicateError(updateErr) {
+				msg := "Duplicate blog post."
+				if strings.Contains(err, "slug") {
+					msg = msg + " Blog Post with slug '" + *doc.Slug + "' already exists."
+				}
+
+				return dbController.NewDuplicateEntryError(msg)
+			}
+
+			return dbController.NewDBError(err)
+		}
+	}
+
+	if doc.Slug != nil && *doc.Slug != previous[0].Slug {
+		dateChanged := time.Now().Unix()
+		if doc.DateUpdated != nil {
+			dateChanged = (*doc.DateUpdated).Unix()
+		}
+
+		if slugErr := saveOldSlug(backCtx, tx, doc.Id, previous[0].Slug, *doc.Slug, dateChanged); slugErr != nil {
+			return dbController.NewDBError(slugErr.Error())
+		}
+	}
+
+	if doc.Tags != nil {
+		_, deleteErr := tx.ExecContext(backCtx, `DELETE FROM `+BLOG_TAGS_TABLE+` WHERE postId = ?`, doc.Id)
+
+		if deleteErr != nil {
+			return dbController.NewDBError(deleteErr.Error())
+		}
+
+		if tagsErr := insertTags(backCtx, tx, doc.Id, *doc.Tags); tagsErr != nil {
+			return dbController.NewDBError(tagsErr.Error())
+		}
+	}
+
+	if revisionErr := saveRevision(backCtx, tx, doc.Id, previous[0]); revisionErr != nil {
+		return dbController.NewDBError(revisionErr.Error())
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return dbController.NewDBError(commitErr.Error())
+	}
+
+	return nil
+}
+
+func (sdbc *SqliteDbController) DeleteBlogPost(doc *dbController.DeleteBlogDocument) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	print("Deleting Blog Post\n")
+
+	if !isValidId(doc.Id) {
+		return dbController.NewInvalidInputError("Invalid User ID")
+	}
+
+	delResult, delErr := sdbc.DB.ExecContext(
+		backCtx,
+		`UPDATE `+BLOG_TABLE+` SET deletedAt = ?, deletedBy = ? WHERE id = ? AND deletedAt IS NULL`,
+		time.Now().Unix(), doc.DeletedBy, doc.Id,
+	)
+
+	if delErr != nil {
+		return dbController.NewDBError(delErr.Error())
+	}
+
+	deletedCount, countErr := delResult.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if deletedCount == 0 {
+		return dbController.NewInvalidInputError("invalid id. no blog posts deleted")
+	}
+
+	return nil
+}
+
+func (sdbc *SqliteDbController) AddUserInformation(info *user.UserInformation) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	_, upsertErr := sdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+USER_TABLE+` (uid, name, email, active, role) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (uid) DO UPDATE SET
+			name = excluded.name,
+			email = excluded.email,
+			active = excluded.active,
+			role = excluded.role`,
+		info.Uid, info.Name, info.Email, info.Active, info.Role.String(),
+	)
+
+	if upsertErr != nil {
+		if isDuplicateError(upsertErr) {
+			return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + info.Email + "' already exists.")
+		}
+
+		return dbController.NewDBError(upsertErr.Error())
+	}
+
+	return nil
+}
+
+// trimLogs deletes the oldest log rows once there are more than MAX_LOG_ROWS
+func (sdbc *SqliteDbController) trimLogs(backCtx context.Context) error {
+	_, deleteErr := sdbc.DB.ExecContext(
+		backCtx,
+		`DELETE FROM `+LOGGING_TABLE+` WHERE id <= (SELECT MAX(id) FROM `+LOGGING_TABLE+`) - ?`,
+		MAX_LOG_ROWS,
+	)
+
+	return deleteErr
+}
+
+func (sdbc *SqliteDbController) AddRequestLog(log *logging.RequestLogData) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	_, insertErr := sdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+LOGGING_TABLE+` (timestamp, type, clientIP, method, path, protocol, statusCode, latency, userAgent, errorMessage)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		log.Timestamp.Unix(),
+		log.Type,
+		log.ClientIP,
+		log.Method,
+		log.Path,
+		log.Protocol,
+		log.StatusCode,
+		int64(log.Latency),
+		log.UserAgent,
+		log.ErrorMessage,
+	)
+
+	if insertErr != nil {
+		return dbController.NewDBError(insertErr.Error())
+	}
+
+	if trimErr := sdbc.trimLogs(backCtx); trimErr != nil {
+		return dbController.NewDBError(trimErr.Error())
+	}
+
+	return nil
+}
+
+func (sdbc *SqliteDbController) AddInfoLog(log *logging.InfoLogData) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	_, insertErr := sdbc.DB.ExecContext(
+		backCtx,
+		`INSERT INTO `+LOGGING_TABLE+` (timestamp, type, message) VALUES (?, ?, ?)`,
+		log.Timestamp.Unix(),
+		log.Type,
+		log.Message,
+	)
+
+	if insertErr != nil {
+		return dbController.NewDBError(insertErr.Error())
+	}
+
+	if trimErr := sdbc.trimLogs(backCtx); trimErr != nil {
+		return dbController.NewDBError(trimErr.Error())
+	}
+
+	return nil
+}
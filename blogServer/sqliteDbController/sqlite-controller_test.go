@@ -0,0 +1,23 @@
+package sqliteDbController
+
+import (
+	"path/filepath"
+	"testing"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/dbController/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RunConformanceSuite(t, func(t *testing.T) dbController.DatabaseController {
+		sdbc, openErr := OpenSqliteDbController(filepath.Join(t.TempDir(), "blog.db"))
+
+		if openErr != nil {
+			t.Fatalf("OpenSqliteDbController: %v", openErr)
+		}
+
+		t.Cleanup(func() { sdbc.DB.Close() })
+
+		return sdbc
+	})
+}
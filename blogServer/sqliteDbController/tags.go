@@ -0,0 +1,56 @@
+package sqliteDbController
+
+import (
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (sdbc *SqliteDbController) GetBlogTags(includeUnpublished bool) ([]*dbController.TagCount, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	visibility, args := visibilityCondition(includeUnpublished)
+
+	rows, queryErr := sdbc.DB.QueryContext(
+		backCtx,
+		`SELECT t.tag, COUNT(DISTINCT t.postId)
+		FROM `+BLOG_TAGS_TABLE+` t
+		JOIN `+BLOG_TABLE+` p ON p.id = t.postId
+		WHERE `+visibility+`
+		GROUP BY t.tag
+		ORDER BY 2 DESC, t.tag ASC`,
+		args...,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	tags := make([]*dbController.TagCount, 0)
+
+	for rows.Next() {
+		var tag dbController.TagCount
+
+		if scanErr := rows.Scan(&tag.Tag, &tag.Count); scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return tags, nil
+}
+
+func (sdbc *SqliteDbController) GetBlogPostsByTag(tag string, page int, pagination int, includeUnpublished bool) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	where, args := filterCondition(&dbController.BlogPostFilter{Tag: tag})
+
+	return sdbc.getBlogPostsWhere(page, pagination, includeUnpublished, where, args...)
+}
@@ -0,0 +1,106 @@
+package sqliteDbController
+
+import (
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+)
+
+func (sdbc *SqliteDbController) GetTrashedBlogPosts(page int, pagination int) ([]*dbController.BlogDocument, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	query := blogPostSelect + ` WHERE p.deletedAt IS NOT NULL ORDER BY p.deletedAt DESC, p.rowid ASC LIMIT ? OFFSET ?`
+
+	return queryBlogPosts(backCtx, sdbc.DB, query, pagination, (page-1)*pagination)
+}
+
+func (sdbc *SqliteDbController) CountTrashedBlogPosts() (int, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	var count int
+
+	queryErr := sdbc.DB.QueryRowContext(
+		backCtx,
+		`SELECT COUNT(*) FROM `+BLOG_TABLE+` WHERE deletedAt IS NOT NULL`,
+	).Scan(&count)
+
+	if queryErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return count, nil
+}
+
+// updateTrashedBlogPost runs a statement that takes the post id as its last
+// argument and only changes posts in the trash.
+func (sdbc *SqliteDbController) updateTrashedBlogPost(statement string, id string) error {
+	if !isValidId(id) {
+		return dbController.NewInvalidInputError("invalid id")
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	result, execErr := sdbc.DB.ExecContext(backCtx, statement, id)
+
+	if execErr != nil {
+		return dbController.NewDBError(execErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count == 0 {
+		return dbController.NewInvalidInputError("id did not match any blog posts in the trash")
+	}
+
+	return nil
+}
+
+func (sdbc *SqliteDbController) RestoreBlogPost(id string) error {
+	return sdbc.updateTrashedBlogPost(
+		`UPDATE `+BLOG_TABLE+` SET deletedAt = NULL, deletedBy = NULL WHERE id = ? AND deletedAt IS NOT NULL`,
+		id,
+	)
+}
+
+// PurgeBlogPost deletes a post in the trash. The tags and revisions are
+// deleted by their foreign keys.
+func (sdbc *SqliteDbController) PurgeBlogPost(id string) error {
+	return sdbc.updateTrashedBlogPost(
+		`DELETE FROM `+BLOG_TABLE+` WHERE id = ? AND deletedAt IS NOT NULL`,
+		id,
+	)
+}
+
+func (sdbc *SqliteDbController) PurgeTrashedBlogPosts(deletedBefore time.Time) (int, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	result, execErr := sdbc.DB.ExecContext(
+		backCtx,
+		`DELETE FROM `+BLOG_TABLE+` WHERE deletedAt IS NOT NULL AND deletedAt < ?`,
+		deletedBefore.Unix(),
+	)
+
+	if execErr != nil {
+		return 0, dbController.NewDBError(execErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return 0, dbController.NewDBError(countErr.Error())
+	}
+
+	return int(count), nil
+}
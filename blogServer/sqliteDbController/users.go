@@ -0,0 +1,191 @@
+package sqliteDbController
+
+import (
+	"database/sql"
+	"strings"
+
+	"methompson.com/blog-microservice/blogServer/dbController"
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+const userSelect = `SELECT uid, name, email, active, role FROM ` + USER_TABLE
+
+// rowScanner is either a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*user.UserInformation, error) {
+	var info user.UserInformation
+	var role string
+
+	if scanErr := row.Scan(&info.Uid, &info.Name, &info.Email, &info.Active, &role); scanErr != nil {
+		return nil, scanErr
+	}
+
+	// Unknown roles are read as the reader role
+	info.Role, _ = user.ParseUserType(role)
+
+	return &info, nil
+}
+
+func (sdbc *SqliteDbController) GetUserInformation(uid string) (*user.UserInformation, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	info, queryErr := scanUser(sdbc.DB.QueryRowContext(backCtx, userSelect+` WHERE uid = ?`, uid))
+
+	if queryErr == sql.ErrNoRows {
+		return nil, dbController.NewNoResultsError("")
+	}
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return info, nil
+}
+
+func (sdbc *SqliteDbController) GetUsers(page int, pagination int) ([]*user.UserInformation, error) {
+	if page < 1 || pagination < 1 {
+		return nil, dbController.NewDBError("invalid page or pagination")
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	rows, queryErr := sdbc.DB.QueryContext(
+		backCtx,
+		userSelect+` ORDER BY uid ASC LIMIT ? OFFSET ?`,
+		pagination, (page-1)*pagination,
+	)
+
+	if queryErr != nil {
+		return nil, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+	defer rows.Close()
+
+	users := make([]*user.UserInformation, 0)
+
+	for rows.Next() {
+		info, scanErr := scanUser(rows)
+
+		if scanErr != nil {
+			return nil, dbController.NewDBError("error parsing results: " + scanErr.Error())
+		}
+
+		users = append(users, info)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, dbController.NewDBError("error parsing results: " + rowsErr.Error())
+	}
+
+	return users, nil
+}
+
+func (sdbc *SqliteDbController) CountUsers() (int, error) {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	var count int
+
+	queryErr := sdbc.DB.QueryRowContext(backCtx, `SELECT COUNT(*) FROM `+USER_TABLE).Scan(&count)
+
+	if queryErr != nil {
+		return 0, dbController.NewDBError("error getting data from database: " + queryErr.Error())
+	}
+
+	return count, nil
+}
+
+func (sdbc *SqliteDbController) EditUserInformation(doc *dbController.EditUserDocument) error {
+	columns := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	setValue := func(column string, value interface{}) {
+		columns = append(columns, column+" = ?")
+		args = append(args, value)
+	}
+
+	if doc.Name != nil {
+		setValue("name", *doc.Name)
+	}
+
+	if doc.Email != nil {
+		setValue("email", *doc.Email)
+	}
+
+	if doc.Active != nil {
+		setValue("active", *doc.Active)
+	}
+
+	if doc.Role != nil {
+		setValue("role", doc.Role.String())
+	}
+
+	// Nothing to change, but the user still has to exist
+	if len(columns) == 0 {
+		_, getErr := sdbc.GetUserInformation(doc.Uid)
+
+		if _, ok := getErr.(dbController.NoResultsError); ok {
+			return dbController.NewInvalidInputError("uid did not match any users")
+		}
+
+		return getErr
+	}
+
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	args = append(args, doc.Uid)
+
+	result, updateErr := sdbc.DB.ExecContext(
+		backCtx,
+		`UPDATE `+USER_TABLE+` SET `+strings.Join(columns, ", ")+` WHERE uid = ?`,
+		args...,
+	)
+
+	if updateErr != nil {
+		if isDuplicateError(updateErr) {
+			return dbController.NewDuplicateEntryError("Duplicate user. User with email '" + *doc.Email + "' already exists.")
+		}
+
+		return dbController.NewDBError(updateErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count == 0 {
+		return dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	return nil
+}
+
+func (sdbc *SqliteDbController) DeleteUserInformation(uid string) error {
+	backCtx, cancel := sdbc.getContext()
+	defer cancel()
+
+	result, deleteErr := sdbc.DB.ExecContext(backCtx, `DELETE FROM `+USER_TABLE+` WHERE uid = ?`, uid)
+
+	if deleteErr != nil {
+		return dbController.NewDBError(deleteErr.Error())
+	}
+
+	count, countErr := result.RowsAffected()
+
+	if countErr != nil {
+		return dbController.NewDBError(countErr.Error())
+	}
+
+	if count == 0 {
+		return dbController.NewInvalidInputError("uid did not match any users")
+	}
+
+	return nil
+}
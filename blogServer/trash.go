@@ -0,0 +1,75 @@
+package blogServer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/constants"
+)
+
+// DEFAULT_TRASH_RETENTION_DAYS is used when TRASH_RETENTION_DAYS isn't set
+const DEFAULT_TRASH_RETENTION_DAYS = 30
+
+// TRASH_PURGE_INTERVAL is how often the trash is checked for expired posts
+const TRASH_PURGE_INTERVAL = time.Hour
+
+// getTrashRetention returns how long deleted blog posts are kept in the trash.
+// A retention of 0 means posts are kept until they are purged by hand.
+func getTrashRetention() (time.Duration, error) {
+	days := DEFAULT_TRASH_RETENTION_DAYS
+
+	if value := os.Getenv(constants.TRASH_RETENTION_DAYS); len(value) > 0 {
+		parsed, parseErr := strconv.Atoi(value)
+
+		if parseErr != nil || parsed < 0 {
+			return 0, errors.New("TRASH_RETENTION_DAYS must be a number of days")
+		}
+
+		days = parsed
+	}
+
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// startTrashRetentionJob purges expired blog posts from the trash when the
+// server starts, then once every TRASH_PURGE_INTERVAL.
+func startTrashRetentionJob(bs *BlogServer) error {
+	retention, retentionErr := getTrashRetention()
+
+	if retentionErr != nil {
+		return retentionErr
+	}
+
+	if retention == 0 {
+		return nil
+	}
+
+	purge := func() {
+		purged, purgeErr := bs.BlogController.PurgeExpiredBlogPosts(retention)
+
+		if purgeErr != nil {
+			print("Error purging trash: " + purgeErr.Error() + "\n")
+			return
+		}
+
+		if purged > 0 {
+			print(fmt.Sprintf("Purged %d blog posts from the trash\n", purged))
+		}
+	}
+
+	go func() {
+		purge()
+
+		ticker := time.NewTicker(TRASH_PURGE_INTERVAL)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purge()
+		}
+	}()
+
+	return nil
+}
@@ -17,17 +17,21 @@ type AuthorizationHeader struct {
 }
 
 type AddBlogBody struct {
-	Title          string    `json:"title" binding:"required"`
-	Slug           string    `json:"slug" binding:"required"`
-	Body           string    `json:"body" binding:"required"`
-	Tags           *[]string `json:"tags"`
-	AuthorId       string    `json:"authorId" binding:"required"`
-	DateAdded      int       `json:"dateAdded" binding:"required"`
-	UpdateAuthorId *string   `json:"updateAuthorId"`
-	DateUpdated    *int      `json:"dateUpdated"`
+	Title       string    `json:"title" binding:"required"`
+	Slug        string    `json:"slug" binding:"required"`
+	Body        string    `json:"body" binding:"required"`
+	Excerpt     *string   `json:"excerpt"`
+	Tags        *[]string `json:"tags"`
+	AuthorId    string    `json:"authorId"`
+	DateAdded   int       `json:"dateAdded" binding:"required"`
+	DateUpdated *int      `json:"dateUpdated"`
+	Status      *string   `json:"status"`
+	PublishAt   *int      `json:"publishAt"`
 }
 
-func (abb *AddBlogBody) GetBlogDocument() *dbController.AddBlogDocument {
+// GetBlogDocument returns the blog post added by the user with the uid
+// addedBy, who is also the author unless the body names one.
+func (abb *AddBlogBody) GetBlogDocument(addedBy string) *dbController.AddBlogDocument {
 	dateAdded := time.Unix(int64(abb.DateAdded), 0)
 
 	var dateUpdated *time.Time
@@ -36,35 +40,54 @@ func (abb *AddBlogBody) GetBlogDocument() *dbController.AddBlogDocument {
 		dateUpdated = &t
 	}
 
+	var publishAt *time.Time
+	if abb.PublishAt != nil {
+		t := time.Unix(int64(*abb.PublishAt), 0)
+		publishAt = &t
+	}
+
+	authorId := abb.AuthorId
+	if len(authorId) == 0 {
+		authorId = addedBy
+	}
+
 	doc := dbController.AddBlogDocument{
 		Title:          abb.Title,
 		Slug:           abb.Slug,
 		Body:           abb.Body,
 		Tags:           abb.Tags,
-		AuthorId:       abb.AuthorId,
+		AuthorId:       authorId,
 		DateAdded:      dateAdded,
-		UpdateAuthorId: abb.UpdateAuthorId,
+		UpdateAuthorId: &addedBy,
 		DateUpdated:    dateUpdated,
+		Status:         abb.Status,
+		PublishAt:      publishAt,
 	}
 
 	return &doc
 }
 
 type EditBlogBody struct {
-	Id             string    `json:"id" binding:"required"`
-	Title          *string   `json:"title"`
-	Slug           *string   `json:"slug"`
-	Body           *string   `json:"body"`
-	Tags           *[]string `json:"tags"`
-	AuthorId       *string   `json:"authorId"`
-	DateAdded      *int      `json:"dateAdded"`
-	UpdateAuthorId *string   `json:"updateAuthorId"`
-	DateUpdated    *int      `json:"dateUpdated"`
+	Id          string    `json:"id" binding:"required"`
+	Title       *string   `json:"title"`
+	Slug        *string   `json:"slug"`
+	Body        *string   `json:"body"`
+	Excerpt     *string   `json:"excerpt"`
+	Tags        *[]string `json:"tags"`
+	AuthorId    *string   `json:"authorId"`
+	DateAdded   *int      `json:"dateAdded"`
+	DateUpdated *int      `json:"dateUpdated"`
+	Status      *string   `json:"status"`
+	PublishAt   *int      `json:"publishAt"`
 }
 
-func (ebb *EditBlogBody) GetBlogDocument() *dbController.EditBlogDocument {
+// GetBlogDocument returns the edit made by the user with the updateAuthorId.
+// The editor always comes from the request's authentication, never the body,
+// so that the revisions can be trusted.
+func (ebb *EditBlogBody) GetBlogDocument(updateAuthorId string) *dbController.EditBlogDocument {
 	var dateAdded *time.Time
 	var dateUpdated *time.Time
+	var publishAt *time.Time
 
 	if ebb.DateAdded != nil {
 		t := time.Unix(int64(*ebb.DateAdded), 0)
@@ -76,6 +99,11 @@ func (ebb *EditBlogBody) GetBlogDocument() *dbController.EditBlogDocument {
 		dateUpdated = &t
 	}
 
+	if ebb.PublishAt != nil {
+		t := time.Unix(int64(*ebb.PublishAt), 0)
+		publishAt = &t
+	}
+
 	doc := dbController.EditBlogDocument{
 		Id:             ebb.Id,
 		Title:          ebb.Title,
@@ -84,8 +112,10 @@ func (ebb *EditBlogBody) GetBlogDocument() *dbController.EditBlogDocument {
 		Tags:           ebb.Tags,
 		AuthorId:       ebb.AuthorId,
 		DateAdded:      dateAdded,
-		UpdateAuthorId: ebb.UpdateAuthorId,
+		UpdateAuthorId: &updateAuthorId,
 		DateUpdated:    dateUpdated,
+		Status:         ebb.Status,
+		PublishAt:      publishAt,
 	}
 
 	return &doc
@@ -95,10 +125,58 @@ type DeleteBlogBody struct {
 	Id string `json:"id" binding:"required"`
 }
 
-func (dbb *DeleteBlogBody) GetBlogDocument() *dbController.DeleteBlogDocument {
+func (dbb *DeleteBlogBody) GetBlogDocument(deletedBy string) *dbController.DeleteBlogDocument {
 	doc := dbController.DeleteBlogDocument{
-		Id: dbb.Id,
+		Id:        dbb.Id,
+		DeletedBy: deletedBy,
 	}
 
 	return &doc
 }
+
+// TrashedBlogBody is used to restore or purge a blog post in the trash
+type TrashedBlogBody struct {
+	Id string `json:"id" binding:"required"`
+}
+
+type RestoreBlogBody struct {
+	Id       string `json:"id" binding:"required"`
+	Revision int    `json:"revision" binding:"required"`
+}
+
+// AddUserBody adds a user that has signed in with Firebase. New users are
+// active unless the body says otherwise.
+type AddUserBody struct {
+	Uid    string `json:"uid" binding:"required"`
+	Name   string `json:"name" binding:"required"`
+	Email  string `json:"email" binding:"required"`
+	Active *bool  `json:"active"`
+	Role   string `json:"role" binding:"required"`
+}
+
+type EditUserBody struct {
+	Uid    string  `json:"uid" binding:"required"`
+	Name   *string `json:"name"`
+	Email  *string `json:"email"`
+	Active *bool   `json:"active"`
+	Role   *string `json:"role"`
+}
+
+// UserBody is used to deactivate or delete a user
+type UserBody struct {
+	Uid string `json:"uid" binding:"required"`
+}
+
+// AddApiKeyBody creates an API key. OwnerId defaults to the admin creating the
+// key. ExpiresAt is a Unix timestamp. Keys without one don't expire.
+type AddApiKeyBody struct {
+	Name      string   `json:"name" binding:"required"`
+	OwnerId   string   `json:"ownerId"`
+	Scopes    []string `json:"scopes" binding:"required"`
+	ExpiresAt *int64   `json:"expiresAt"`
+}
+
+// ApiKeyBody is used to revoke an API key
+type ApiKeyBody struct {
+	Id string `json:"id" binding:"required"`
+}
@@ -0,0 +1,80 @@
+package blogServer
+
+import (
+	"sync"
+	"time"
+
+	"methompson.com/blog-microservice/blogServer/user"
+)
+
+// USER_CACHE_TTL is how long authorization trusts a user read from the
+// database. Changes made through the BlogController apply right away, changes
+// made to the database by hand apply once the user expires.
+const USER_CACHE_TTL = 30 * time.Second
+
+// USER_CACHE_SWEEP_SIZE is the number of cached users above which expired
+// users are removed when a user is added.
+const USER_CACHE_SWEEP_SIZE = 1000
+
+type userCacheEntry struct {
+	info    *user.UserInformation
+	expires time.Time
+}
+
+// A UserCache keeps users read from the database for authorization, so that
+// every request doesn't have to read the user again. A nil user is cached for
+// uids that don't match a user.
+type UserCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]userCacheEntry
+}
+
+func NewUserCache(ttl time.Duration) *UserCache {
+	return &UserCache{
+		ttl:     ttl,
+		entries: make(map[string]userCacheEntry),
+	}
+}
+
+// Get returns the cached user with the uid and whether there was one
+func (uc *UserCache) Get(uid string) (*user.UserInformation, bool) {
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	entry, ok := uc.entries[uid]
+
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	return entry.info, true
+}
+
+func (uc *UserCache) Set(uid string, info *user.UserInformation) {
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	now := time.Now()
+
+	if len(uc.entries) >= USER_CACHE_SWEEP_SIZE {
+		for key, entry := range uc.entries {
+			if now.After(entry.expires) {
+				delete(uc.entries, key)
+			}
+		}
+	}
+
+	uc.entries[uid] = userCacheEntry{
+		info:    info,
+		expires: now.Add(uc.ttl),
+	}
+}
+
+// Invalidate removes the user with the uid, so that the next Get misses
+func (uc *UserCache) Invalidate(uid string) {
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	delete(uc.entries, uid)
+}
@@ -1,5 +1,7 @@
 package user
 
+import "errors"
+
 type UserType int
 
 const (
@@ -21,6 +23,18 @@ func (s UserType) String() string {
 	return "unknown"
 }
 
+// ParseUserType returns the UserType that String names. Unknown names are an
+// error and return Reader, so that they never get more access than a reader.
+func ParseUserType(name string) (UserType, error) {
+	for _, userType := range []UserType{Reader, Editor, Admin} {
+		if userType.String() == name {
+			return userType, nil
+		}
+	}
+
+	return Reader, errors.New("invalid role")
+}
+
 type UserInformation struct {
 	Uid    string
 	Name   string
@@ -9,6 +9,7 @@
   -e MONGO_DB_PASSWORD='password' \
   -e CONSOLE_LOGGING='true' \
   -e GIN_MODE='release' \
+  -e PUBLIC_BASE_URL='https://example.com' \
   --name blog-microservice \
   blog-microservice
 )
\ No newline at end of file
@@ -13,6 +13,17 @@ func main() {
 	initPtr := flag.Bool("init", false, "Whether to run the admin initialization function")
 	initUidPtr := flag.String("adminUid", "", "The UID of the user slated to be an admin")
 	initNamePtr := flag.String("adminName", "", "The Name of the user slated to be an admin")
+	migratePtr := flag.Bool("migrate", false, "Whether to run the database migrations")
+	migrateVersionPtr := flag.Int("migrateVersion", -1, "The schema version to migrate to. Defaults to the latest version")
+	migrateDryRunPtr := flag.Bool("migrateDryRun", false, "Whether to only print the migration changes without making them")
+	reconcileClaimsPtr := flag.Bool("reconcileClaims", false, "Whether to compare the users in the database with the Firebase custom claims")
+	reconcileFixPtr := flag.Bool("reconcileFix", false, "Whether to change the Firebase custom claims to match the database when reconciling")
+	apiKeyPtr := flag.String("apiKey", "", "The API key command to run: create, list or revoke")
+	apiKeyNamePtr := flag.String("apiKeyName", "", "The name of the API key to create")
+	apiKeyOwnerPtr := flag.String("apiKeyOwner", "", "The UID of the editor or admin the API key acts as")
+	apiKeyScopesPtr := flag.String("apiKeyScopes", "", "The comma separated scopes of the API key, e.g. posts:write,posts:delete")
+	apiKeyExpiresDaysPtr := flag.Int("apiKeyExpiresDays", 0, "The number of days until the API key expires. 0 means it doesn't expire")
+	apiKeyIdPtr := flag.String("apiKeyId", "", "The id of the API key to revoke")
 
 	flag.Parse()
 
@@ -27,5 +38,27 @@ func main() {
 		return
 	}
 
+	if migratePtr != nil && *migratePtr {
+		blogServer.DoMigrate(migrateVersionPtr, migrateDryRunPtr)
+		return
+	}
+
+	if reconcileClaimsPtr != nil && *reconcileClaimsPtr {
+		blogServer.DoReconcileClaims(reconcileFixPtr)
+		return
+	}
+
+	if apiKeyPtr != nil && len(*apiKeyPtr) > 0 {
+		blogServer.DoApiKeyCommand(blogServer.ApiKeyCommand{
+			Command:     *apiKeyPtr,
+			Name:        *apiKeyNamePtr,
+			OwnerId:     *apiKeyOwnerPtr,
+			Scopes:      *apiKeyScopesPtr,
+			ExpiresDays: *apiKeyExpiresDaysPtr,
+			Id:          *apiKeyIdPtr,
+		})
+		return
+	}
+
 	blogServer.MakeAndStartServer()
 }